@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+)
+
+type ProxyAuth struct {
+	APIKeys    []*APIKey `toml:"apiKeys" json:"apiKeys"`                     // static api keys sent in the X-API-Key header. the client identity is the key name
+	JWKSFile   string    `toml:"jwksFile" json:"jwksFile"`                   // JWKS file with the keys used to validate bearer JWTs. the client identity is the sub claim
+	Issuer     string    `toml:"issuer" json:"issuer"`                       // required iss claim of bearer JWTs. not checked if not set
+	Audience   string    `toml:"audience" json:"audience"`                   // required aud claim of bearer JWTs. not checked if not set
+	ClientCert bool      `toml:"clientCertificate" json:"clientCertificate"` // indicates if clients are authenticated by their tls certificate. the client identity is the certificate CN
+}
+
+type APIKey struct {
+	Name string `toml:"name" json:"name"` // name of the client using the key
+	Key  string `toml:"key" json:"key"`
+}
+
+func (c ProxyAuth) IsValid() error {
+	if len(c.APIKeys) == 0 && c.JWKSFile == "" && !c.ClientCert {
+		return errors.New("apiKeys, jwksFile and clientCertificate are not set")
+	}
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for i, k := range c.APIKeys {
+		if k == nil {
+			return newArrFieldErr("apiKeys", i, isEmptyErr)
+		}
+		if err := k.IsValid(); err != nil {
+			return newArrFieldErr("apiKeys", i, err)
+		}
+		if names[k.Name] {
+			return newArrFieldErr("apiKeys", i, newFieldErr("name", isNotUniqueErr))
+		}
+		if keys[k.Key] {
+			return newArrFieldErr("apiKeys", i, newFieldErr("key", isNotUniqueErr))
+		}
+		names[k.Name], keys[k.Key] = true, true
+	}
+	if c.JWKSFile == "" {
+		if c.Issuer != "" {
+			return newFieldErr("issuer", errors.New("must not be set as jwksFile is not set"))
+		}
+		if c.Audience != "" {
+			return newFieldErr("audience", errors.New("must not be set as jwksFile is not set"))
+		}
+	}
+	return nil
+}
+
+func (c APIKey) IsValid() error {
+	if c.Name == "" {
+		return newFieldErr("name", isEmptyErr)
+	}
+	if c.Key == "" {
+		return newFieldErr("key", isEmptyErr)
+	}
+	return nil
+}
+
+type RateLimit struct {
+	Requests *TokenBucket `toml:"requests" json:"requests"` // limit of requests per client. not limited if not set
+	Wakes    *TokenBucket `toml:"wakes" json:"wakes"`       // limit of requests per client that wake the node. not limited if not set
+}
+
+func (c RateLimit) IsValid() error {
+	if c.Requests == nil && c.Wakes == nil {
+		return errors.New("requests and wakes are not set")
+	}
+	if c.Requests != nil {
+		if err := c.Requests.IsValid(); err != nil {
+			return newFieldErr("requests", err)
+		}
+	}
+	if c.Wakes != nil {
+		if err := c.Wakes.IsValid(); err != nil {
+			return newFieldErr("wakes", err)
+		}
+	}
+	return nil
+}
+
+// TokenBucket is a rate limit allowing bursts of up to Burst requests, refilled at Rate requests per second
+type TokenBucket struct {
+	Rate  float64 `toml:"rate" json:"rate"`   // requests per second
+	Burst int     `toml:"burst" json:"burst"` // maximum number of requests allowed at once
+}
+
+func (c TokenBucket) IsValid() error {
+	if c.Rate <= 0 {
+		return newFieldErr("rate", isNotGreaterThanZeroErr)
+	}
+	if c.Burst <= 0 {
+		return newFieldErr("burst", isNotGreaterThanZeroErr)
+	}
+	return nil
+}
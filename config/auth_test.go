@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProxyAuth_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": [{"%v": "client1", "%v": "secret"}],
+	"%v": "/path/to/jwks.json",
+	"%v": "https://issuer",
+	"%v": "node1",
+	"%v": true
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = [{%v = "client1", %v = "secret"}]
+%v = "/path/to/jwks.json"
+%v = "https://issuer"
+%v = "node1"
+%v = true`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, apiKeysField, nameField, keyField, jwksFileField, issuerField, audienceField, clientCertificateField)
+
+			want := ProxyAuth{
+				APIKeys:    []*APIKey{{Name: "client1", Key: "secret"}},
+				JWKSFile:   "/path/to/jwks.json",
+				Issuer:     "https://issuer",
+				Audience:   "node1",
+				ClientCert: true,
+			}
+
+			var (
+				got ProxyAuth
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestProxyAuth_IsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    ProxyAuth
+		wantErr string
+	}{
+		{
+			name:    "not set",
+			auth:    ProxyAuth{},
+			wantErr: fmt.Sprintf("%v, %v and %v are not set", apiKeysField, jwksFileField, clientCertificateField),
+		},
+		{
+			name:    "api key name not set",
+			auth:    ProxyAuth{APIKeys: []*APIKey{{Key: "secret"}}},
+			wantErr: fmt.Sprintf("%v[0].%v is empty", apiKeysField, nameField),
+		},
+		{
+			name:    "api key not set",
+			auth:    ProxyAuth{APIKeys: []*APIKey{{Name: "client1"}}},
+			wantErr: fmt.Sprintf("%v[0].%v is empty", apiKeysField, keyField),
+		},
+		{
+			name:    "api key name not unique",
+			auth:    ProxyAuth{APIKeys: []*APIKey{{Name: "client1", Key: "secret1"}, {Name: "client1", Key: "secret2"}}},
+			wantErr: fmt.Sprintf("%v[1].%v must be unique", apiKeysField, nameField),
+		},
+		{
+			name:    "api key not unique",
+			auth:    ProxyAuth{APIKeys: []*APIKey{{Name: "client1", Key: "secret"}, {Name: "client2", Key: "secret"}}},
+			wantErr: fmt.Sprintf("%v[1].%v must be unique", apiKeysField, keyField),
+		},
+		{
+			name:    "issuer without jwks file",
+			auth:    ProxyAuth{ClientCert: true, Issuer: "https://issuer"},
+			wantErr: fmt.Sprintf("%v must not be set as %v is not set", issuerField, jwksFileField),
+		},
+		{
+			name:    "audience without jwks file",
+			auth:    ProxyAuth{ClientCert: true, Audience: "node1"},
+			wantErr: fmt.Sprintf("%v must not be set as %v is not set", audienceField, jwksFileField),
+		},
+		{
+			name: "valid",
+			auth: ProxyAuth{APIKeys: []*APIKey{{Name: "client1", Key: "secret"}}, JWKSFile: "jwks.json", Issuer: "https://issuer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateLimit_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": {"%v": 10, "%v": 20},
+	"%v": {"%v": 0.1, "%v": 2}
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = {%v = 10.0, %v = 20}
+%v = {%v = 0.1, %v = 2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, requestsField, rateField, burstField, wakesField, rateField, burstField)
+
+			want := RateLimit{
+				Requests: &TokenBucket{Rate: 10, Burst: 20},
+				Wakes:    &TokenBucket{Rate: 0.1, Burst: 2},
+			}
+
+			var (
+				got RateLimit
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestRateLimit_IsValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit RateLimit
+		wantErr   string
+	}{
+		{
+			name:      "not set",
+			rateLimit: RateLimit{},
+			wantErr:   fmt.Sprintf("%v and %v are not set", requestsField, wakesField),
+		},
+		{
+			name:      "requests rate",
+			rateLimit: RateLimit{Requests: &TokenBucket{Burst: 1}},
+			wantErr:   fmt.Sprintf("%v.%v must be > 0", requestsField, rateField),
+		},
+		{
+			name:      "wakes burst",
+			rateLimit: RateLimit{Wakes: &TokenBucket{Rate: 1}},
+			wantErr:   fmt.Sprintf("%v.%v must be > 0", wakesField, burstField),
+		},
+		{
+			name:      "valid",
+			rateLimit: RateLimit{Requests: &TokenBucket{Rate: 10, Burst: 20}, Wakes: &TokenBucket{Rate: 0.1, Burst: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rateLimit.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
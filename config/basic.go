@@ -9,11 +9,20 @@ type Basic struct {
 	DisableStrictMode    bool              `toml:"disableStrictMode" json:"disableStrictMode"`           // strict mode keeps consensus nodes alive always
 	UpchkPollingInterval int               `toml:"upcheckPollingInterval" json:"upcheckPollingInterval"` // up check polling interval in seconds for the blockchainClient and privacyManager
 	PeersConfigFile      string            `toml:"peersConfigFile" json:"peersConfigFile"`               // node hibernator config file path
+	Discovery            *Discovery        `toml:"discovery" json:"discovery"`                           // discovery of peers through signed peer records exchanged with other node hibernators. peersConfigFile is used if not set
 	InactivityTime       int               `toml:"inactivityTime" json:"inactivityTime"`                 // inactivity time for blockchain client and privacy hibernator
 	ResyncTime           int               `toml:"resyncTime" json:"resyncTime"`                         // time after which client should be started to sync up with network
+	DataDir              string            `toml:"dataDir" json:"dataDir"`                               // directory where node hibernator state is persisted across restarts
+	Watchdog             *Watchdog         `toml:"watchdog" json:"watchdog"`                             // watchdog to recover from stuck shutdown/startup
+	HibernationLease     *HibernationLease `toml:"hibernationLease" json:"hibernationLease"`             // leases acquired from a majority of peers before hibernating and held while hibernated
+	UnreachablePeers     *UnreachablePeers `toml:"unreachablePeers" json:"unreachablePeers"`             // handling of peers which do not respond to the hibernation checks. all peers must respond if not set
+	History              *History          `toml:"history" json:"history"`                               // history of node lifecycle events
+	Metrics              *Metrics          `toml:"metrics" json:"metrics"`                               // prometheus metrics endpoint. metrics are not served if not set
+	Schedules            []*Schedule       `toml:"schedules" json:"schedules"`                           // time windows overriding the inactivity rule. the first matching window applies
 	BlockchainClient     *BlockchainClient `toml:"blockchainClient" json:"blockchainClient"`             // configuration related to the blockchain client to be managed
 	PrivacyManager       *PrivacyManager   `toml:"privacyManager" json:"privacyManager"`                 // configuration related to the privacy hibernator to be managed
 	Server               *RPCServer        `toml:"server" json:"server"`                                 // RPC server config of this node hibernator
+	Processes            []*Process        `toml:"processes" json:"processes"`                           // additional processes managed by this node hibernator, e.g. signer, enclave or monitoring sidecar
 	Proxies              []*Proxy          `toml:"proxies" json:"proxies"`                               // proxies managed by this node hibernator
 }
 
@@ -21,6 +30,14 @@ func (c Basic) IsResyncTimerSet() bool {
 	return c.ResyncTime != 0
 }
 
+func (c Basic) IsDataDirSet() bool {
+	return c.DataDir != ""
+}
+
+func (c Basic) IsMetricsEnabled() bool {
+	return c.Metrics != nil
+}
+
 func (c Basic) IsRaft() bool {
 	return c.BlockchainClient.IsRaft()
 }
@@ -46,7 +63,17 @@ func (c Basic) IsValid() error {
 		return newFieldErr("name", isEmptyErr)
 	}
 
-	if c.PeersConfigFile == "" {
+	if c.Discovery != nil {
+		if c.PeersConfigFile != "" {
+			return newFieldErr("peersConfigFile", errors.New("must not be set as discovery is set"))
+		}
+		if err := c.Discovery.IsValid(); err != nil {
+			return newFieldErr("discovery", err)
+		}
+		if !c.IsDataDirSet() {
+			return newFieldErr("discovery", errors.New("requires dataDir to be set"))
+		}
+	} else if c.PeersConfigFile == "" {
 		return newFieldErr("peersConfigFile", isEmptyErr)
 	}
 
@@ -62,6 +89,39 @@ func (c Basic) IsValid() error {
 		return newFieldErr("resyncTime", errors.New("must be >= inactivityTime"))
 	}
 
+	if c.Watchdog != nil {
+		if err := c.Watchdog.IsValid(); err != nil {
+			return newFieldErr("watchdog", err)
+		}
+	}
+
+	if c.HibernationLease != nil {
+		if err := c.HibernationLease.IsValid(); err != nil {
+			return newFieldErr("hibernationLease", err)
+		}
+	}
+
+	if c.UnreachablePeers != nil {
+		if err := c.UnreachablePeers.IsValid(); err != nil {
+			return newFieldErr("unreachablePeers", err)
+		}
+	}
+
+	if c.History != nil {
+		if err := c.History.IsValid(); err != nil {
+			return newFieldErr("history", err)
+		}
+		if c.History.Persist && !c.IsDataDirSet() {
+			return newFieldErr("history", newFieldErr("persist", errors.New("requires dataDir to be set")))
+		}
+	}
+
+	for i, s := range c.Schedules {
+		if err := s.IsValid(); err != nil {
+			return newArrFieldErr("schedules", i, err)
+		}
+	}
+
 	if c.Server == nil {
 		return newFieldErr("server", isEmptyErr)
 	}
@@ -84,6 +144,16 @@ func (c Basic) IsValid() error {
 		}
 	}
 
+	for i, p := range c.Processes {
+		if err := p.IsValid(); err != nil {
+			return newArrFieldErr("processes", i, err)
+		}
+	}
+
+	if _, err := c.ProcessLevels(); err != nil {
+		return newFieldErr("processes", err)
+	}
+
 	if len(c.Proxies) == 0 {
 		return newFieldErr("proxies", isEmptyErr)
 	}
@@ -92,6 +162,9 @@ func (c Basic) IsValid() error {
 		if err := n.IsValid(); err != nil {
 			return newArrFieldErr("proxies", i, err)
 		}
+		if n.IsTesseraTxHandler() && c.PrivacyManager == nil {
+			return newArrFieldErr("proxies", i, newFieldErr("txHandler", errors.New("tessera requires privacyManager to be set")))
+		}
 	}
 
 	return nil
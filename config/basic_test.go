@@ -40,11 +40,20 @@ func TestBasic_Unmarshal(t *testing.T) {
 	"%v": true,
 	"%v": 1,
 	"%v": "/path/to/conf.json",
+	"%v": {},
 	"%v": 60,
 	"%v": 120,
+	"%v": "/path/to/data",
+	"%v": {},
+	"%v": {},
+	"%v": {},
 	"%v": {},
 	"%v": {},
+	"%v": [{}],
 	"%v": {},
+	"%v": {},
+	"%v": {},
+	"%v": [{}],
 	"%v": [{}]
 }`,
 		},
@@ -55,11 +64,20 @@ func TestBasic_Unmarshal(t *testing.T) {
 %v = true
 %v = 1
 %v = "/path/to/conf.json"
+%v = {}
 %v = 60
 %v = 120
+%v = "/path/to/data"
+%v = {}
 %v = {}
 %v = {}
 %v = {}
+%v = {}
+%v = [{}]
+%v = {}
+%v = {}
+%v = {}
+%v = [{}]
 %v = [{}]`,
 		},
 	}
@@ -72,11 +90,20 @@ func TestBasic_Unmarshal(t *testing.T) {
 				disableStrictModeField,
 				upcheckPollingIntervalField,
 				peersConfigFileField,
+				discoveryField,
 				inactivityTimeField,
 				resyncTimeField,
+				dataDirField,
+				watchdogField,
+				hibernationLeaseField,
+				unreachablePeersField,
+				historyField,
+				metricsField,
+				schedulesField,
 				blockchainClientField,
 				privacyManagerField,
 				serverField,
+				processesField,
 				proxiesField,
 			)
 
@@ -85,11 +112,20 @@ func TestBasic_Unmarshal(t *testing.T) {
 				DisableStrictMode:    true,
 				UpchkPollingInterval: 1,
 				PeersConfigFile:      "/path/to/conf.json",
+				Discovery:            &Discovery{},
 				InactivityTime:       60,
 				ResyncTime:           120,
+				DataDir:              "/path/to/data",
+				Watchdog:             &Watchdog{},
+				HibernationLease:     &HibernationLease{},
+				UnreachablePeers:     &UnreachablePeers{},
+				History:              &History{},
+				Metrics:              &Metrics{},
+				Schedules:            []*Schedule{{}},
 				BlockchainClient:     &BlockchainClient{},
 				PrivacyManager:       &PrivacyManager{},
 				Server:               &RPCServer{},
+				Processes:            []*Process{{}},
 				Proxies:              []*Proxy{{}},
 			}
 
@@ -138,6 +174,61 @@ func TestBasic_IsValid_PeersConfigFile(t *testing.T) {
 	require.EqualError(t, err, peersConfigFileField+" is empty")
 }
 
+func TestBasic_IsValid_Discovery(t *testing.T) {
+	invalidDiscovery := minimumValidDiscovery()
+	invalidDiscovery.Interval = 0
+
+	tests := []struct {
+		name            string
+		discovery       Discovery
+		peersConfigFile string
+		dataDir         string
+		wantErrMsg      string
+	}{
+		{
+			name:            "peersConfigFile set",
+			discovery:       minimumValidDiscovery(),
+			peersConfigFile: "/path/to/conf.json",
+			dataDir:         "/path/to/data",
+			wantErrMsg:      fmt.Sprintf("%v must not be set as %v is set", peersConfigFileField, discoveryField),
+		},
+		{
+			name:       "invalid",
+			discovery:  invalidDiscovery,
+			dataDir:    "/path/to/data",
+			wantErrMsg: fmt.Sprintf("%v.%v must be > 0", discoveryField, intervalField),
+		},
+		{
+			name:       "dataDir not set",
+			discovery:  minimumValidDiscovery(),
+			wantErrMsg: fmt.Sprintf("%v requires %v to be set", discoveryField, dataDirField),
+		},
+		{
+			name:      "valid",
+			discovery: minimumValidDiscovery(),
+			dataDir:   "/path/to/data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidBasic()
+			c.Discovery = &tt.discovery
+			c.PeersConfigFile = tt.peersConfigFile
+			c.DataDir = tt.dataDir
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
 func TestBasic_IsValid_UpcheckPollingInterval(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -269,6 +360,184 @@ func TestBasic_IsValid_ResyncTime(t *testing.T) {
 	}
 }
 
+func TestBasic_IsValid_History(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    *History
+		dataDir    string
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			history:    nil,
+			wantErrMsg: "",
+		},
+		{
+			name:       "in memory",
+			history:    &History{Size: 10},
+			wantErrMsg: "",
+		},
+		{
+			name:       "invalid",
+			history:    &History{Size: -1},
+			wantErrMsg: fmt.Sprintf("%v.%v must be >= 0", historyField, sizeField),
+		},
+		{
+			name:       "persisted with dataDir",
+			history:    &History{Persist: true},
+			dataDir:    "/path/to/data",
+			wantErrMsg: "",
+		},
+		{
+			name:       "persisted without dataDir",
+			history:    &History{Persist: true},
+			wantErrMsg: fmt.Sprintf("%v.%v requires dataDir to be set", historyField, persistField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidBasic()
+			c.History = tt.history
+			c.DataDir = tt.dataDir
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestBasic_IsValid_Watchdog(t *testing.T) {
+	invalidWatchdog := minimumValidWatchdog()
+	invalidWatchdog.Deadline = 0
+
+	validWatchdog := minimumValidWatchdog()
+
+	tests := []struct {
+		name       string
+		watchdog   *Watchdog
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			watchdog:   nil,
+			wantErrMsg: "",
+		},
+		{
+			name:       "valid",
+			watchdog:   &validWatchdog,
+			wantErrMsg: "",
+		},
+		{
+			name:       "invalid",
+			watchdog:   &invalidWatchdog,
+			wantErrMsg: fmt.Sprintf("%v.%v must be > 0", watchdogField, deadlineField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidBasic()
+			c.Watchdog = tt.watchdog
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestBasic_IsValid_HibernationLease(t *testing.T) {
+	tests := []struct {
+		name       string
+		lease      *HibernationLease
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			lease:      nil,
+			wantErrMsg: "",
+		},
+		{
+			name:       "valid",
+			lease:      &HibernationLease{},
+			wantErrMsg: "",
+		},
+		{
+			name:       "invalid",
+			lease:      &HibernationLease{MaxConcurrent: -1},
+			wantErrMsg: fmt.Sprintf("%v.%v must be >= 0", hibernationLeaseField, maxConcurrentField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidBasic()
+			c.HibernationLease = tt.lease
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestBasic_IsValid_UnreachablePeers(t *testing.T) {
+	tests := []struct {
+		name             string
+		unreachablePeers *UnreachablePeers
+		wantErrMsg       string
+	}{
+		{
+			name:             "not set",
+			unreachablePeers: nil,
+			wantErrMsg:       "",
+		},
+		{
+			name:             "valid",
+			unreachablePeers: &UnreachablePeers{Policy: "quorum"},
+			wantErrMsg:       "",
+		},
+		{
+			name:             "invalid",
+			unreachablePeers: &UnreachablePeers{Policy: "any"},
+			wantErrMsg:       fmt.Sprintf("%v.%v must be all, down, quorum or ignoreAfter", unreachablePeersField, policyField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidBasic()
+			c.UnreachablePeers = tt.unreachablePeers
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
 func TestBasic_IsValid_Server(t *testing.T) {
 	invalidServer := minimumValidRPCServer()
 	invalidServer.RPCAddr = ""
@@ -383,6 +652,131 @@ func TestBasic_IsValid_PrivacyManager(t *testing.T) {
 	}
 }
 
+func TestBasic_IsValid_Schedules_Invalid(t *testing.T) {
+	validSchedule := minimumValidSchedule()
+
+	invalidSchedule := minimumValidSchedule()
+	invalidSchedule.Cron = ""
+
+	c := minimumValidBasic()
+	c.Schedules = []*Schedule{&validSchedule, &invalidSchedule}
+
+	err := c.IsValid()
+
+	require.IsType(t, &arrFieldErr{}, err)
+	require.EqualError(t, err, fmt.Sprintf("%v[1].%v is empty", schedulesField, cronField))
+}
+
+func TestBasic_IsValid_Processes(t *testing.T) {
+	newProcess := func(name string, dependsOn ...string) *Process {
+		p := minimumValidProcess()
+		p.Name = name
+		p.DependsOn = dependsOn
+		return &p
+	}
+
+	tests := []struct {
+		name       string
+		processes  []*Process
+		wantErrMsg string
+		wantType   interface{}
+	}{
+		{
+			name:       "dependencies",
+			processes:  []*Process{newProcess("enclave"), newProcess("ethsigner", "bcclnt")},
+			wantErrMsg: "",
+		},
+		{
+			name:       "invalid",
+			processes:  []*Process{newProcess("")},
+			wantErrMsg: fmt.Sprintf("%v[0].%v is empty", processesField, nameField),
+			wantType:   &arrFieldErr{},
+		},
+		{
+			name:       "not unique",
+			processes:  []*Process{newProcess("privman")},
+			wantErrMsg: fmt.Sprintf("%v privman must have a unique name", processesField),
+			wantType:   &fieldErr{},
+		},
+		{
+			name:       "unknown dependency",
+			processes:  []*Process{newProcess("ethsigner", "unknown")},
+			wantErrMsg: fmt.Sprintf("%v ethsigner depends on unknown process unknown", processesField),
+			wantType:   &fieldErr{},
+		},
+		{
+			name:       "cycle",
+			processes:  []*Process{newProcess("a", "b"), newProcess("b", "a"), newProcess("c", "a")},
+			wantErrMsg: fmt.Sprintf("%v have a dependency cycle between a, b, c", processesField),
+			wantType:   &fieldErr{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidBasic()
+			c.Processes = tt.processes
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, tt.wantType, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestBasic_ProcessLevels(t *testing.T) {
+	newProcess := func(name string, dependsOn ...string) *Process {
+		p := minimumValidProcess()
+		p.Name = name
+		p.DependsOn = dependsOn
+		return &p
+	}
+
+	c := minimumValidBasic()
+	c.PrivacyManager.PrivManProcess.DependsOn = []string{"enclave"}
+	c.Processes = []*Process{
+		newProcess("ethsigner", "bcclnt"),
+		newProcess("enclave"),
+		newProcess("monitoring"),
+	}
+
+	levels, err := c.ProcessLevels()
+	require.NoError(t, err)
+
+	var got [][]string
+	for _, l := range levels {
+		var names []string
+		for _, p := range l {
+			names = append(names, p.Name)
+		}
+		got = append(got, names)
+	}
+
+	want := [][]string{
+		{"enclave", "monitoring"},
+		{"privman"},
+		{"bcclnt"},
+		{"ethsigner"},
+	}
+	require.Equal(t, want, got)
+}
+
+func TestBasic_ProcessLevels_WithoutPrivacyManager(t *testing.T) {
+	c := minimumValidBasic()
+	c.PrivacyManager = nil
+
+	levels, err := c.ProcessLevels()
+
+	require.NoError(t, err)
+	require.Len(t, levels, 1)
+	require.Equal(t, []*Process{c.BlockchainClient.BcClntProcess}, levels[0])
+}
+
 func TestBasic_IsValid_Proxies_NotSet(t *testing.T) {
 	c := minimumValidBasic()
 	c.Proxies = nil
@@ -433,6 +827,22 @@ func TestBasic_IsValid_Proxies_Invalid(t *testing.T) {
 	}
 }
 
+func TestBasic_IsValid_Proxies_TesseraTxHandler(t *testing.T) {
+	proxy := minimumValidProxy()
+	proxy.TxHandler = "tessera"
+
+	c := minimumValidBasic()
+	c.Proxies = []*Proxy{&proxy}
+	require.NoError(t, c.IsValid())
+
+	c.PrivacyManager = nil
+
+	err := c.IsValid()
+
+	require.IsType(t, &arrFieldErr{}, err)
+	require.EqualError(t, err, fmt.Sprintf("%v[0].%v tessera requires %v to be set", proxiesField, txHandlerField, privacyManagerField))
+}
+
 func TestBasic_IsResyncTimerSet(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"path"
+)
+
+type ResponseCache struct {
+	Methods []string `toml:"methods" json:"methods"` // JSON-RPC methods whose responses are cached. glob patterns, e.g. eth_get*
+	Size    int      `toml:"size" json:"size"`       // maximum number of cached responses
+	TTL     int      `toml:"ttl" json:"ttl"`         // time in seconds a cached response is used
+}
+
+func (c ResponseCache) IsValid() error {
+	if len(c.Methods) == 0 {
+		return newFieldErr("methods", isEmptyErr)
+	}
+	for i, m := range c.Methods {
+		if m == "" {
+			return newArrFieldErr("methods", i, isEmptyErr)
+		}
+		if _, err := path.Match(m, ""); err != nil {
+			return newArrFieldErr("methods", i, errors.New("is not a valid pattern"))
+		}
+	}
+	if c.Size <= 0 {
+		return newFieldErr("size", isNotGreaterThanZeroErr)
+	}
+	if c.TTL <= 0 {
+		return newFieldErr("ttl", isNotGreaterThanZeroErr)
+	}
+	return nil
+}
+
+// IsCached returns true if responses to method are cached
+func (c ResponseCache) IsCached(method string) bool {
+	return matchesMethod(c.Methods, method)
+}
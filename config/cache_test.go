@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidResponseCache() ResponseCache {
+	return ResponseCache{
+		Methods: []string{"eth_chainId"},
+		Size:    100,
+		TTL:     60,
+	}
+}
+
+func TestResponseCache_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": ["eth_chainId", "net_*"],
+	"%v": 100,
+	"%v": 60
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = ["eth_chainId", "net_*"]
+%v = 100
+%v = 60`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, methodsField, sizeField, ttlField)
+
+			want := ResponseCache{
+				Methods: []string{"eth_chainId", "net_*"},
+				Size:    100,
+				TTL:     60,
+			}
+
+			var (
+				got ResponseCache
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestResponseCache_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidResponseCache()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+}
+
+func TestResponseCache_IsValid_Methods(t *testing.T) {
+	tests := []struct {
+		name       string
+		methods    []string
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			methods:    nil,
+			wantErrMsg: methodsField + " is empty",
+		},
+		{
+			name:       "empty method",
+			methods:    []string{""},
+			wantErrMsg: methodsField + "[0] is empty",
+		},
+		{
+			name:       "invalid pattern",
+			methods:    []string{"eth_chainId", "eth_["},
+			wantErrMsg: methodsField + "[1] is not a valid pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidResponseCache()
+			c.Methods = tt.methods
+
+			err := c.IsValid()
+
+			require.EqualError(t, err, tt.wantErrMsg)
+		})
+	}
+}
+
+func TestResponseCache_IsValid_Size(t *testing.T) {
+	c := minimumValidResponseCache()
+	c.Size = 0
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, sizeField+" must be > 0")
+}
+
+func TestResponseCache_IsValid_TTL(t *testing.T) {
+	c := minimumValidResponseCache()
+	c.TTL = 0
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, ttlField+" must be > 0")
+}
@@ -22,6 +22,7 @@ func minimumValidBlockchainClient() BlockchainClient {
 
 func minimumValidPrivacyManager() PrivacyManager {
 	privManProcess := minimumValidProcess()
+	privManProcess.Name = "privman"
 
 	return PrivacyManager{
 		PrivManKey:       "mykey",
@@ -298,7 +299,7 @@ func TestBlockchainClient_IsValid_Process(t *testing.T) {
 		{
 			name:       "invalid",
 			process:    &invalidProcess,
-			wantErrMsg: fmt.Sprintf("%v.%v must be bcclnt or privman", processField, nameField),
+			wantErrMsg: fmt.Sprintf("%v.%v is empty", processField, nameField),
 		},
 	}
 
@@ -499,7 +500,7 @@ func TestPrivacyManager_IsValid_Process(t *testing.T) {
 		{
 			name:       "invalid",
 			process:    &invalidProcess,
-			wantErrMsg: fmt.Sprintf("%v.%v must be bcclnt or privman", processField, nameField),
+			wantErrMsg: fmt.Sprintf("%v.%v is empty", processField, nameField),
 		},
 	}
 
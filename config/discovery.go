@@ -0,0 +1,88 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+type Discovery struct {
+	PrivateKeyFile string             `toml:"privateKeyFile" json:"privateKeyFile"`       // file containing the hex encoded ed25519 private key seed used to sign the peer record of this node hibernator
+	RpcUrl         string             `toml:"rpcUrl" json:"rpcUrl"`                       // RPC url of this node hibernator advertised to peers
+	PrivManKey     string             `toml:"privacyManagerKey" json:"privacyManagerKey"` // privacy manager key of this node advertised to peers
+	BootstrapPeers []string           `toml:"bootstrapPeers" json:"bootstrapPeers"`       // RPC urls of node hibernators to exchange peer records with on startup
+	TrustedKeys    []string           `toml:"trustedKeys" json:"trustedKeys"`             // hex encoded ed25519 public keys of node hibernators whose peer records are accepted
+	Interval       int                `toml:"interval" json:"interval"`                   // time in seconds between exchanges of peer records
+	TLSConfig      *ClientTLS         `toml:"tlsConfig" json:"tlsConfig"`                 // tls config used to connect to bootstrap peers and peers which do not advertise a tls fingerprint
+	PrivateKey     ed25519.PrivateKey `toml:"-" json:"-"`                                 // private key read from PrivateKeyFile
+}
+
+// IsValid returns nil if the Discovery is valid else returns error. It reads the private key from PrivateKeyFile.
+func (c *Discovery) IsValid() error {
+	if c.PrivateKeyFile == "" {
+		return newFieldErr("privateKeyFile", isEmptyErr)
+	}
+	key, err := readPrivateKey(c.PrivateKeyFile)
+	if err != nil {
+		return newFieldErr("privateKeyFile", err)
+	}
+	c.PrivateKey = key
+
+	if c.RpcUrl == "" {
+		return newFieldErr("rpcUrl", isEmptyErr)
+	}
+	if _, err := url.Parse(c.RpcUrl); err != nil {
+		return newFieldErr("rpcUrl", err)
+	}
+
+	for i, p := range c.BootstrapPeers {
+		if p == "" {
+			return newArrFieldErr("bootstrapPeers", i, isEmptyErr)
+		}
+		if _, err := url.Parse(p); err != nil {
+			return newArrFieldErr("bootstrapPeers", i, err)
+		}
+	}
+
+	for i, k := range c.TrustedKeys {
+		if _, err := ParsePublicKey(k); err != nil {
+			return newArrFieldErr("trustedKeys", i, err)
+		}
+	}
+
+	if c.Interval <= 0 {
+		return newFieldErr("interval", isNotGreaterThanZeroErr)
+	}
+
+	if c.TLSConfig != nil {
+		if err := c.TLSConfig.IsValid(); err != nil {
+			return newFieldErr("tlsConfig", err)
+		}
+	}
+	return nil
+}
+
+// readPrivateKey reads a hex encoded ed25519 private key seed from file
+func readPrivateKey(file string) (ed25519.PrivateKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("must contain a hex encoded 32 byte ed25519 private key seed")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParsePublicKey parses a hex encoded ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("must be a hex encoded ed25519 public key")
+	}
+	return ed25519.PublicKey(b), nil
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const trustedKey = "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29"
+
+func minimumValidDiscovery() Discovery {
+	return Discovery{
+		PrivateKeyFile: "resources/discovery.key",
+		RpcUrl:         "http://localhost:8081",
+		Interval:       60,
+	}
+}
+
+func TestDiscovery_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": "/path/to/key",
+	"%v": "http://localhost:8081",
+	"%v": "akey",
+	"%v": ["http://localhost:8082"],
+	"%v": ["%v"],
+	"%v": 60,
+	"%v": {}
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = "/path/to/key"
+%v = "http://localhost:8081"
+%v = "akey"
+%v = ["http://localhost:8082"]
+%v = ["%v"]
+%v = 60
+%v = {}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, privateKeyFileField, rpcUrlField, privacyManagerKeyField, bootstrapPeersField, trustedKeysField, trustedKey, intervalField, tlsConfigField)
+
+			want := Discovery{
+				PrivateKeyFile: "/path/to/key",
+				RpcUrl:         "http://localhost:8081",
+				PrivManKey:     "akey",
+				BootstrapPeers: []string{"http://localhost:8082"},
+				TrustedKeys:    []string{trustedKey},
+				Interval:       60,
+				TLSConfig:      &ClientTLS{},
+			}
+
+			var (
+				got Discovery
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestDiscovery_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidDiscovery()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+	require.Len(t, c.PrivateKey, 64, "private key must be read")
+}
+
+func TestDiscovery_IsValid_PrivateKeyFile(t *testing.T) {
+	invalidKeyFile := filepath.Join(t.TempDir(), "invalid.key")
+	require.NoError(t, ioutil.WriteFile(invalidKeyFile, []byte("notakey"), 0600))
+
+	tests := []struct {
+		name, file, wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			file:       "",
+			wantErrMsg: privateKeyFileField + " is empty",
+		},
+		{
+			name:       "not found",
+			file:       "resources/notfound.key",
+			wantErrMsg: privateKeyFileField + " open resources/notfound.key: no such file or directory",
+		},
+		{
+			name:       "invalid",
+			file:       invalidKeyFile,
+			wantErrMsg: privateKeyFileField + " must contain a hex encoded 32 byte ed25519 private key seed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidDiscovery()
+			c.PrivateKeyFile = tt.file
+
+			err := c.IsValid()
+
+			require.IsType(t, &fieldErr{}, err)
+			require.EqualError(t, err, tt.wantErrMsg)
+		})
+	}
+}
+
+func TestDiscovery_IsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(c *Discovery)
+		wantErrMsg string
+	}{
+		{
+			name:       "rpcUrl not set",
+			modify:     func(c *Discovery) { c.RpcUrl = "" },
+			wantErrMsg: rpcUrlField + " is empty",
+		},
+		{
+			name:       "bootstrapPeers empty url",
+			modify:     func(c *Discovery) { c.BootstrapPeers = []string{"http://localhost:8082", ""} },
+			wantErrMsg: bootstrapPeersField + "[1] is empty",
+		},
+		{
+			name:       "trustedKeys invalid",
+			modify:     func(c *Discovery) { c.TrustedKeys = []string{trustedKey, strings.Repeat("a", 10)} },
+			wantErrMsg: trustedKeysField + "[1] must be a hex encoded ed25519 public key",
+		},
+		{
+			name:       "interval not set",
+			modify:     func(c *Discovery) { c.Interval = 0 },
+			wantErrMsg: intervalField + " must be > 0",
+		},
+		{
+			name:       "invalid tlsConfig",
+			modify:     func(c *Discovery) { c.TLSConfig = &ClientTLS{} },
+			wantErrMsg: tlsConfigField + ".caCertificateFile is empty",
+		},
+		{
+			name: "valid",
+			modify: func(c *Discovery) {
+				c.BootstrapPeers = []string{"http://localhost:8082"}
+				c.TrustedKeys = []string{trustedKey}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidDiscovery()
+			tt.modify(&c)
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ var (
 	isEmptyErr              = errors.New("is empty")
 	isNotUniqueErr          = errors.New("must be unique")
 	isNotGreaterThanZeroErr = errors.New("must be > 0")
+	isNotSupportedByTCPErr  = errors.New("is not supported by tcp proxies")
 )
 
 type fieldErr struct {
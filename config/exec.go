@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+type Exec struct {
+	Command     []string `toml:"command" json:"command"`         // binary to run followed by its arguments
+	Env         []string `toml:"env" json:"env"`                 // additional environment variables in the form KEY=VALUE
+	WorkDir     string   `toml:"workDir" json:"workDir"`         // working directory of the process. defaults to the node hibernator working directory
+	GracePeriod int      `toml:"gracePeriod" json:"gracePeriod"` // time in seconds to wait for the process to exit after SIGTERM before sending SIGKILL
+	PidFile     string   `toml:"pidFile" json:"pidFile"`         // file to which the PID of the process is written. allows a restarted node hibernator to control a process it spawned before
+	LogFile     string   `toml:"logFile" json:"logFile"`         // file to which stdout and stderr of the process are written. output is discarded if not set
+	MaxLogSize  int      `toml:"maxLogSize" json:"maxLogSize"`   // size in MB after which the log file is rotated. 0 disables rotation
+	MaxLogFiles int      `toml:"maxLogFiles" json:"maxLogFiles"` // number of rotated log files to keep
+}
+
+func (c Exec) IsValid() error {
+	if len(c.Command) == 0 {
+		return newFieldErr("command", isEmptyErr)
+	}
+	for i, e := range c.Env {
+		if !strings.Contains(e, "=") || strings.HasPrefix(e, "=") {
+			return newArrFieldErr("env", i, errors.New("must be in the form KEY=VALUE"))
+		}
+	}
+	if c.GracePeriod <= 0 {
+		return newFieldErr("gracePeriod", isNotGreaterThanZeroErr)
+	}
+	if c.MaxLogSize < 0 {
+		return newFieldErr("maxLogSize", errors.New("must be >= 0"))
+	}
+	if c.MaxLogFiles < 0 {
+		return newFieldErr("maxLogFiles", errors.New("must be >= 0"))
+	}
+	if c.MaxLogSize > 0 && c.LogFile == "" {
+		return newFieldErr("logFile", errors.New("must be set as maxLogSize is set"))
+	}
+	return nil
+}
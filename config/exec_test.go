@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidExec() Exec {
+	return Exec{
+		Command:     []string{"geth", "--datadir", "data"},
+		GracePeriod: 30,
+	}
+}
+
+func TestExec_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": ["geth", "--datadir", "data"],
+	"%v": ["PRIVATE_CONFIG=ignore"],
+	"%v": "/home/node",
+	"%v": 30,
+	"%v": "geth.pid",
+	"%v": "geth.log",
+	"%v": 100,
+	"%v": 5
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = ["geth", "--datadir", "data"]
+%v = ["PRIVATE_CONFIG=ignore"]
+%v = "/home/node"
+%v = 30
+%v = "geth.pid"
+%v = "geth.log"
+%v = 100
+%v = 5`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(
+				tt.configTemplate,
+				commandField,
+				envField,
+				workDirField,
+				gracePeriodField,
+				pidFileField,
+				logFileField,
+				maxLogSizeField,
+				maxLogFilesField,
+			)
+
+			want := Exec{
+				Command:     []string{"geth", "--datadir", "data"},
+				Env:         []string{"PRIVATE_CONFIG=ignore"},
+				WorkDir:     "/home/node",
+				GracePeriod: 30,
+				PidFile:     "geth.pid",
+				LogFile:     "geth.log",
+				MaxLogSize:  100,
+				MaxLogFiles: 5,
+			}
+
+			var (
+				got Exec
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestExec_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidExec()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+}
+
+func TestExec_IsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(c *Exec)
+		wantErrMsg string
+		wantType   interface{}
+	}{
+		{
+			name:       "command not set",
+			modify:     func(c *Exec) { c.Command = nil },
+			wantErrMsg: commandField + " is empty",
+			wantType:   &fieldErr{},
+		},
+		{
+			name:       "env without value",
+			modify:     func(c *Exec) { c.Env = []string{"A=1", "B"} },
+			wantErrMsg: envField + "[1] must be in the form KEY=VALUE",
+			wantType:   &arrFieldErr{},
+		},
+		{
+			name:       "env without key",
+			modify:     func(c *Exec) { c.Env = []string{"=1"} },
+			wantErrMsg: envField + "[0] must be in the form KEY=VALUE",
+			wantType:   &arrFieldErr{},
+		},
+		{
+			name:       "gracePeriod zero",
+			modify:     func(c *Exec) { c.GracePeriod = 0 },
+			wantErrMsg: gracePeriodField + " must be > 0",
+			wantType:   &fieldErr{},
+		},
+		{
+			name:       "maxLogSize negative",
+			modify:     func(c *Exec) { c.MaxLogSize = -1 },
+			wantErrMsg: maxLogSizeField + " must be >= 0",
+			wantType:   &fieldErr{},
+		},
+		{
+			name:       "maxLogFiles negative",
+			modify:     func(c *Exec) { c.MaxLogFiles = -1 },
+			wantErrMsg: maxLogFilesField + " must be >= 0",
+			wantType:   &fieldErr{},
+		},
+		{
+			name:       "maxLogSize without logFile",
+			modify:     func(c *Exec) { c.MaxLogSize = 10 },
+			wantErrMsg: fmt.Sprintf("%v must be set as %v is set", logFileField, maxLogSizeField),
+			wantType:   &fieldErr{},
+		},
+		{
+			name: "log rotation",
+			modify: func(c *Exec) {
+				c.LogFile = "geth.log"
+				c.MaxLogSize = 10
+				c.MaxLogFiles = 3
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidExec()
+			tt.modify(&c)
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, tt.wantType, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
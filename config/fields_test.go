@@ -10,6 +10,7 @@ const (
 	peersConfigFileField        = "peersConfigFile"
 	inactivityTimeField         = "inactivityTime"
 	resyncTimeField             = "resyncTime"
+	dataDirField                = "dataDir"
 	blockchainClientField       = "blockchainClient"
 	privacyManagerField         = "privacyManager"
 	serverField                 = "server"
@@ -49,4 +50,79 @@ const (
 	expectedField               = "expected"
 	peersField                  = "peers"
 	privacyManagerKeyField      = "privacyManagerKey"
+	watchdogField               = "watchdog"
+	deadlineField               = "deadline"
+	retryLimitField             = "retryLimit"
+	retryBackoffField           = "retryBackoff"
+	historyField                = "history"
+	sizeField                   = "size"
+	persistField                = "persist"
+	metricsField                = "metrics"
+	listenAddressField          = "listenAddress"
+	schedulesField              = "schedules"
+	modeField                   = "mode"
+	cronField                   = "cron"
+	timezoneField               = "timezone"
+	processesField              = "processes"
+	dependsOnField              = "dependsOn"
+	execConfigField             = "execConfig"
+	commandField                = "command"
+	envField                    = "env"
+	workDirField                = "workDir"
+	gracePeriodField            = "gracePeriod"
+	pidFileField                = "pidFile"
+	logFileField                = "logFile"
+	maxLogSizeField             = "maxLogSize"
+	maxLogFilesField            = "maxLogFiles"
+	kubernetesConfigField       = "kubernetesConfig"
+	kindField                   = "kind"
+	namespaceField              = "namespace"
+	replicasField               = "replicas"
+	kubeconfigField             = "kubeconfig"
+	contextField                = "context"
+	readyTimeoutField           = "readyTimeout"
+	requestQueueField           = "requestQueue"
+	maxLengthField              = "maxLength"
+	maxWaitField                = "maxWait"
+	activityMethodsField        = "activityMethods"
+	wakeMethodsField            = "wakeMethods"
+	allowField                  = "allow"
+	denyField                   = "deny"
+	responseCacheField          = "responseCache"
+	methodsField                = "methods"
+	ttlField                    = "ttl"
+	authField                   = "auth"
+	apiKeysField                = "apiKeys"
+	jwksFileField               = "jwksFile"
+	issuerField                 = "issuer"
+	audienceField               = "audience"
+	clientCertificateField      = "clientCertificate"
+	keyField                    = "key"
+	rateLimitField              = "rateLimit"
+	requestsField               = "requests"
+	wakesField                  = "wakes"
+	rateField                   = "rate"
+	burstField                  = "burst"
+	txHandlerField              = "txHandler"
+	upstreamsField              = "upstreams"
+	maxBodySizeField            = "maxBodySize"
+	discoveryField              = "discovery"
+	privateKeyFileField         = "privateKeyFile"
+	bootstrapPeersField         = "bootstrapPeers"
+	trustedKeysField            = "trustedKeys"
+	intervalField               = "interval"
+	addressesField              = "addresses"
+	policyField                 = "policy"
+	healthCheckField            = "healthCheck"
+	healthCheckIntervalField    = "healthCheckInterval"
+	hibernationLeaseField       = "hibernationLease"
+	durationField               = "duration"
+	maxConcurrentField          = "maxConcurrent"
+	unreachablePeersField       = "unreachablePeers"
+	ignoreAfterField            = "ignoreAfter"
+	failureThresholdField       = "failureThreshold"
+	openTimeField               = "openTime"
+	certificateSubjectField     = "certificateSubject"
+	aclField                    = "acl"
+	subjectsField               = "subjects"
 )
@@ -0,0 +1,15 @@
+package config
+
+import "errors"
+
+type History struct {
+	Size    int  `toml:"size" json:"size"`       // max number of lifecycle events kept. defaults to 1000 if not set
+	Persist bool `toml:"persist" json:"persist"` // persists events to dataDir so that they are retained across restarts
+}
+
+func (c History) IsValid() error {
+	if c.Size < 0 {
+		return newFieldErr("size", errors.New("must be >= 0"))
+	}
+	return nil
+}
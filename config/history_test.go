@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHistory_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": 100,
+	"%v": true
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = 100
+%v = true`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, sizeField, persistField)
+
+			want := History{
+				Size:    100,
+				Persist: true,
+			}
+
+			var (
+				got History
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestHistory_IsValid_Size(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		wantErrMsg string
+	}{
+		{
+			name:       "zero",
+			size:       0,
+			wantErrMsg: "",
+		},
+		{
+			name:       "positive",
+			size:       10,
+			wantErrMsg: "",
+		},
+		{
+			name:       "negative",
+			size:       -1,
+			wantErrMsg: sizeField + " must be >= 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := History{Size: tt.size}
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	defaultKubernetesReplicas     = 1
+	defaultKubernetesReadyTimeout = 300
+)
+
+type Kubernetes struct {
+	Kind         string `toml:"kind" json:"kind"`                 // kind of the workload. statefulset or deployment
+	Name         string `toml:"name" json:"name"`                 // name of the workload
+	Namespace    string `toml:"namespace" json:"namespace"`       // namespace of the workload. defaults to the namespace of the kubeconfig context or service account
+	Replicas     int    `toml:"replicas" json:"replicas"`         // number of replicas when the workload is awake. defaults to 1
+	Kubeconfig   string `toml:"kubeconfig" json:"kubeconfig"`     // path to kubeconfig file. in-cluster service account auth is used if not set
+	Context      string `toml:"context" json:"context"`           // kubeconfig context. defaults to the current context
+	ReadyTimeout int    `toml:"readyTimeout" json:"readyTimeout"` // time in seconds to wait for the pods to be ready/terminated after scaling. defaults to 300
+}
+
+func (c Kubernetes) IsStatefulSet() bool {
+	return strings.ToLower(c.Kind) == "statefulset"
+}
+
+func (c Kubernetes) IsDeployment() bool {
+	return strings.ToLower(c.Kind) == "deployment"
+}
+
+// ReplicasOrDefault returns the number of replicas when the workload is awake
+func (c Kubernetes) ReplicasOrDefault() int {
+	if c.Replicas == 0 {
+		return defaultKubernetesReplicas
+	}
+	return c.Replicas
+}
+
+// ReadyTimeoutOrDefault returns the time in seconds to wait for the pods to be ready/terminated after scaling
+func (c Kubernetes) ReadyTimeoutOrDefault() int {
+	if c.ReadyTimeout == 0 {
+		return defaultKubernetesReadyTimeout
+	}
+	return c.ReadyTimeout
+}
+
+func (c Kubernetes) IsValid() error {
+	if !c.IsStatefulSet() && !c.IsDeployment() {
+		return newFieldErr("kind", errors.New("must be statefulset or deployment"))
+	}
+	if c.Name == "" {
+		return newFieldErr("name", isEmptyErr)
+	}
+	if c.Replicas < 0 {
+		return newFieldErr("replicas", errors.New("must be >= 0"))
+	}
+	if c.ReadyTimeout < 0 {
+		return newFieldErr("readyTimeout", errors.New("must be >= 0"))
+	}
+	if c.Context != "" && c.Kubeconfig == "" {
+		return newFieldErr("kubeconfig", errors.New("must be set as context is set"))
+	}
+	return nil
+}
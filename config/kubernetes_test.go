@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidKubernetes() Kubernetes {
+	return Kubernetes{
+		Kind: "statefulset",
+		Name: "quorum-node1",
+	}
+}
+
+func TestKubernetes_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": "statefulset",
+	"%v": "quorum-node1",
+	"%v": "quorum",
+	"%v": 2,
+	"%v": "/home/user/.kube/config",
+	"%v": "cluster1",
+	"%v": 600
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = "statefulset"
+%v = "quorum-node1"
+%v = "quorum"
+%v = 2
+%v = "/home/user/.kube/config"
+%v = "cluster1"
+%v = 600`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(
+				tt.configTemplate,
+				kindField,
+				nameField,
+				namespaceField,
+				replicasField,
+				kubeconfigField,
+				contextField,
+				readyTimeoutField,
+			)
+
+			want := Kubernetes{
+				Kind:         "statefulset",
+				Name:         "quorum-node1",
+				Namespace:    "quorum",
+				Replicas:     2,
+				Kubeconfig:   "/home/user/.kube/config",
+				Context:      "cluster1",
+				ReadyTimeout: 600,
+			}
+
+			var (
+				got Kubernetes
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestKubernetes_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidKubernetes()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+}
+
+func TestKubernetes_IsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(c *Kubernetes)
+		wantErrMsg string
+	}{
+		{
+			name:       "kind not set",
+			modify:     func(c *Kubernetes) { c.Kind = "" },
+			wantErrMsg: kindField + " must be statefulset or deployment",
+		},
+		{
+			name:       "kind invalid",
+			modify:     func(c *Kubernetes) { c.Kind = "daemonset" },
+			wantErrMsg: kindField + " must be statefulset or deployment",
+		},
+		{
+			name:   "deployment",
+			modify: func(c *Kubernetes) { c.Kind = "Deployment" },
+		},
+		{
+			name:       "name not set",
+			modify:     func(c *Kubernetes) { c.Name = "" },
+			wantErrMsg: nameField + " is empty",
+		},
+		{
+			name:       "replicas negative",
+			modify:     func(c *Kubernetes) { c.Replicas = -1 },
+			wantErrMsg: replicasField + " must be >= 0",
+		},
+		{
+			name:       "readyTimeout negative",
+			modify:     func(c *Kubernetes) { c.ReadyTimeout = -1 },
+			wantErrMsg: readyTimeoutField + " must be >= 0",
+		},
+		{
+			name:       "context without kubeconfig",
+			modify:     func(c *Kubernetes) { c.Context = "cluster1" },
+			wantErrMsg: fmt.Sprintf("%v must be set as %v is set", kubeconfigField, contextField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidKubernetes()
+			tt.modify(&c)
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestKubernetes_Defaults(t *testing.T) {
+	c := minimumValidKubernetes()
+
+	require.Equal(t, 1, c.ReplicasOrDefault())
+	require.Equal(t, 300, c.ReadyTimeoutOrDefault())
+
+	c.Replicas = 3
+	c.ReadyTimeout = 60
+
+	require.Equal(t, 3, c.ReplicasOrDefault())
+	require.Equal(t, 60, c.ReadyTimeoutOrDefault())
+}
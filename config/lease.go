@@ -0,0 +1,39 @@
+package config
+
+import "errors"
+
+const (
+	defaultHibernationLeaseDuration      = 300
+	defaultHibernationLeaseMaxConcurrent = 1
+)
+
+type HibernationLease struct {
+	Duration      int `toml:"duration" json:"duration"`           // time in seconds for which a lease granted by this node hibernator is valid. defaults to 300
+	MaxConcurrent int `toml:"maxConcurrent" json:"maxConcurrent"` // number of leases this node hibernator grants at the same time, including its own. defaults to 1
+}
+
+// DurationOrDefault returns the time in seconds for which a lease is valid
+func (c HibernationLease) DurationOrDefault() int {
+	if c.Duration == 0 {
+		return defaultHibernationLeaseDuration
+	}
+	return c.Duration
+}
+
+// MaxConcurrentOrDefault returns the number of leases granted at the same time
+func (c HibernationLease) MaxConcurrentOrDefault() int {
+	if c.MaxConcurrent == 0 {
+		return defaultHibernationLeaseMaxConcurrent
+	}
+	return c.MaxConcurrent
+}
+
+func (c HibernationLease) IsValid() error {
+	if c.Duration < 0 {
+		return newFieldErr("duration", errors.New("must be >= 0"))
+	}
+	if c.MaxConcurrent < 0 {
+		return newFieldErr("maxConcurrent", errors.New("must be >= 0"))
+	}
+	return nil
+}
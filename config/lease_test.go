@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHibernationLease_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": 120,
+	"%v": 2
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = 120
+%v = 2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, durationField, maxConcurrentField)
+
+			want := HibernationLease{
+				Duration:      120,
+				MaxConcurrent: 2,
+			}
+
+			var (
+				got HibernationLease
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestHibernationLease_IsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		lease      HibernationLease
+		wantErrMsg string
+	}{
+		{
+			name:  "defaults",
+			lease: HibernationLease{},
+		},
+		{
+			name:  "valid",
+			lease: HibernationLease{Duration: 120, MaxConcurrent: 2},
+		},
+		{
+			name:       "negative duration",
+			lease:      HibernationLease{Duration: -1},
+			wantErrMsg: durationField + " must be >= 0",
+		},
+		{
+			name:       "negative maxConcurrent",
+			lease:      HibernationLease{MaxConcurrent: -1},
+			wantErrMsg: maxConcurrentField + " must be >= 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lease.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestHibernationLease_Defaults(t *testing.T) {
+	c := HibernationLease{}
+
+	require.Equal(t, 300, c.DurationOrDefault())
+	require.Equal(t, 1, c.MaxConcurrentOrDefault())
+
+	c.Duration = 120
+	c.MaxConcurrent = 2
+
+	require.Equal(t, 120, c.DurationOrDefault())
+	require.Equal(t, 2, c.MaxConcurrentOrDefault())
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"path"
+)
+
+// MethodFilter decides which JSON-RPC methods are allowed.
+// Methods are matched against glob patterns, e.g. admin_*.
+type MethodFilter struct {
+	Allow []string `toml:"allow" json:"allow"` // methods that are allowed. all methods are allowed if empty
+	Deny  []string `toml:"deny" json:"deny"`   // methods that are not allowed. takes precedence over allow
+}
+
+func (c MethodFilter) IsValid() error {
+	if len(c.Allow) == 0 && len(c.Deny) == 0 {
+		return errors.New("allow and deny are empty")
+	}
+	if err := isValidMethodPatterns("allow", c.Allow); err != nil {
+		return err
+	}
+	return isValidMethodPatterns("deny", c.Deny)
+}
+
+func isValidMethodPatterns(field string, patterns []string) error {
+	for i, p := range patterns {
+		if p == "" {
+			return newArrFieldErr(field, i, isEmptyErr)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return newArrFieldErr(field, i, errors.New("is not a valid pattern"))
+		}
+	}
+	return nil
+}
+
+// IsAllowed returns true if method does not match any deny pattern and, if allow patterns are set, matches one of them
+func (c MethodFilter) IsAllowed(method string) bool {
+	if matchesMethod(c.Deny, method) {
+		return false
+	}
+	return len(c.Allow) == 0 || matchesMethod(c.Allow, method)
+}
+
+func matchesMethod(patterns []string, method string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, method); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMethodFilter_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": ["eth_*"],
+	"%v": ["eth_blockNumber"]
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = ["eth_*"]
+%v = ["eth_blockNumber"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, allowField, denyField)
+
+			want := MethodFilter{
+				Allow: []string{"eth_*"},
+				Deny:  []string{"eth_blockNumber"},
+			}
+
+			var (
+				got MethodFilter
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestMethodFilter_IsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  MethodFilter
+		wantErr string
+	}{
+		{
+			name:    "not set",
+			filter:  MethodFilter{},
+			wantErr: fmt.Sprintf("%v and %v are empty", allowField, denyField),
+		},
+		{
+			name:    "empty allow pattern",
+			filter:  MethodFilter{Allow: []string{"eth_*", ""}},
+			wantErr: fmt.Sprintf("%v[1] is empty", allowField),
+		},
+		{
+			name:    "invalid deny pattern",
+			filter:  MethodFilter{Deny: []string{"admin_["}},
+			wantErr: fmt.Sprintf("%v[0] is not a valid pattern", denyField),
+		},
+		{
+			name:   "allow only",
+			filter: MethodFilter{Allow: []string{"eth_*"}},
+		},
+		{
+			name:   "deny only",
+			filter: MethodFilter{Deny: []string{"net_*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMethodFilter_IsAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter MethodFilter
+		method string
+		want   bool
+	}{
+		{
+			name:   "deny matched",
+			filter: MethodFilter{Deny: []string{"eth_blockNumber", "net_*"}},
+			method: "net_peerCount",
+			want:   false,
+		},
+		{
+			name:   "deny not matched",
+			filter: MethodFilter{Deny: []string{"eth_blockNumber", "net_*"}},
+			method: "eth_sendTransaction",
+			want:   true,
+		},
+		{
+			name:   "allow matched",
+			filter: MethodFilter{Allow: []string{"eth_send*"}},
+			method: "eth_sendRawTransaction",
+			want:   true,
+		},
+		{
+			name:   "allow not matched",
+			filter: MethodFilter{Allow: []string{"eth_send*"}},
+			method: "eth_getBalance",
+			want:   false,
+		},
+		{
+			name:   "deny takes precedence over allow",
+			filter: MethodFilter{Allow: []string{"admin_*"}, Deny: []string{"admin_peers"}},
+			method: "admin_peers",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.filter.IsAllowed(tt.method))
+		})
+	}
+}
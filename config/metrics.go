@@ -0,0 +1,9 @@
+package config
+
+type Metrics struct {
+	ListenAddress string `toml:"listenAddress" json:"listenAddress"` // listen address of a separate server for the metrics endpoint. if not set, metrics are served by the RPC server
+}
+
+func (c Metrics) IsSeparateServer() bool {
+	return c.ListenAddress != ""
+}
@@ -6,17 +6,23 @@ type PeerArr []*Peer
 
 func (a *PeerArr) IsValid() error {
 	nameList := make(map[string]bool, len(*a))
+	subjectList := make(map[string]bool, len(*a))
 	for i, c := range *a {
 		// check if the name is duplicate
 		if _, ok := nameList[c.Name]; ok {
 			return newArrFieldErr("peers", i, newFieldErr("name", isNotUniqueErr))
 		}
+		// check if the certificate subject is duplicate as the peer would be ambiguous
+		if _, ok := subjectList[c.CertSubjectOrName()]; ok {
+			return newArrFieldErr("peers", i, newFieldErr("certificateSubject", isNotUniqueErr))
+		}
 
 		// validate peer entry
 		if err := c.IsValid(); err != nil {
 			return newArrFieldErr("peers", i, err)
 		}
 		nameList[c.Name] = true
+		subjectList[c.CertSubjectOrName()] = true
 	}
 	return nil
 }
@@ -26,10 +32,19 @@ type NodeHibernatorList struct {
 }
 
 type Peer struct {
-	Name       string     `toml:"name" json:"name"`                           // Name of the other node hibernator
-	PrivManKey string     `toml:"privacyManagerKey" json:"privacyManagerKey"` // PrivManKey managed by the other node hibernator
-	RpcUrl     string     `toml:"rpcUrl" json:"rpcUrl"`                       // RPC url of the other node hibernator
-	TLSConfig  *ClientTLS `toml:"tlsConfig" json:"tlsConfig"`                 // tls config
+	Name        string     `toml:"name" json:"name"`                             // Name of the other node hibernator
+	PrivManKey  string     `toml:"privacyManagerKey" json:"privacyManagerKey"`   // PrivManKey managed by the other node hibernator
+	RpcUrl      string     `toml:"rpcUrl" json:"rpcUrl"`                         // RPC url of the other node hibernator
+	TLSConfig   *ClientTLS `toml:"tlsConfig" json:"tlsConfig"`                   // tls config
+	CertSubject string     `toml:"certificateSubject" json:"certificateSubject"` // subject common name of the client certificate the other node hibernator calls this node hibernator with. defaults to name
+}
+
+// CertSubjectOrName returns the subject common name of the client certificate of the other node hibernator
+func (c Peer) CertSubjectOrName() string {
+	if c.CertSubject == "" {
+		return c.Name
+	}
+	return c.CertSubject
 }
 
 // IsValid returns nil if the Peer is valid else returns error
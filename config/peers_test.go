@@ -31,7 +31,8 @@ func TestNodeHibernatorList_Unmarshal(t *testing.T) {
 			"%v": "mypeer",		
 			"%v": "akey",		
 			"%v": "http://url",		
-			"%v": {}		
+			"%v": {},
+			"%v": "mypeer-cn"
 		}
 	]
 }`,
@@ -43,21 +44,23 @@ func TestNodeHibernatorList_Unmarshal(t *testing.T) {
 %v = "mypeer"
 %v = "akey"
 %v = "http://url"
-%v = {}`,
+%v = {}
+%v = "mypeer-cn"`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			conf := fmt.Sprintf(tt.configTemplate, peersField, nameField, privacyManagerKeyField, rpcUrlField, tlsConfigField)
+			conf := fmt.Sprintf(tt.configTemplate, peersField, nameField, privacyManagerKeyField, rpcUrlField, tlsConfigField, certificateSubjectField)
 
 			want := NodeHibernatorList{
 				Peers: []*Peer{
 					{
-						Name:       "mypeer",
-						PrivManKey: "akey",
-						RpcUrl:     "http://url",
-						TLSConfig:  &ClientTLS{},
+						Name:        "mypeer",
+						PrivManKey:  "akey",
+						RpcUrl:      "http://url",
+						TLSConfig:   &ClientTLS{},
+						CertSubject: "mypeer-cn",
 					},
 				},
 			}
@@ -99,6 +102,10 @@ func TestPeerArr_IsValid(t *testing.T) {
 
 	anotherValidPeer := minimumValidPeer()
 
+	sameSubjectPeer := minimumValidPeer()
+	sameSubjectPeer.Name = "mypeer3"
+	sameSubjectPeer.CertSubject = "mypeer1"
+
 	tests := []struct {
 		name       string
 		peers      PeerArr
@@ -119,6 +126,11 @@ func TestPeerArr_IsValid(t *testing.T) {
 			peers:      PeerArr{&validPeer, &validPeer},
 			wantErrMsg: fmt.Sprintf("%v[1].%v must be unique", peersField, nameField),
 		},
+		{
+			name:       "duplicate certificate subjects",
+			peers:      PeerArr{&validPeer, &sameSubjectPeer},
+			wantErrMsg: fmt.Sprintf("%v[1].%v must be unique", peersField, certificateSubjectField),
+		},
 		{
 			name:       "no error",
 			peers:      PeerArr{&validPeer, &anotherValidPeer},
@@ -2,16 +2,20 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
 type Process struct {
-	Name         string   `toml:"name" json:"name"`                   // name of process. should be bcclnt or privman
-	ControlType  string   `toml:"controlType" json:"controlType"`     // control type supported. shell or docker
-	ContainerId  string   `toml:"containerId" json:"containerId"`     // docker container id. required if controlType is docker
-	StopCommand  []string `toml:"stopCommand" json:"stopCommand"`     // stop command. required if controlType is shell
-	StartCommand []string `toml:"startCommand" json:"startCommand"`   // start command. required if controlType is shell
-	UpcheckCfg   *Upcheck `toml:"upcheckConfig" json:"upcheckConfig"` // Upcheck config
+	Name          string      `toml:"name" json:"name"`                         // name of process. must be unique among the processes managed by this node hibernator
+	ControlType   string      `toml:"controlType" json:"controlType"`           // control type supported. shell, docker, exec or kubernetes
+	ContainerId   string      `toml:"containerId" json:"containerId"`           // docker container id. required if controlType is docker
+	StopCommand   []string    `toml:"stopCommand" json:"stopCommand"`           // stop command. required if controlType is shell
+	StartCommand  []string    `toml:"startCommand" json:"startCommand"`         // start command. required if controlType is shell
+	ExecCfg       *Exec       `toml:"execConfig" json:"execConfig"`             // exec config. required if controlType is exec
+	KubernetesCfg *Kubernetes `toml:"kubernetesConfig" json:"kubernetesConfig"` // kubernetes config. required if controlType is kubernetes
+	UpcheckCfg    *Upcheck    `toml:"upcheckConfig" json:"upcheckConfig"`       // Upcheck config
+	DependsOn     []string    `toml:"dependsOn" json:"dependsOn"`               // names of processes that must be started before and stopped after this process
 }
 
 func (c Process) IsShell() bool {
@@ -22,20 +26,20 @@ func (c Process) IsDocker() bool {
 	return strings.ToLower(c.ControlType) == "docker"
 }
 
-func (c Process) IsBcClient() bool {
-	return strings.ToLower(c.Name) == "bcclnt"
+func (c Process) IsExec() bool {
+	return strings.ToLower(c.ControlType) == "exec"
 }
 
-func (c Process) IsPrivacyManager() bool {
-	return strings.ToLower(c.Name) == "privman"
+func (c Process) IsKubernetes() bool {
+	return strings.ToLower(c.ControlType) == "kubernetes"
 }
 
 func (c Process) IsValid() error {
-	if !c.IsDocker() && !c.IsShell() {
-		return newFieldErr("controlType", errors.New("must be shell or docker"))
+	if !c.IsDocker() && !c.IsShell() && !c.IsExec() && !c.IsKubernetes() {
+		return newFieldErr("controlType", errors.New("must be shell, docker, exec or kubernetes"))
 	}
-	if !c.IsBcClient() && !c.IsPrivacyManager() {
-		return newFieldErr("name", errors.New("must be bcclnt or privman"))
+	if c.Name == "" {
+		return newFieldErr("name", isEmptyErr)
 	}
 	if c.IsDocker() && c.ContainerId == "" {
 		return newFieldErr("containerId", errors.New("must be set as controlType is docker"))
@@ -46,6 +50,22 @@ func (c Process) IsValid() error {
 	if c.IsShell() && len(c.StopCommand) == 0 {
 		return newFieldErr("stopCommand", errors.New("must be set as controlType is shell"))
 	}
+	if c.IsExec() && c.ExecCfg == nil {
+		return newFieldErr("execConfig", errors.New("must be set as controlType is exec"))
+	}
+	if c.IsExec() {
+		if err := c.ExecCfg.IsValid(); err != nil {
+			return newFieldErr("execConfig", err)
+		}
+	}
+	if c.IsKubernetes() && c.KubernetesCfg == nil {
+		return newFieldErr("kubernetesConfig", errors.New("must be set as controlType is kubernetes"))
+	}
+	if c.IsKubernetes() {
+		if err := c.KubernetesCfg.IsValid(); err != nil {
+			return newFieldErr("kubernetesConfig", err)
+		}
+	}
 	if c.UpcheckCfg == nil {
 		return newFieldErr("upcheckConfig", isEmptyErr)
 	}
@@ -54,3 +74,86 @@ func (c Process) IsValid() error {
 	}
 	return nil
 }
+
+// ManagedProcesses returns the processes managed by this node hibernator: the blockchain client,
+// the privacy manager if set and the additional processes
+func (c Basic) ManagedProcesses() []*Process {
+	var ps []*Process
+	if c.BlockchainClient != nil && c.BlockchainClient.BcClntProcess != nil {
+		ps = append(ps, c.BlockchainClient.BcClntProcess)
+	}
+	if c.PrivacyManager != nil && c.PrivacyManager.PrivManProcess != nil {
+		ps = append(ps, c.PrivacyManager.PrivManProcess)
+	}
+	return append(ps, c.Processes...)
+}
+
+// ProcessDependencies returns the names of the processes that must be started before and stopped after p.
+// In addition to the declared dependencies, the blockchain client always depends on the privacy manager.
+func (c Basic) ProcessDependencies(p *Process) []string {
+	deps := p.DependsOn
+	if c.BlockchainClient != nil && p == c.BlockchainClient.BcClntProcess && c.PrivacyManager != nil && c.PrivacyManager.PrivManProcess != nil {
+		deps = append([]string{c.PrivacyManager.PrivManProcess.Name}, deps...)
+	}
+	return deps
+}
+
+// ProcessLevels groups the managed processes into levels such that every process is in a later level than
+// the processes it depends on. Processes in the same level do not depend on each other.
+// Processes should be started level by level in the returned order and stopped in the reverse order.
+func (c Basic) ProcessLevels() ([][]*Process, error) {
+	processes := c.ManagedProcesses()
+	byName := make(map[string]*Process)
+	for _, p := range processes {
+		if _, ok := byName[p.Name]; ok {
+			return nil, fmt.Errorf("%v must have a unique name", p.Name)
+		}
+		byName[p.Name] = p
+	}
+
+	remaining := make(map[*Process]int) // number of dependencies not yet placed in a level
+	dependants := make(map[string][]*Process)
+	for _, p := range processes {
+		for _, d := range c.ProcessDependencies(p) {
+			if _, ok := byName[d]; !ok {
+				return nil, fmt.Errorf("%v depends on unknown process %v", p.Name, d)
+			}
+			remaining[p]++
+			dependants[d] = append(dependants[d], p)
+		}
+	}
+
+	var levels [][]*Process
+	var level []*Process
+	for _, p := range processes {
+		if remaining[p] == 0 {
+			level = append(level, p)
+		}
+	}
+	placed := 0
+	for len(level) > 0 {
+		levels = append(levels, level)
+		placed += len(level)
+		var next []*Process
+		for _, p := range level {
+			for _, d := range dependants[p.Name] {
+				remaining[d]--
+				if remaining[d] == 0 {
+					next = append(next, d)
+				}
+			}
+		}
+		level = next
+	}
+
+	if placed != len(processes) {
+		var cyclic []string
+		for _, p := range processes {
+			if remaining[p] > 0 {
+				cyclic = append(cyclic, p.Name)
+			}
+		}
+		return nil, fmt.Errorf("have a dependency cycle between %v", strings.Join(cyclic, ", "))
+	}
+	return levels, nil
+}
@@ -34,7 +34,10 @@ func TestProcess_Unmarshal(t *testing.T) {
 	"%v": "mycontainer",
 	"%v": ["stop.sh"],
 	"%v": ["start.sh"],
-	"%v": {}
+	"%v": {},
+	"%v": {},
+	"%v": {},
+	"%v": ["privman"]
 }`,
 		},
 		{
@@ -45,7 +48,10 @@ func TestProcess_Unmarshal(t *testing.T) {
 %v = "mycontainer"
 %v = ["stop.sh"]
 %v = ["start.sh"]
-%v = {}`,
+%v = {}
+%v = {}
+%v = {}
+%v = ["privman"]`,
 		},
 	}
 
@@ -58,16 +64,22 @@ func TestProcess_Unmarshal(t *testing.T) {
 				containerIdField,
 				stopCommandField,
 				startCommandField,
+				execConfigField,
+				kubernetesConfigField,
 				upcheckConfigField,
+				dependsOnField,
 			)
 
 			want := Process{
-				Name:         "bcclnt",
-				ControlType:  "shell",
-				ContainerId:  "mycontainer",
-				StopCommand:  []string{"stop.sh"},
-				StartCommand: []string{"start.sh"},
-				UpcheckCfg:   &Upcheck{},
+				Name:          "bcclnt",
+				ControlType:   "shell",
+				ContainerId:   "mycontainer",
+				StopCommand:   []string{"stop.sh"},
+				StartCommand:  []string{"start.sh"},
+				ExecCfg:       &Exec{},
+				KubernetesCfg: &Kubernetes{},
+				UpcheckCfg:    &Upcheck{},
+				DependsOn:     []string{"privman"},
 			}
 
 			var (
@@ -102,12 +114,12 @@ func TestProcess_IsValid_Name(t *testing.T) {
 		{
 			name:       "not set",
 			nameField:  "",
-			wantErrMsg: nameField + " must be bcclnt or privman",
+			wantErrMsg: nameField + " is empty",
 		},
 		{
-			name:       "invalid",
-			nameField:  "invalid",
-			wantErrMsg: nameField + " must be bcclnt or privman",
+			name:       "other process",
+			nameField:  "ethsigner",
+			wantErrMsg: "",
 		},
 		{
 			name:       "bcclnt",
@@ -145,12 +157,12 @@ func TestProcess_IsValid_ControlType(t *testing.T) {
 		{
 			name:        "not set",
 			controlType: "",
-			wantErrMsg:  controlTypeField + " must be shell or docker",
+			wantErrMsg:  controlTypeField + " must be shell, docker, exec or kubernetes",
 		},
 		{
 			name:        "invalid",
 			controlType: "invalid",
-			wantErrMsg:  controlTypeField + " must be shell or docker",
+			wantErrMsg:  controlTypeField + " must be shell, docker, exec or kubernetes",
 		},
 	}
 
@@ -204,6 +216,100 @@ func TestProcess_IsValid_StopCommand(t *testing.T) {
 	require.EqualError(t, err, fmt.Sprintf("%v must be set as %v is shell", stopCommandField, controlTypeField))
 }
 
+func TestProcess_IsValid_ExecConfig(t *testing.T) {
+	invalidExec := minimumValidExec()
+	invalidExec.Command = nil
+	validExec := minimumValidExec()
+
+	tests := []struct {
+		name       string
+		execConfig *Exec
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			execConfig: nil,
+			wantErrMsg: fmt.Sprintf("%v must be set as %v is exec", execConfigField, controlTypeField),
+		},
+		{
+			name:       "invalid",
+			execConfig: &invalidExec,
+			wantErrMsg: fmt.Sprintf("%v.%v is empty", execConfigField, commandField),
+		},
+		{
+			name:       "valid",
+			execConfig: &validExec,
+			wantErrMsg: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProcess()
+			c.ControlType = "exec"
+			c.StartCommand = nil
+			c.StopCommand = nil
+			c.ExecCfg = tt.execConfig
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestProcess_IsValid_KubernetesConfig(t *testing.T) {
+	invalidKubernetes := minimumValidKubernetes()
+	invalidKubernetes.Name = ""
+	validKubernetes := minimumValidKubernetes()
+
+	tests := []struct {
+		name             string
+		kubernetesConfig *Kubernetes
+		wantErrMsg       string
+	}{
+		{
+			name:             "not set",
+			kubernetesConfig: nil,
+			wantErrMsg:       fmt.Sprintf("%v must be set as %v is kubernetes", kubernetesConfigField, controlTypeField),
+		},
+		{
+			name:             "invalid",
+			kubernetesConfig: &invalidKubernetes,
+			wantErrMsg:       fmt.Sprintf("%v.%v is empty", kubernetesConfigField, nameField),
+		},
+		{
+			name:             "valid",
+			kubernetesConfig: &validKubernetes,
+			wantErrMsg:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProcess()
+			c.ControlType = "kubernetes"
+			c.StartCommand = nil
+			c.StopCommand = nil
+			c.KubernetesCfg = tt.kubernetesConfig
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
 func TestProcess_IsValid_UpcheckConfig(t *testing.T) {
 	invalidUpcheck := minimumValidUpcheck()
 	invalidUpcheck.UpcheckUrl = ""
@@ -296,19 +402,19 @@ func TestProcess_IsDocker(t *testing.T) {
 	}
 }
 
-func TestProcess_IsBcClient(t *testing.T) {
+func TestProcess_IsExec(t *testing.T) {
 	tests := []struct {
-		name, processName string
+		name, controlType string
 		want              bool
 	}{
 		{
-			name:        "not bcclnt",
-			processName: "privman",
+			name:        "not exec",
+			controlType: "shell",
 			want:        false,
 		},
 		{
-			name:        "is bcclnt",
-			processName: "bcclnt",
+			name:        "is exec",
+			controlType: "exec",
 			want:        true,
 		},
 	}
@@ -316,26 +422,26 @@ func TestProcess_IsBcClient(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := Process{
-				Name: tt.processName,
+				ControlType: tt.controlType,
 			}
-			require.Equal(t, tt.want, c.IsBcClient())
+			require.Equal(t, tt.want, c.IsExec())
 		})
 	}
 }
 
-func TestProcess_IsPrivacyManager(t *testing.T) {
+func TestProcess_IsKubernetes(t *testing.T) {
 	tests := []struct {
-		name, processName string
+		name, controlType string
 		want              bool
 	}{
 		{
-			name:        "not privman",
-			processName: "bcclnt",
+			name:        "not kubernetes",
+			controlType: "docker",
 			want:        false,
 		},
 		{
-			name:        "is privman",
-			processName: "privman",
+			name:        "is kubernetes",
+			controlType: "kubernetes",
 			want:        true,
 		},
 	}
@@ -343,9 +449,9 @@ func TestProcess_IsPrivacyManager(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := Process{
-				Name: tt.processName,
+				ControlType: tt.controlType,
 			}
-			require.Equal(t, tt.want, c.IsPrivacyManager())
+			require.Equal(t, tt.want, c.IsKubernetes())
 		})
 	}
 }
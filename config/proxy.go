@@ -2,21 +2,34 @@ package config
 
 import (
 	"errors"
+	"net"
 	"net/url"
 	"strings"
 )
 
+// defaultMaxBodySize is the default maximum size in bytes of http proxy request bodies, the same as the limit of the geth http server
+const defaultMaxBodySize = 5 * 1024 * 1024
+
 type Proxy struct {
-	Name                   string     `toml:"name" json:"name"`                                     // name of node hibernator process
-	Type                   string     `toml:"type" json:"type"`                                     // proxy scheme - http or ws
-	ProxyAddr              string     `toml:"proxyAddress" json:"proxyAddress"`                     // proxy address
-	UpstreamAddr           string     `toml:"upstreamAddress" json:"upstreamAddress"`               // upstream address of the proxy address
-	ProxyPaths             []string   `toml:"proxyPaths" json:"proxyPaths"`                         // httpRequestURI paths of the upstream address
-	IgnorePathsForActivity []string   `toml:"ignorePathsForActivity" json:"ignorePathsForActivity"` // httpRequestURI paths of the upstream address that should be ignored for activity
-	ReadTimeout            int        `toml:"readTimeout" json:"readTimeout"`                       // readTimeout of the proxy server
-	WriteTimeout           int        `toml:"writeTimeout" json:"writeTimeout"`                     // writeTimeout of the proxy server
-	ProxyServerTLSConfig   *ServerTLS `toml:"proxyTlsConfig" json:"proxyTlsConfig"`                 // proxy server tls config
-	ClientTLSConfig        *ClientTLS `toml:"clientTlsConfig" json:"clientTlsConfig"`               // reverse proxy client tls config
+	Name                   string         `toml:"name" json:"name"`                                     // name of node hibernator process
+	Type                   string         `toml:"type" json:"type"`                                     // proxy scheme - http, ws or tcp
+	ProxyAddr              string         `toml:"proxyAddress" json:"proxyAddress"`                     // proxy address
+	UpstreamAddr           string         `toml:"upstreamAddress" json:"upstreamAddress"`               // upstream address of the proxy address
+	ProxyPaths             []string       `toml:"proxyPaths" json:"proxyPaths"`                         // httpRequestURI paths of the upstream address
+	IgnorePathsForActivity []string       `toml:"ignorePathsForActivity" json:"ignorePathsForActivity"` // httpRequestURI paths of the upstream address that should be ignored for activity
+	ReadTimeout            int            `toml:"readTimeout" json:"readTimeout"`                       // readTimeout of the proxy server
+	WriteTimeout           int            `toml:"writeTimeout" json:"writeTimeout"`                     // writeTimeout of the proxy server
+	MaxBodySize            int            `toml:"maxBodySize" json:"maxBodySize"`                       // max size in bytes of http request bodies. defaults to 5 MiB
+	ProxyServerTLSConfig   *ServerTLS     `toml:"proxyTlsConfig" json:"proxyTlsConfig"`                 // proxy server tls config
+	ClientTLSConfig        *ClientTLS     `toml:"clientTlsConfig" json:"clientTlsConfig"`               // reverse proxy client tls config
+	RequestQueue           *RequestQueue  `toml:"requestQueue" json:"requestQueue"`                     // queue for requests received while the node is being started. requests fail immediately if not set
+	ActivityMethods        *MethodFilter  `toml:"activityMethods" json:"activityMethods"`               // JSON-RPC methods that count as activity. all methods count if not set
+	WakeMethods            *MethodFilter  `toml:"wakeMethods" json:"wakeMethods"`                       // JSON-RPC methods that may wake the node. all methods may if not set
+	ResponseCache          *ResponseCache `toml:"responseCache" json:"responseCache"`                   // cache of responses used to answer requests while the node is down. responses are not cached if not set
+	Auth                   *ProxyAuth     `toml:"auth" json:"auth"`                                     // authentication of clients. clients are not authenticated if not set
+	RateLimit              *RateLimit     `toml:"rateLimit" json:"rateLimit"`                           // rate limits per client. requests are not limited if not set
+	Upstreams              *Upstreams     `toml:"upstreams" json:"upstreams"`                           // additional upstreams with failover. only upstreamAddress is used if not set
+	TxHandler              string         `toml:"txHandler" json:"txHandler"`                           // handler of private transactions - tessera. the blockchain client's handler is used if not set
 }
 
 func (c Proxy) IsHttp() bool {
@@ -27,13 +40,30 @@ func (c Proxy) IsWS() bool {
 	return strings.ToLower(c.Type) == "ws"
 }
 
+func (c Proxy) IsTCP() bool {
+	return strings.ToLower(c.Type) == "tcp"
+}
+
+// MaxBodySizeOrDefault returns the max size in bytes of http request bodies
+func (c Proxy) MaxBodySizeOrDefault() int64 {
+	if c.MaxBodySize == 0 {
+		return defaultMaxBodySize
+	}
+	return int64(c.MaxBodySize)
+}
+
+// IsTesseraTxHandler returns true if private transactions are sent to the proxy using the Tessera REST API
+func (c Proxy) IsTesseraTxHandler() bool {
+	return strings.ToLower(c.TxHandler) == "tessera"
+}
+
 // IsValid returns nil if the Proxy is valid else returns error
 func (c Proxy) IsValid() error {
 	if c.Name == "" {
 		return newFieldErr("name", isEmptyErr)
 	}
-	if !c.IsWS() && !c.IsHttp() {
-		return newFieldErr("type", errors.New("must be http or ws"))
+	if !c.IsWS() && !c.IsHttp() && !c.IsTCP() {
+		return newFieldErr("type", errors.New("must be http, ws or tcp"))
 	}
 	if c.ProxyAddr == "" {
 		return newFieldErr("proxyAddress", isEmptyErr)
@@ -42,10 +72,31 @@ func (c Proxy) IsValid() error {
 	if c.UpstreamAddr == "" {
 		return newFieldErr("upstreamAddress", isEmptyErr)
 	}
-	if _, err := url.Parse(c.UpstreamAddr); err != nil {
+	if c.IsTCP() {
+		if _, _, err := net.SplitHostPort(c.UpstreamAddr); err != nil {
+			return newFieldErr("upstreamAddress", errors.New("must be host:port for tcp proxies"))
+		}
+	} else if _, err := url.Parse(c.UpstreamAddr); err != nil {
 		return newFieldErr("upstreamAddress", err)
 	}
-	if len(c.ProxyPaths) == 0 {
+	if c.IsTCP() {
+		// tcp proxies are not http based so there are no paths or JSON-RPC methods
+		if len(c.ProxyPaths) != 0 {
+			return newFieldErr("proxyPaths", isNotSupportedByTCPErr)
+		}
+		if len(c.IgnorePathsForActivity) != 0 {
+			return newFieldErr("ignorePathsForActivity", isNotSupportedByTCPErr)
+		}
+		if c.ActivityMethods != nil {
+			return newFieldErr("activityMethods", isNotSupportedByTCPErr)
+		}
+		if c.WakeMethods != nil {
+			return newFieldErr("wakeMethods", isNotSupportedByTCPErr)
+		}
+		if c.Auth != nil {
+			return newFieldErr("auth", isNotSupportedByTCPErr)
+		}
+	} else if len(c.ProxyPaths) == 0 {
 		return newFieldErr("proxyPaths", isEmptyErr)
 	}
 	if c.ReadTimeout == 0 {
@@ -54,6 +105,12 @@ func (c Proxy) IsValid() error {
 	if c.WriteTimeout == 0 {
 		return newFieldErr("writeTimeout", isNotGreaterThanZeroErr)
 	}
+	if c.MaxBodySize < 0 {
+		return newFieldErr("maxBodySize", errors.New("must be >= 0"))
+	}
+	if c.MaxBodySize != 0 && !c.IsHttp() {
+		return newFieldErr("maxBodySize", errors.New("is only supported by http proxies"))
+	}
 
 	if c.ProxyServerTLSConfig != nil {
 		if err := c.ProxyServerTLSConfig.IsValid(); err != nil {
@@ -67,5 +124,73 @@ func (c Proxy) IsValid() error {
 		}
 	}
 
+	if c.RequestQueue != nil {
+		if err := c.RequestQueue.IsValid(); err != nil {
+			return newFieldErr("requestQueue", err)
+		}
+		if c.IsHttp() && c.RequestQueue.MaxWait >= c.WriteTimeout {
+			return newFieldErr("requestQueue", errors.New("maxWait must be < writeTimeout"))
+		}
+	}
+
+	if c.ActivityMethods != nil {
+		if err := c.ActivityMethods.IsValid(); err != nil {
+			return newFieldErr("activityMethods", err)
+		}
+	}
+
+	if c.WakeMethods != nil {
+		if err := c.WakeMethods.IsValid(); err != nil {
+			return newFieldErr("wakeMethods", err)
+		}
+	}
+
+	if c.ResponseCache != nil {
+		if !c.IsHttp() {
+			return newFieldErr("responseCache", errors.New("is only supported by http proxies"))
+		}
+		if err := c.ResponseCache.IsValid(); err != nil {
+			return newFieldErr("responseCache", err)
+		}
+	}
+
+	if c.Auth != nil {
+		if err := c.Auth.IsValid(); err != nil {
+			return newFieldErr("auth", err)
+		}
+		if c.Auth.ClientCert && (c.ProxyServerTLSConfig == nil || c.ProxyServerTLSConfig.ClientCaCertFile == "") {
+			return newFieldErr("auth", errors.New("clientCertificate requires proxyTlsConfig.clientCaCertificateFile to be set"))
+		}
+	}
+
+	if c.RateLimit != nil {
+		if err := c.RateLimit.IsValid(); err != nil {
+			return newFieldErr("rateLimit", err)
+		}
+	}
+
+	if c.Upstreams != nil {
+		if !c.IsHttp() {
+			return newFieldErr("upstreams", errors.New("is only supported by http proxies"))
+		}
+		if err := c.Upstreams.IsValid(); err != nil {
+			return newFieldErr("upstreams", err)
+		}
+		for i, a := range c.Upstreams.Addresses {
+			if a == c.UpstreamAddr {
+				return newFieldErr("upstreams", newArrFieldErr("addresses", i, errors.New("must not be upstreamAddress")))
+			}
+		}
+	}
+
+	if c.TxHandler != "" {
+		if !c.IsTesseraTxHandler() {
+			return newFieldErr("txHandler", errors.New("must be tessera"))
+		}
+		if !c.IsHttp() {
+			return newFieldErr("txHandler", errors.New("tessera is only supported by http proxies"))
+		}
+	}
+
 	return nil
 }
@@ -23,6 +23,17 @@ func minimumValidProxy() Proxy {
 	}
 }
 
+func minimumValidTCPProxy() Proxy {
+	return Proxy{
+		Name:         "mytcpproxy",
+		Type:         "tcp",
+		ProxyAddr:    "localhost:9001",
+		UpstreamAddr: "localhost:9002",
+		ReadTimeout:  15,
+		WriteTimeout: 15,
+	}
+}
+
 func TestProxy_Unmarshal(t *testing.T) {
 	tests := []struct {
 		name, configTemplate string
@@ -39,8 +50,17 @@ func TestProxy_Unmarshal(t *testing.T) {
 	"%v": ["/ignore"],
 	"%v": 15,
 	"%v": 15,
+	"%v": 1024,
+	"%v": {},
+	"%v": {},
+	"%v": {},
+	"%v": {},
+	"%v": {},
 	"%v": {},
-	"%v": {}
+	"%v": {},
+	"%v": {},
+	"%v": {},
+	"%v": "tessera"
 }`,
 		},
 		{
@@ -54,8 +74,17 @@ func TestProxy_Unmarshal(t *testing.T) {
 %v = ["/ignore"]
 %v = 15
 %v = 15
+%v = 1024
+%v = {}
+%v = {}
+%v = {}
+%v = {}
+%v = {}
+%v = {}
 %v = {}
-%v = {}`,
+%v = {}
+%v = {}
+%v = "tessera"`,
 		},
 	}
 
@@ -71,8 +100,17 @@ func TestProxy_Unmarshal(t *testing.T) {
 				ignorePathsForActivityField,
 				readTimeoutField,
 				writeTimeoutField,
+				maxBodySizeField,
 				proxyTlsConfigField,
 				clientTlsConfigField,
+				requestQueueField,
+				activityMethodsField,
+				wakeMethodsField,
+				responseCacheField,
+				authField,
+				rateLimitField,
+				upstreamsField,
+				txHandlerField,
 			)
 
 			want := Proxy{
@@ -84,8 +122,17 @@ func TestProxy_Unmarshal(t *testing.T) {
 				IgnorePathsForActivity: []string{"/ignore"},
 				ReadTimeout:            15,
 				WriteTimeout:           15,
+				MaxBodySize:            1024,
 				ProxyServerTLSConfig:   &ServerTLS{},
 				ClientTLSConfig:        &ClientTLS{},
+				RequestQueue:           &RequestQueue{},
+				ActivityMethods:        &MethodFilter{},
+				WakeMethods:            &MethodFilter{},
+				ResponseCache:          &ResponseCache{},
+				Auth:                   &ProxyAuth{},
+				RateLimit:              &RateLimit{},
+				Upstreams:              &Upstreams{},
+				TxHandler:              "tessera",
 			}
 
 			var (
@@ -131,12 +178,12 @@ func TestUpcheck_IsValid_Type(t *testing.T) {
 		{
 			name:       "not set",
 			proxyType:  "",
-			wantErrMsg: typeField + " must be http or ws",
+			wantErrMsg: typeField + " must be http, ws or tcp",
 		},
 		{
 			name:       "invalid",
 			proxyType:  "unix",
-			wantErrMsg: typeField + " must be http or ws",
+			wantErrMsg: typeField + " must be http, ws or tcp",
 		},
 		{
 			name:       "http",
@@ -167,6 +214,77 @@ func TestUpcheck_IsValid_Type(t *testing.T) {
 	}
 }
 
+func TestProxy_IsValid_TCP(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Proxy)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Proxy) {},
+		},
+		{
+			name: "valid with request queue and rate limit",
+			modify: func(c *Proxy) {
+				c.RequestQueue = &RequestQueue{MaxLength: 10, MaxWait: 60}
+				c.RateLimit = &RateLimit{Requests: &TokenBucket{Rate: 1, Burst: 5}}
+			},
+		},
+		{
+			name:    "upstream address is url",
+			modify:  func(c *Proxy) { c.UpstreamAddr = "http://localhost:9002" },
+			wantErr: upstreamAddressField + " must be host:port for tcp proxies",
+		},
+		{
+			name:    "proxy paths",
+			modify:  func(c *Proxy) { c.ProxyPaths = []string{"/"} },
+			wantErr: proxyPathsField + " is not supported by tcp proxies",
+		},
+		{
+			name:    "ignore paths for activity",
+			modify:  func(c *Proxy) { c.IgnorePathsForActivity = []string{"/upcheck"} },
+			wantErr: ignorePathsForActivityField + " is not supported by tcp proxies",
+		},
+		{
+			name:    "activity methods",
+			modify:  func(c *Proxy) { c.ActivityMethods = &MethodFilter{Deny: []string{"*"}} },
+			wantErr: activityMethodsField + " is not supported by tcp proxies",
+		},
+		{
+			name:    "wake methods",
+			modify:  func(c *Proxy) { c.WakeMethods = &MethodFilter{Deny: []string{"*"}} },
+			wantErr: wakeMethodsField + " is not supported by tcp proxies",
+		},
+		{
+			name:    "auth",
+			modify:  func(c *Proxy) { c.Auth = &ProxyAuth{ClientCert: true} },
+			wantErr: authField + " is not supported by tcp proxies",
+		},
+		{
+			name:    "response cache",
+			modify:  func(c *Proxy) { c.ResponseCache = &ResponseCache{Methods: []string{"eth_chainId"}, Size: 10, TTL: 60} },
+			wantErr: responseCacheField + " is only supported by http proxies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidTCPProxy()
+			tt.modify(&c)
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestProxy_IsValid_ProxyAddress(t *testing.T) {
 	tests := []struct {
 		name, proxyAddr, wantErr string
@@ -291,6 +409,327 @@ func TestProxy_IsValid_ClientTLSConfig(t *testing.T) {
 	require.EqualError(t, err, fmt.Sprintf("%v.%v %v", clientTlsConfigField, caCertificateFileField, "is empty"))
 }
 
+func TestProxy_IsValid_RequestQueue(t *testing.T) {
+	tests := []struct {
+		name, proxyType string
+		requestQueue    *RequestQueue
+		wantErr         string
+	}{
+		{
+			name:         "invalid",
+			proxyType:    "http",
+			requestQueue: &RequestQueue{},
+			wantErr:      fmt.Sprintf("%v.%v must be > 0", requestQueueField, maxLengthField),
+		},
+		{
+			name:         "maxWait not less than writeTimeout",
+			proxyType:    "http",
+			requestQueue: &RequestQueue{MaxLength: 10, MaxWait: 15},
+			wantErr:      fmt.Sprintf("%v %v must be < %v", requestQueueField, maxWaitField, writeTimeoutField),
+		},
+		{
+			name:         "valid",
+			proxyType:    "http",
+			requestQueue: &RequestQueue{MaxLength: 10, MaxWait: 14},
+		},
+		{
+			name:         "ws not limited by writeTimeout",
+			proxyType:    "ws",
+			requestQueue: &RequestQueue{MaxLength: 10, MaxWait: 60},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProxy()
+			c.Type = tt.proxyType
+			c.RequestQueue = tt.requestQueue
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxy_IsValid_ActivityMethods(t *testing.T) {
+	c := minimumValidProxy()
+	c.ActivityMethods = &MethodFilter{}
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, fmt.Sprintf("%v %v and %v are empty", activityMethodsField, allowField, denyField))
+}
+
+func TestProxy_IsValid_WakeMethods(t *testing.T) {
+	c := minimumValidProxy()
+	c.WakeMethods = &MethodFilter{Deny: []string{"eth_["}}
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, fmt.Sprintf("%v.%v[0] is not a valid pattern", wakeMethodsField, denyField))
+}
+
+func TestProxy_IsValid_ResponseCache(t *testing.T) {
+	tests := []struct {
+		name, proxyType string
+		responseCache   *ResponseCache
+		wantErr         string
+	}{
+		{
+			name:          "invalid",
+			proxyType:     "http",
+			responseCache: &ResponseCache{},
+			wantErr:       fmt.Sprintf("%v.%v is empty", responseCacheField, methodsField),
+		},
+		{
+			name:          "ws",
+			proxyType:     "ws",
+			responseCache: &ResponseCache{Methods: []string{"eth_chainId"}, Size: 10, TTL: 60},
+			wantErr:       fmt.Sprintf("%v is only supported by http proxies", responseCacheField),
+		},
+		{
+			name:          "valid",
+			proxyType:     "http",
+			responseCache: &ResponseCache{Methods: []string{"eth_chainId"}, Size: 10, TTL: 60},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProxy()
+			c.Type = tt.proxyType
+			c.ResponseCache = tt.responseCache
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxy_IsValid_Auth(t *testing.T) {
+	tests := []struct {
+		name      string
+		auth      *ProxyAuth
+		serverTLS *ServerTLS
+		wantErr   string
+	}{
+		{
+			name:    "invalid",
+			auth:    &ProxyAuth{},
+			wantErr: fmt.Sprintf("%v %v, %v and %v are not set", authField, apiKeysField, jwksFileField, clientCertificateField),
+		},
+		{
+			name:    "client certificate without tls",
+			auth:    &ProxyAuth{ClientCert: true},
+			wantErr: fmt.Sprintf("%v %v requires %v.%v to be set", authField, clientCertificateField, proxyTlsConfigField, clientCaCertificateField),
+		},
+		{
+			name:      "client certificate without client ca",
+			auth:      &ProxyAuth{ClientCert: true},
+			serverTLS: &ServerTLS{CertFile: certFile, KeyFile: keyFile},
+			wantErr:   fmt.Sprintf("%v %v requires %v.%v to be set", authField, clientCertificateField, proxyTlsConfigField, clientCaCertificateField),
+		},
+		{
+			name:      "client certificate",
+			auth:      &ProxyAuth{ClientCert: true},
+			serverTLS: &ServerTLS{CertFile: certFile, KeyFile: keyFile, ClientCaCertFile: certFile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProxy()
+			c.Auth = tt.auth
+			c.ProxyServerTLSConfig = tt.serverTLS
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxy_IsValid_RateLimit(t *testing.T) {
+	c := minimumValidProxy()
+	c.RateLimit = &RateLimit{Wakes: &TokenBucket{Rate: 0.1}}
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, fmt.Sprintf("%v.%v.%v must be > 0", rateLimitField, wakesField, burstField))
+}
+
+func TestProxy_IsValid_MaxBodySize(t *testing.T) {
+	tests := []struct {
+		name, proxyType string
+		maxBodySize     int
+		wantErr         string
+	}{
+		{
+			name:        "not set",
+			proxyType:   "ws",
+			maxBodySize: 0,
+		},
+		{
+			name:        "http",
+			proxyType:   "http",
+			maxBodySize: 1024,
+		},
+		{
+			name:        "negative",
+			proxyType:   "http",
+			maxBodySize: -1,
+			wantErr:     fmt.Sprintf("%v must be >= 0", maxBodySizeField),
+		},
+		{
+			name:        "ws",
+			proxyType:   "ws",
+			maxBodySize: 1024,
+			wantErr:     fmt.Sprintf("%v is only supported by http proxies", maxBodySizeField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProxy()
+			c.Type = tt.proxyType
+			c.MaxBodySize = tt.maxBodySize
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxy_MaxBodySizeOrDefault(t *testing.T) {
+	c := minimumValidProxy()
+	require.Equal(t, int64(5*1024*1024), c.MaxBodySizeOrDefault())
+
+	c.MaxBodySize = 1024
+	require.Equal(t, int64(1024), c.MaxBodySizeOrDefault())
+}
+
+func TestProxy_IsValid_Upstreams(t *testing.T) {
+	tests := []struct {
+		name, proxyType string
+		upstreams       *Upstreams
+		wantErr         string
+	}{
+		{
+			name:      "invalid",
+			proxyType: "http",
+			upstreams: &Upstreams{},
+			wantErr:   fmt.Sprintf("%v.%v is empty", upstreamsField, addressesField),
+		},
+		{
+			name:      "ws",
+			proxyType: "ws",
+			upstreams: &Upstreams{Addresses: []string{"http://localhost:9091"}},
+			wantErr:   fmt.Sprintf("%v is only supported by http proxies", upstreamsField),
+		},
+		{
+			name:      "same as upstream address",
+			proxyType: "http",
+			upstreams: &Upstreams{Addresses: []string{"http://localhost:9091", "http://localhost:9090"}},
+			wantErr:   fmt.Sprintf("%v.%v[1] must not be %v", upstreamsField, addressesField, upstreamAddressField),
+		},
+		{
+			name:      "valid",
+			proxyType: "http",
+			upstreams: &Upstreams{Addresses: []string{"http://localhost:9091"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProxy()
+			c.Type = tt.proxyType
+			c.Upstreams = tt.upstreams
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxy_IsValid_TxHandler(t *testing.T) {
+	tests := []struct {
+		name, proxyType, txHandler string
+		wantErr                    string
+	}{
+		{
+			name:      "not set",
+			proxyType: "ws",
+			txHandler: "",
+		},
+		{
+			name:      "tessera",
+			proxyType: "http",
+			txHandler: "tessera",
+		},
+		{
+			name:      "invalid",
+			proxyType: "http",
+			txHandler: "besu",
+			wantErr:   fmt.Sprintf("%v must be tessera", txHandlerField),
+		},
+		{
+			name:      "tessera ws",
+			proxyType: "ws",
+			txHandler: "tessera",
+			wantErr:   fmt.Sprintf("%v tessera is only supported by http proxies", txHandlerField),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidProxy()
+			c.Type = tt.proxyType
+			c.TxHandler = tt.txHandler
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestProxy_IsHttp(t *testing.T) {
 	tests := []struct {
 		name, proxyType string
@@ -344,3 +783,30 @@ func TestProxy_IsWs(t *testing.T) {
 		})
 	}
 }
+
+func TestProxy_IsTCP(t *testing.T) {
+	tests := []struct {
+		name, proxyType string
+		want            bool
+	}{
+		{
+			name:      "not tcp",
+			proxyType: "http",
+			want:      false,
+		},
+		{
+			name:      "is tcp",
+			proxyType: "tcp",
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Proxy{
+				Type: tt.proxyType,
+			}
+			require.Equal(t, tt.want, c.IsTCP())
+		})
+	}
+}
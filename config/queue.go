@@ -0,0 +1,16 @@
+package config
+
+type RequestQueue struct {
+	MaxLength int `toml:"maxLength" json:"maxLength"` // maximum number of requests held while the node is being started
+	MaxWait   int `toml:"maxWait" json:"maxWait"`     // time in seconds a request is held before it is failed
+}
+
+func (c RequestQueue) IsValid() error {
+	if c.MaxLength <= 0 {
+		return newFieldErr("maxLength", isNotGreaterThanZeroErr)
+	}
+	if c.MaxWait <= 0 {
+		return newFieldErr("maxWait", isNotGreaterThanZeroErr)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidRequestQueue() RequestQueue {
+	return RequestQueue{
+		MaxLength: 100,
+		MaxWait:   10,
+	}
+}
+
+func TestRequestQueue_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": 100,
+	"%v": 10
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = 100
+%v = 10`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, maxLengthField, maxWaitField)
+
+			want := RequestQueue{
+				MaxLength: 100,
+				MaxWait:   10,
+			}
+
+			var (
+				got RequestQueue
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestRequestQueue_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidRequestQueue()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+}
+
+func TestRequestQueue_IsValid_MaxLength(t *testing.T) {
+	c := minimumValidRequestQueue()
+	c.MaxLength = 0
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, maxLengthField+" must be > 0")
+}
+
+func TestRequestQueue_IsValid_MaxWait(t *testing.T) {
+	c := minimumValidRequestQueue()
+	c.MaxWait = 0
+
+	err := c.IsValid()
+
+	require.IsType(t, &fieldErr{}, err)
+	require.EqualError(t, err, maxWaitField+" must be > 0")
+}
@@ -1,10 +1,17 @@
 package config
 
+import "errors"
+
 type RPCServer struct {
-	RPCAddr     string     `toml:"rpcAddress" json:"rpcAddress"`
-	RPCCorsList []string   `toml:"rpcCorsList" json:"rpcCorsList"`
-	RPCVHosts   []string   `toml:"rpcvHosts" json:"rpcvHosts"`
-	TLSConfig   *ServerTLS `toml:"tlsConfig" json:"tlsConfig"`
+	RPCAddr     string        `toml:"rpcAddress" json:"rpcAddress"`
+	RPCCorsList []string      `toml:"rpcCorsList" json:"rpcCorsList"`
+	RPCVHosts   []string      `toml:"rpcvHosts" json:"rpcvHosts"`
+	TLSConfig   *ServerTLS    `toml:"tlsConfig" json:"tlsConfig"`
+	ACL         []*RPCACLRule `toml:"acl" json:"acl"` // if set, callers are authenticated by their tls client certificate and may only call the methods allowed by a rule
+}
+
+func (c RPCServer) IsACLEnabled() bool {
+	return len(c.ACL) != 0
 }
 
 func (c RPCServer) IsValid() error {
@@ -17,5 +24,49 @@ func (c RPCServer) IsValid() error {
 			return newFieldErr("tlsConfig", err)
 		}
 	}
+
+	if c.IsACLEnabled() && (c.TLSConfig == nil || c.TLSConfig.ClientCaCertFile == "") {
+		return newFieldErr("acl", errors.New("requires tlsConfig.clientCaCertificateFile to be set"))
+	}
+	for i, r := range c.ACL {
+		if r == nil {
+			return newArrFieldErr("acl", i, isEmptyErr)
+		}
+		if err := r.IsValid(); err != nil {
+			return newArrFieldErr("acl", i, err)
+		}
+	}
+	return nil
+}
+
+// RPCACLRule allows the callers matching the rule to call methods of the RPC server
+type RPCACLRule struct {
+	Peers    []string `toml:"peers" json:"peers"`       // names of the peers the rule applies to. * applies to all peers
+	Subjects []string `toml:"subjects" json:"subjects"` // client certificate subject common names of callers which are not peers, e.g. operators, the rule applies to
+	Methods  []string `toml:"methods" json:"methods"`   // methods allowed, e.g. node.NodeStatus. * allows all methods
+}
+
+func (c RPCACLRule) IsValid() error {
+	if len(c.Peers) == 0 && len(c.Subjects) == 0 {
+		return errors.New("peers and subjects are not set")
+	}
+	for i, p := range c.Peers {
+		if p == "" {
+			return newArrFieldErr("peers", i, isEmptyErr)
+		}
+	}
+	for i, s := range c.Subjects {
+		if s == "" {
+			return newArrFieldErr("subjects", i, isEmptyErr)
+		}
+	}
+	if len(c.Methods) == 0 {
+		return newFieldErr("methods", isEmptyErr)
+	}
+	for i, m := range c.Methods {
+		if m == "" {
+			return newArrFieldErr("methods", i, isEmptyErr)
+		}
+	}
 	return nil
 }
@@ -28,7 +28,8 @@ func TestRPCServer_Unmarshal(t *testing.T) {
 	"%v": "http://url",
 	"%v": ["http://other"],
 	"%v": ["http://another"],
-	"%v": {}
+	"%v": {},
+	"%v": [{"%v": ["peer1"], "%v": ["admin"], "%v": ["node.NodeStatus"]}]
 }`,
 		},
 		{
@@ -37,19 +38,21 @@ func TestRPCServer_Unmarshal(t *testing.T) {
 %v = "http://url"
 %v = ["http://other"]
 %v = ["http://another"]
-%v = {}`,
+%v = {}
+%v = [{%v = ["peer1"], %v = ["admin"], %v = ["node.NodeStatus"]}]`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			conf := fmt.Sprintf(tt.configTemplate, rpcAddressField, rpcCorsListField, rpcvHostsField, tlsConfigField)
+			conf := fmt.Sprintf(tt.configTemplate, rpcAddressField, rpcCorsListField, rpcvHostsField, tlsConfigField, aclField, peersField, subjectsField, methodsField)
 
 			want := RPCServer{
 				RPCAddr:     "http://url",
 				RPCCorsList: []string{"http://other"},
 				RPCVHosts:   []string{"http://another"},
 				TLSConfig:   &ServerTLS{},
+				ACL:         []*RPCACLRule{{Peers: []string{"peer1"}, Subjects: []string{"admin"}, Methods: []string{"node.NodeStatus"}}},
 			}
 
 			var (
@@ -110,3 +113,65 @@ func TestRPCServer_IsValid_TLSConfig(t *testing.T) {
 	require.IsType(t, &fieldErr{}, err)
 	require.EqualError(t, err, fmt.Sprintf("%v.%v %v", tlsConfigField, certificateFileField, "is empty"))
 }
+
+func TestRPCServer_IsValid_ACL(t *testing.T) {
+	rule := RPCACLRule{Peers: []string{"*"}, Methods: []string{"node.NodeStatus"}}
+
+	tests := []struct {
+		name      string
+		tlsConfig *ServerTLS
+		acl       []*RPCACLRule
+		wantErr   string
+	}{
+		{
+			name:    "tlsConfig not set",
+			acl:     []*RPCACLRule{&rule},
+			wantErr: aclField + " requires tlsConfig.clientCaCertificateFile to be set",
+		},
+		{
+			name:      "clientCaCertificateFile not set",
+			tlsConfig: &ServerTLS{CertFile: "resources/cert.pem", KeyFile: "resources/key.pem"},
+			acl:       []*RPCACLRule{&rule},
+			wantErr:   aclField + " requires tlsConfig.clientCaCertificateFile to be set",
+		},
+		{
+			name:      "peers and subjects not set",
+			tlsConfig: &ServerTLS{CertFile: "resources/cert.pem", KeyFile: "resources/key.pem", ClientCaCertFile: "resources/cert.pem"},
+			acl:       []*RPCACLRule{&rule, {Methods: []string{"*"}}},
+			wantErr:   aclField + "[1] peers and subjects are not set",
+		},
+		{
+			name:      "empty subject",
+			tlsConfig: &ServerTLS{CertFile: "resources/cert.pem", KeyFile: "resources/key.pem", ClientCaCertFile: "resources/cert.pem"},
+			acl:       []*RPCACLRule{{Subjects: []string{""}, Methods: []string{"*"}}},
+			wantErr:   fmt.Sprintf("%v[0].%v[0] is empty", aclField, subjectsField),
+		},
+		{
+			name:      "methods not set",
+			tlsConfig: &ServerTLS{CertFile: "resources/cert.pem", KeyFile: "resources/key.pem", ClientCaCertFile: "resources/cert.pem"},
+			acl:       []*RPCACLRule{{Peers: []string{"peer1"}}},
+			wantErr:   fmt.Sprintf("%v[0].%v is empty", aclField, methodsField),
+		},
+		{
+			name:      "valid",
+			tlsConfig: &ServerTLS{CertFile: "resources/cert.pem", KeyFile: "resources/key.pem", ClientCaCertFile: "resources/cert.pem"},
+			acl:       []*RPCACLRule{&rule, {Subjects: []string{"admin"}, Methods: []string{"*"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidRPCServer()
+			c.TLSConfig = tt.tlsConfig
+			c.ACL = tt.acl
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
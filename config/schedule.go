@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+)
+
+const (
+	ScheduleAwake      = "awake"      // node must stay awake regardless of activity
+	ScheduleHibernate  = "hibernate"  // node should be hibernated as soon as consensus allows
+	ScheduleInactivity = "inactivity" // node is hibernated based on inactivityTime
+)
+
+// default inactivity time in seconds after which the node is hibernated in a hibernate window
+const defaultHibernateWindowInactivityTime = 60
+
+type Schedule struct {
+	Mode     string `toml:"mode" json:"mode"`         // awake, hibernate or inactivity
+	Cron     string `toml:"cron" json:"cron"`         // cron expression (minute hour day-of-month month day-of-week). the window is active during every minute matching the expression
+	Timezone string `toml:"timezone" json:"timezone"` // IANA timezone in which the cron expression is evaluated. defaults to UTC
+	// inactivity time in seconds after which the node is hibernated in a hibernate window.
+	// defaults to the lower of inactivityTime and 60
+	InactivityTime int `toml:"inactivityTime" json:"inactivityTime"`
+}
+
+func (c Schedule) IsAwake() bool {
+	return strings.ToLower(c.Mode) == ScheduleAwake
+}
+
+func (c Schedule) IsHibernate() bool {
+	return strings.ToLower(c.Mode) == ScheduleHibernate
+}
+
+func (c Schedule) IsInactivity() bool {
+	return strings.ToLower(c.Mode) == ScheduleInactivity
+}
+
+// InactivityTimeOrDefault returns the inactivity time in seconds after which the node is hibernated in a hibernate
+// window. If not set it is the lower of inactivityTime, the inactivity time outside windows, and 60.
+func (c Schedule) InactivityTimeOrDefault(inactivityTime int) int {
+	if c.InactivityTime > 0 {
+		return c.InactivityTime
+	}
+	if inactivityTime < defaultHibernateWindowInactivityTime {
+		return inactivityTime
+	}
+	return defaultHibernateWindowInactivityTime
+}
+
+// Location returns the timezone of the schedule
+func (c Schedule) Location() (*time.Location, error) {
+	return time.LoadLocation(c.Timezone)
+}
+
+func (c Schedule) IsValid() error {
+	if !c.IsAwake() && !c.IsHibernate() && !c.IsInactivity() {
+		return newFieldErr("mode", errors.New("must be awake, hibernate or inactivity"))
+	}
+	if c.Cron == "" {
+		return newFieldErr("cron", isEmptyErr)
+	}
+	if _, err := core.ParseCron(c.Cron); err != nil {
+		return newFieldErr("cron", err)
+	}
+	if _, err := c.Location(); err != nil {
+		return newFieldErr("timezone", err)
+	}
+	if c.InactivityTime < 0 {
+		return newFieldErr("inactivityTime", errors.New("must be >= 0"))
+	}
+	if c.InactivityTime != 0 && !c.IsHibernate() {
+		return newFieldErr("inactivityTime", errors.New("can only be set if mode is hibernate"))
+	}
+	return nil
+}
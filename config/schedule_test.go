@@ -0,0 +1,264 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidSchedule() Schedule {
+	return Schedule{
+		Mode: "awake",
+		Cron: "* 9-17 * * 1-5",
+	}
+}
+
+func TestSchedule_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": "hibernate",
+	"%v": "* 9-17 * * 1-5",
+	"%v": "Europe/London",
+	"%v": 30
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = "hibernate"
+%v = "* 9-17 * * 1-5"
+%v = "Europe/London"
+%v = 30`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, modeField, cronField, timezoneField, inactivityTimeField)
+
+			want := Schedule{
+				Mode:           "hibernate",
+				Cron:           "* 9-17 * * 1-5",
+				Timezone:       "Europe/London",
+				InactivityTime: 30,
+			}
+
+			var (
+				got Schedule
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestSchedule_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidSchedule()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+}
+
+func TestSchedule_IsValid_Mode(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		wantErrMsg string
+	}{
+		{
+			name:       "awake",
+			mode:       "awake",
+			wantErrMsg: "",
+		},
+		{
+			name:       "hibernate",
+			mode:       "hibernate",
+			wantErrMsg: "",
+		},
+		{
+			name:       "inactivity",
+			mode:       "inactivity",
+			wantErrMsg: "",
+		},
+		{
+			name:       "case insensitive",
+			mode:       "HiBeRnAtE",
+			wantErrMsg: "",
+		},
+		{
+			name:       "not set",
+			mode:       "",
+			wantErrMsg: modeField + " must be awake, hibernate or inactivity",
+		},
+		{
+			name:       "invalid",
+			mode:       "sleep",
+			wantErrMsg: modeField + " must be awake, hibernate or inactivity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidSchedule()
+			c.Mode = tt.mode
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestSchedule_IsValid_Cron(t *testing.T) {
+	tests := []struct {
+		name       string
+		cron       string
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			cron:       "",
+			wantErrMsg: cronField + " is empty",
+		},
+		{
+			name:       "invalid",
+			cron:       "* 25 * * *",
+			wantErrMsg: cronField + ` hour field value out of range 0-23: "25"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidSchedule()
+			c.Cron = tt.cron
+
+			err := c.IsValid()
+
+			require.IsType(t, &fieldErr{}, err)
+			require.EqualError(t, err, tt.wantErrMsg)
+		})
+	}
+}
+
+func TestSchedule_IsValid_Timezone(t *testing.T) {
+	tests := []struct {
+		name       string
+		timezone   string
+		wantErrMsg string
+	}{
+		{
+			name:       "not set",
+			timezone:   "",
+			wantErrMsg: "",
+		},
+		{
+			name:       "valid",
+			timezone:   "America/New_York",
+			wantErrMsg: "",
+		},
+		{
+			name:       "invalid",
+			timezone:   "Mars/Olympus_Mons",
+			wantErrMsg: timezoneField + " unknown time zone Mars/Olympus_Mons",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidSchedule()
+			c.Timezone = tt.timezone
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestSchedule_IsValid_InactivityTime(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           string
+		inactivityTime int
+		wantErrMsg     string
+	}{
+		{
+			name:           "not set",
+			mode:           "awake",
+			inactivityTime: 0,
+			wantErrMsg:     "",
+		},
+		{
+			name:           "hibernate",
+			mode:           "hibernate",
+			inactivityTime: 10,
+			wantErrMsg:     "",
+		},
+		{
+			name:           "negative",
+			mode:           "hibernate",
+			inactivityTime: -1,
+			wantErrMsg:     inactivityTimeField + " must be >= 0",
+		},
+		{
+			name:           "not hibernate",
+			mode:           "inactivity",
+			inactivityTime: 10,
+			wantErrMsg:     inactivityTimeField + " can only be set if mode is hibernate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidSchedule()
+			c.Mode = tt.mode
+			c.InactivityTime = tt.inactivityTime
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestSchedule_InactivityTimeOrDefault(t *testing.T) {
+	c := minimumValidSchedule()
+	c.Mode = "hibernate"
+
+	require.Equal(t, 60, c.InactivityTimeOrDefault(600))
+	require.Equal(t, 30, c.InactivityTimeOrDefault(30))
+
+	c.InactivityTime = 10
+	require.Equal(t, 10, c.InactivityTimeOrDefault(600))
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	defaultPeerFailureThreshold = 3
+	defaultPeerOpenTime         = 30
+)
+
+type UnreachablePeers struct {
+	Policy           string `toml:"policy" json:"policy"`                     // handling of peers which do not respond to the hibernation checks - all, down, quorum or ignoreAfter. defaults to all
+	IgnoreAfter      int    `toml:"ignoreAfter" json:"ignoreAfter"`           // time in seconds after which an unreachable peer is ignored by the ignoreAfter policy
+	FailureThreshold int    `toml:"failureThreshold" json:"failureThreshold"` // number of consecutive failed calls after which calls to a peer are skipped. defaults to 3
+	OpenTime         int    `toml:"openTime" json:"openTime"`                 // time in seconds for which calls to a peer are skipped before it is called again. defaults to 30
+}
+
+// IsAll returns true if all peers must respond to the hibernation checks
+func (c UnreachablePeers) IsAll() bool {
+	return c.Policy == "" || strings.ToLower(c.Policy) == "all"
+}
+
+// IsDown returns true if unreachable peers are treated as down
+func (c UnreachablePeers) IsDown() bool {
+	return strings.ToLower(c.Policy) == "down"
+}
+
+// IsQuorum returns true if a majority of the node hibernators must respond to the hibernation checks
+func (c UnreachablePeers) IsQuorum() bool {
+	return strings.ToLower(c.Policy) == "quorum"
+}
+
+// IsIgnoreAfter returns true if peers which have been unreachable for longer than IgnoreAfter are ignored
+func (c UnreachablePeers) IsIgnoreAfter() bool {
+	return strings.ToLower(c.Policy) == "ignoreafter"
+}
+
+// FailureThresholdOrDefault returns the number of consecutive failed calls after which calls to a peer are skipped
+func (c UnreachablePeers) FailureThresholdOrDefault() int {
+	if c.FailureThreshold == 0 {
+		return defaultPeerFailureThreshold
+	}
+	return c.FailureThreshold
+}
+
+// OpenTimeOrDefault returns the time in seconds for which calls to a peer are skipped
+func (c UnreachablePeers) OpenTimeOrDefault() int {
+	if c.OpenTime == 0 {
+		return defaultPeerOpenTime
+	}
+	return c.OpenTime
+}
+
+func (c UnreachablePeers) IsValid() error {
+	if !c.IsAll() && !c.IsDown() && !c.IsQuorum() && !c.IsIgnoreAfter() {
+		return newFieldErr("policy", errors.New("must be all, down, quorum or ignoreAfter"))
+	}
+	if c.IsIgnoreAfter() && c.IgnoreAfter <= 0 {
+		return newFieldErr("ignoreAfter", errors.New("must be > 0 as policy is ignoreAfter"))
+	}
+	if c.IgnoreAfter < 0 {
+		return newFieldErr("ignoreAfter", errors.New("must be >= 0"))
+	}
+	if c.FailureThreshold < 0 {
+		return newFieldErr("failureThreshold", errors.New("must be >= 0"))
+	}
+	if c.OpenTime < 0 {
+		return newFieldErr("openTime", errors.New("must be >= 0"))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnreachablePeers_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": "ignoreAfter",
+	"%v": 600,
+	"%v": 5,
+	"%v": 60
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = "ignoreAfter"
+%v = 600
+%v = 5
+%v = 60`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, policyField, ignoreAfterField, failureThresholdField, openTimeField)
+
+			want := UnreachablePeers{
+				Policy:           "ignoreAfter",
+				IgnoreAfter:      600,
+				FailureThreshold: 5,
+				OpenTime:         60,
+			}
+
+			var (
+				got UnreachablePeers
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestUnreachablePeers_IsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          UnreachablePeers
+		wantErrMsg string
+	}{
+		{
+			name: "defaults",
+			c:    UnreachablePeers{},
+		},
+		{
+			name: "all",
+			c:    UnreachablePeers{Policy: "all"},
+		},
+		{
+			name: "down",
+			c:    UnreachablePeers{Policy: "down"},
+		},
+		{
+			name: "quorum",
+			c:    UnreachablePeers{Policy: "quorum"},
+		},
+		{
+			name: "ignoreAfter",
+			c:    UnreachablePeers{Policy: "ignoreAfter", IgnoreAfter: 600},
+		},
+		{
+			name:       "unsupported policy",
+			c:          UnreachablePeers{Policy: "any"},
+			wantErrMsg: policyField + " must be all, down, quorum or ignoreAfter",
+		},
+		{
+			name:       "ignoreAfter not set",
+			c:          UnreachablePeers{Policy: "ignoreAfter"},
+			wantErrMsg: ignoreAfterField + " must be > 0 as policy is ignoreAfter",
+		},
+		{
+			name:       "negative ignoreAfter",
+			c:          UnreachablePeers{IgnoreAfter: -1},
+			wantErrMsg: ignoreAfterField + " must be >= 0",
+		},
+		{
+			name:       "negative failureThreshold",
+			c:          UnreachablePeers{FailureThreshold: -1},
+			wantErrMsg: failureThresholdField + " must be >= 0",
+		},
+		{
+			name:       "negative openTime",
+			c:          UnreachablePeers{OpenTime: -1},
+			wantErrMsg: openTimeField + " must be >= 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestUnreachablePeers_Defaults(t *testing.T) {
+	c := UnreachablePeers{}
+
+	require.True(t, c.IsAll())
+	require.Equal(t, 3, c.FailureThresholdOrDefault())
+	require.Equal(t, 30, c.OpenTimeOrDefault())
+
+	c.FailureThreshold = 5
+	c.OpenTime = 60
+
+	require.Equal(t, 5, c.FailureThresholdOrDefault())
+	require.Equal(t, 60, c.OpenTimeOrDefault())
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+type Upstreams struct {
+	Addresses           []string `toml:"addresses" json:"addresses"`                     // upstream addresses used in addition to the proxy's upstreamAddress, in priority order
+	Policy              string   `toml:"policy" json:"policy"`                           // selection of the upstream for each request - priority or roundRobin. defaults to priority
+	HealthCheck         *Upcheck `toml:"healthCheck" json:"healthCheck"`                 // health check of each upstream. url is resolved against each upstream address. upstreams are only checked by failed requests if not set
+	HealthCheckInterval int      `toml:"healthCheckInterval" json:"healthCheckInterval"` // time in seconds between health checks
+}
+
+func (c Upstreams) IsRoundRobin() bool {
+	return strings.ToLower(c.Policy) == "roundrobin"
+}
+
+func (c Upstreams) IsPriority() bool {
+	return c.Policy == "" || strings.ToLower(c.Policy) == "priority"
+}
+
+func (c Upstreams) IsValid() error {
+	if len(c.Addresses) == 0 {
+		return newFieldErr("addresses", isEmptyErr)
+	}
+	seen := make(map[string]bool)
+	for i, a := range c.Addresses {
+		if a == "" {
+			return newArrFieldErr("addresses", i, isEmptyErr)
+		}
+		u, err := url.Parse(a)
+		if err != nil {
+			return newArrFieldErr("addresses", i, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return newArrFieldErr("addresses", i, errors.New("must be a http or https url"))
+		}
+		if seen[a] {
+			return newArrFieldErr("addresses", i, isNotUniqueErr)
+		}
+		seen[a] = true
+	}
+	if !c.IsPriority() && !c.IsRoundRobin() {
+		return newFieldErr("policy", errors.New("must be priority or roundRobin"))
+	}
+	if c.HealthCheck != nil {
+		if err := c.HealthCheck.IsValid(); err != nil {
+			return newFieldErr("healthCheck", err)
+		}
+		if c.HealthCheckInterval <= 0 {
+			return newFieldErr("healthCheckInterval", isNotGreaterThanZeroErr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidUpstreams() Upstreams {
+	return Upstreams{
+		Addresses: []string{"http://localhost:9091"},
+	}
+}
+
+func TestUpstreams_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": ["http://localhost:9091", "http://localhost:9092"],
+	"%v": "roundRobin",
+	"%v": {},
+	"%v": 5
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = ["http://localhost:9091", "http://localhost:9092"]
+%v = "roundRobin"
+%v = {}
+%v = 5`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, addressesField, policyField, healthCheckField, healthCheckIntervalField)
+
+			want := Upstreams{
+				Addresses:           []string{"http://localhost:9091", "http://localhost:9092"},
+				Policy:              "roundRobin",
+				HealthCheck:         &Upcheck{},
+				HealthCheckInterval: 5,
+			}
+
+			var (
+				got Upstreams
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestUpstreams_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidUpstreams()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+	require.True(t, c.IsPriority())
+}
+
+func TestUpstreams_IsValid_Addresses(t *testing.T) {
+	tests := []struct {
+		name      string
+		addresses []string
+		wantErr   string
+	}{
+		{
+			name:      "not set",
+			addresses: nil,
+			wantErr:   addressesField + " is empty",
+		},
+		{
+			name:      "empty address",
+			addresses: []string{"http://localhost:9091", ""},
+			wantErr:   addressesField + "[1] is empty",
+		},
+		{
+			name:      "not http",
+			addresses: []string{"ws://localhost:9091"},
+			wantErr:   addressesField + "[0] must be a http or https url",
+		},
+		{
+			name:      "duplicate",
+			addresses: []string{"http://localhost:9091", "http://localhost:9091"},
+			wantErr:   addressesField + "[1] must be unique",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidUpstreams()
+			c.Addresses = tt.addresses
+
+			err := c.IsValid()
+
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUpstreams_IsValid_Policy(t *testing.T) {
+	tests := []struct {
+		name, policy, wantErr string
+	}{
+		{
+			name:   "priority",
+			policy: "priority",
+		},
+		{
+			name:   "round robin",
+			policy: "roundrobin",
+		},
+		{
+			name:    "invalid",
+			policy:  "random",
+			wantErr: policyField + " must be priority or roundRobin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidUpstreams()
+			c.Policy = tt.policy
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpstreams_IsValid_HealthCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		healthCheck *Upcheck
+		interval    int
+		wantErr     string
+	}{
+		{
+			name:        "invalid",
+			healthCheck: &Upcheck{},
+			interval:    5,
+			wantErr:     fmt.Sprintf("%v.%v is empty", healthCheckField, urlField),
+		},
+		{
+			name:        "interval not set",
+			healthCheck: &Upcheck{UpcheckUrl: "/", ReturnType: "rpcresult", Method: "POST"},
+			wantErr:     healthCheckIntervalField + " must be > 0",
+		},
+		{
+			name:        "valid",
+			healthCheck: &Upcheck{UpcheckUrl: "/", ReturnType: "rpcresult", Method: "POST"},
+			interval:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidUpstreams()
+			c.HealthCheck = tt.healthCheck
+			c.HealthCheckInterval = tt.interval
+
+			err := c.IsValid()
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import "errors"
+
+type Watchdog struct {
+	Deadline     int `toml:"deadline" json:"deadline"`         // time in seconds after which a ShutdownInprogress/StartupInprogress status is considered stuck
+	RetryLimit   int `toml:"retryLimit" json:"retryLimit"`     // number of times the failed stop/start is retried before reconciling the status
+	RetryBackoff int `toml:"retryBackoff" json:"retryBackoff"` // time in seconds to wait before the first retry. it is doubled for every subsequent retry
+}
+
+func (c Watchdog) IsValid() error {
+	if c.Deadline <= 0 {
+		return newFieldErr("deadline", isNotGreaterThanZeroErr)
+	}
+	if c.RetryLimit < 0 {
+		return newFieldErr("retryLimit", errors.New("must be >= 0"))
+	}
+	if c.RetryLimit > 0 && c.RetryBackoff <= 0 {
+		return newFieldErr("retryBackoff", errors.New("must be > 0 as retryLimit is set"))
+	}
+	return nil
+}
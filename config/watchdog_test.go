@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func minimumValidWatchdog() Watchdog {
+	return Watchdog{
+		Deadline:     120,
+		RetryLimit:   0,
+		RetryBackoff: 0,
+	}
+}
+
+func TestWatchdog_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name, configTemplate string
+	}{
+		{
+			name: "json",
+			configTemplate: `
+{
+	"%v": 120,
+	"%v": 3,
+	"%v": 5
+}`,
+		},
+		{
+			name: "toml",
+			configTemplate: `
+%v = 120
+%v = 3
+%v = 5`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := fmt.Sprintf(tt.configTemplate, deadlineField, retryLimitField, retryBackoffField)
+
+			want := Watchdog{
+				Deadline:     120,
+				RetryLimit:   3,
+				RetryBackoff: 5,
+			}
+
+			var (
+				got Watchdog
+				err error
+			)
+
+			if tt.name == "json" {
+				err = json.Unmarshal([]byte(conf), &got)
+			} else if tt.name == "toml" {
+				err = toml.Unmarshal([]byte(conf), &got)
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestWatchdog_IsValid_MinimumValid(t *testing.T) {
+	c := minimumValidWatchdog()
+
+	err := c.IsValid()
+
+	require.NoError(t, err)
+}
+
+func TestWatchdog_IsValid_Deadline(t *testing.T) {
+	tests := []struct {
+		name       string
+		deadline   int
+		wantErrMsg string
+	}{
+		{
+			name:       "zero",
+			deadline:   0,
+			wantErrMsg: deadlineField + " must be > 0",
+		},
+		{
+			name:       "negative",
+			deadline:   -1,
+			wantErrMsg: deadlineField + " must be > 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidWatchdog()
+			c.Deadline = tt.deadline
+
+			err := c.IsValid()
+
+			require.IsType(t, &fieldErr{}, err)
+			require.EqualError(t, err, tt.wantErrMsg)
+		})
+	}
+}
+
+func TestWatchdog_IsValid_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryLimit   int
+		retryBackoff int
+		wantErrMsg   string
+	}{
+		{
+			name:       "negative retryLimit",
+			retryLimit: -1,
+			wantErrMsg: retryLimitField + " must be >= 0",
+		},
+		{
+			name:         "retryLimit without retryBackoff",
+			retryLimit:   3,
+			retryBackoff: 0,
+			wantErrMsg:   retryBackoffField + " must be > 0 as retryLimit is set",
+		},
+		{
+			name:         "retryLimit with retryBackoff",
+			retryLimit:   3,
+			retryBackoff: 5,
+			wantErrMsg:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := minimumValidWatchdog()
+			c.RetryLimit = tt.retryLimit
+			c.RetryBackoff = tt.retryBackoff
+
+			err := c.IsValid()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.IsType(t, &fieldErr{}, err)
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
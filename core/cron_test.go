@@ -0,0 +1,142 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []struct {
+		name, expr, wantErrMsg string
+	}{
+		{
+			name:       "tooFewFields",
+			expr:       "* * * *",
+			wantErrMsg: "cron expression must have 5 fields: minute hour day-of-month month day-of-week",
+		},
+		{
+			name:       "notANumber",
+			expr:       "* x * * *",
+			wantErrMsg: `invalid value in hour field: "x"`,
+		},
+		{
+			name:       "outOfRange",
+			expr:       "* * 0 * *",
+			wantErrMsg: `day-of-month field value out of range 1-31: "0"`,
+		},
+		{
+			name:       "reversedRange",
+			expr:       "* 17-9 * * *",
+			wantErrMsg: `hour field value out of range 0-23: "17-9"`,
+		},
+		{
+			name:       "invalidStep",
+			expr:       "*/0 * * * *",
+			wantErrMsg: `invalid step in minute field: "*/0"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+
+			require.EqualError(t, err, tt.wantErrMsg)
+		})
+	}
+}
+
+func TestCronSpec_Matches(t *testing.T) {
+	// Monday
+	monday := time.Date(2021, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		time time.Time
+		want bool
+	}{
+		{
+			name: "everyMinute",
+			expr: "* * * * *",
+			time: monday,
+			want: true,
+		},
+		{
+			name: "businessHours",
+			expr: "* 9-17 * * 1-5",
+			time: monday,
+			want: true,
+		},
+		{
+			name: "businessHoursEndOfLastHour",
+			expr: "* 9-17 * * 1-5",
+			time: time.Date(2021, 3, 1, 17, 59, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "outsideBusinessHours",
+			expr: "* 9-17 * * 1-5",
+			time: time.Date(2021, 3, 1, 18, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "weekend",
+			expr: "* 9-17 * * 1-5",
+			time: time.Date(2021, 3, 6, 10, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "sundayAsSeven",
+			expr: "* * * * 7",
+			time: time.Date(2021, 3, 7, 10, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "list",
+			expr: "0,30 * * * *",
+			time: monday,
+			want: true,
+		},
+		{
+			name: "step",
+			expr: "*/20 * * * *",
+			time: monday,
+			want: false,
+		},
+		{
+			name: "rangeWithStep",
+			expr: "10-40/10 * * * *",
+			time: monday,
+			want: true,
+		},
+		{
+			name: "month",
+			expr: "* * * 4-12 *",
+			time: monday,
+			want: false,
+		},
+		{
+			name: "dayOfMonthOrDayOfWeek",
+			expr: "* * 15 * 1",
+			time: monday,
+			want: true,
+		},
+		{
+			name: "dayOfMonthAndWildcardDayOfWeek",
+			expr: "* * 15 * *",
+			time: monday,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.want, c.Matches(tt.time))
+		})
+	}
+}
@@ -2,4 +2,8 @@ package core
 
 import "errors"
 
-var ErrNodeDown = errors.New("node is not up")
+var (
+	ErrNodeDown          = errors.New("node is not up")
+	ErrNodeBeingShutdown = errors.New(NodeIsBeingShutdown)
+	ErrNodeBeingStarted  = errors.New(NodeIsBeingStarted)
+)
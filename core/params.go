@@ -15,4 +15,5 @@ const (
 	NodeIsBeingStarted            = "node is being started, try after sometime"
 	SomeParticipantsDown          = "Some participant nodes are down"
 	NodeIsNotReadyToAcceptRequest = "node is not ready to accept request"
+	NodeIsHibernated              = "node is hibernated and the request is not allowed to wake it"
 )
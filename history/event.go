@@ -0,0 +1,56 @@
+package history
+
+import "time"
+
+// EventType is the type of a lifecycle event of the node
+type EventType string
+
+const (
+	Hibernated             EventType = "hibernated"             // blockchain client and privacy manager were stopped
+	HibernationRefused     EventType = "hibernationRefused"     // hibernation was refused by consensus/peer validation or because node hibernator was busy
+	HibernationFailed      EventType = "hibernationFailed"      // stopping blockchain client or privacy manager failed
+	Woken                  EventType = "woken"                  // blockchain client and privacy manager were started
+	WakeRefused            EventType = "wakeRefused"            // waking was refused as node hibernator was busy or node was already up
+	WakeFailed             EventType = "wakeFailed"             // starting blockchain client or privacy manager failed
+	InactivityLimitReached EventType = "inactivityLimitReached" // node has been inactive for the configured inactivity time
+	ResyncDue              EventType = "resyncDue"              // resync timer expired
+	StatusReset            EventType = "statusReset"            // node status was reset by the watchdog or an admin
+	PeersPreparedForPvtTx  EventType = "peersPreparedForPvtTx"  // participant peers were asked to prepare for a private transaction
+	PeerUnreachable        EventType = "peerUnreachable"        // rpc call to a peer failed
+)
+
+// Trigger is what caused an event
+type Trigger string
+
+const (
+	TriggerInactivity    Trigger = "inactivity"    // inactivity time limit
+	TriggerResyncTimer   Trigger = "resyncTimer"   // resync timer
+	TriggerProxyRequest  Trigger = "proxyRequest"  // client request received by a proxy
+	TriggerPeerPrivateTx Trigger = "peerPrivateTx" // PrepareForPrivateTx request from a peer
+	TriggerAdmin         Trigger = "admin"         // admin rpc request
+	TriggerWatchdog      Trigger = "watchdog"      // watchdog recovering a stuck status
+	TriggerSchedule      Trigger = "schedule"      // awake/hibernate schedule window
+)
+
+// Event is a lifecycle event of the node
+type Event struct {
+	Time     time.Time `json:"time"`               // time at which the event happened
+	Type     EventType `json:"type"`               // type of event
+	Trigger  Trigger   `json:"trigger,omitempty"`  // what caused the event
+	Duration int64     `json:"duration,omitempty"` // duration of the action in milliseconds
+	Reason   string    `json:"reason,omitempty"`   // reason code if an action was refused
+	Message  string    `json:"message,omitempty"`  // details of the event
+	Peers    []string  `json:"peers,omitempty"`    // peers involved in the event
+}
+
+func (e Event) isAnyType(types []EventType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if e.Type == t {
+			return true
+		}
+	}
+	return false
+}
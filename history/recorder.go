@@ -0,0 +1,140 @@
+package history
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+// DefaultSize is the number of events kept if size is not configured
+const DefaultSize = 1000
+
+// Recorder keeps the most recent lifecycle events of the node in a ring buffer.
+// If a file is given, the events are persisted to it after every change and reloaded on creation.
+// A nil Recorder is valid and discards all events.
+type Recorder struct {
+	events []Event // ring buffer of events
+	next   int     // index in events to write the next event to
+	full   bool    // indicates if the ring buffer has wrapped around
+	file   string  // file to persist the events to. empty if events are not persisted
+	mux    sync.Mutex
+}
+
+// NewRecorder returns a Recorder that keeps up to size events.
+// If file is not empty, events are persisted to it and any events persisted earlier are loaded.
+func NewRecorder(size int, file string) *Recorder {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	r := &Recorder{
+		events: make([]Event, 0, size),
+		file:   file,
+	}
+	if file != "" {
+		r.load()
+	}
+	return r
+}
+
+// Record adds the event to the history. The event time is set to now if it is not set.
+func (r *Recorder) Record(e Event) {
+	if r == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	log.Debug("Record - history event", "event", e)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.add(e)
+	r.persist()
+}
+
+// Events returns the recorded events in chronological order that happened in the time range [since, until]
+// and match any of the given types.
+// A zero since or until means the range is unbounded on that side. Empty types matches all types.
+func (r *Recorder) Events(since, until time.Time, types []EventType) []Event {
+	if r == nil {
+		return nil
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	var res []Event
+	for _, e := range r.ordered() {
+		if !since.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		if !until.IsZero() && e.Time.After(until) {
+			continue
+		}
+		if !e.isAnyType(types) {
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+func (r *Recorder) add(e Event) {
+	if !r.full && len(r.events) < cap(r.events) {
+		r.events = append(r.events, e)
+	} else {
+		r.events[r.next] = e
+		r.full = true
+	}
+	r.next = (r.next + 1) % cap(r.events)
+}
+
+// ordered returns events from oldest to newest
+func (r *Recorder) ordered() []Event {
+	if !r.full {
+		return r.events
+	}
+	return append(append([]Event{}, r.events[r.next:]...), r.events[:r.next]...)
+}
+
+func (r *Recorder) load() {
+	data, err := ioutil.ReadFile(r.file)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.Error("load - unable to read history file", "file", r.file, "err", err)
+		return
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		log.Error("load - unable to decode history file", "file", r.file, "err", err)
+		return
+	}
+	for _, e := range events {
+		r.add(e)
+	}
+	log.Info("load - loaded history", "file", r.file, "events", len(events))
+}
+
+// persist writes the events to a temporary file and renames it, so that a crash
+// while writing does not leave a corrupted history file behind
+func (r *Recorder) persist() {
+	if r.file == "" {
+		return
+	}
+	data, err := json.Marshal(r.ordered())
+	if err != nil {
+		log.Error("persist - unable to encode history", "err", err)
+		return
+	}
+	tmp := r.file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		log.Error("persist - unable to write history file", "file", tmp, "err", err)
+		return
+	}
+	if err := os.Rename(tmp, r.file); err != nil {
+		log.Error("persist - unable to rename history file", "file", r.file, "err", err)
+	}
+}
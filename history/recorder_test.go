@@ -0,0 +1,123 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Record_KeepsMostRecentEvents(t *testing.T) {
+	r := NewRecorder(3, "")
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		r.Record(Event{Time: start.Add(time.Duration(i) * time.Second), Type: Woken, Duration: int64(i)})
+	}
+
+	got := r.Events(time.Time{}, time.Time{}, nil)
+
+	require.Len(t, got, 3)
+	for i, e := range got {
+		require.Equal(t, int64(i+2), e.Duration)
+	}
+}
+
+func TestRecorder_Record_SetsTime(t *testing.T) {
+	r := NewRecorder(3, "")
+
+	r.Record(Event{Type: Woken})
+
+	got := r.Events(time.Time{}, time.Time{}, nil)
+	require.Len(t, got, 1)
+	require.False(t, got[0].Time.IsZero())
+}
+
+func TestRecorder_Events_Filters(t *testing.T) {
+	r := NewRecorder(10, "")
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.Record(Event{Time: start, Type: Hibernated})
+	r.Record(Event{Time: start.Add(time.Minute), Type: HibernationRefused})
+	r.Record(Event{Time: start.Add(2 * time.Minute), Type: Woken})
+	r.Record(Event{Time: start.Add(3 * time.Minute), Type: Hibernated})
+
+	tests := []struct {
+		name         string
+		since, until time.Time
+		types        []EventType
+		want         []EventType
+	}{
+		{
+			name: "noFilters",
+			want: []EventType{Hibernated, HibernationRefused, Woken, Hibernated},
+		},
+		{
+			name:  "since",
+			since: start.Add(time.Minute),
+			want:  []EventType{HibernationRefused, Woken, Hibernated},
+		},
+		{
+			name:  "until",
+			until: start.Add(time.Minute),
+			want:  []EventType{Hibernated, HibernationRefused},
+		},
+		{
+			name:  "types",
+			types: []EventType{Hibernated, Woken},
+			want:  []EventType{Hibernated, Woken, Hibernated},
+		},
+		{
+			name:  "timeRangeAndTypes",
+			since: start.Add(time.Second),
+			until: start.Add(3 * time.Minute),
+			types: []EventType{Hibernated},
+			want:  []EventType{Hibernated},
+		},
+		{
+			name:  "noMatch",
+			types: []EventType{WakeFailed},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []EventType
+			for _, e := range r.Events(tt.since, tt.until, tt.types) {
+				got = append(got, e.Type)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRecorder_Persist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history.json")
+
+	r := NewRecorder(2, file)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.Record(Event{Time: start, Type: Hibernated, Trigger: TriggerInactivity})
+	r.Record(Event{Time: start.Add(time.Minute), Type: Woken, Trigger: TriggerProxyRequest, Duration: 2000})
+	r.Record(Event{Time: start.Add(2 * time.Minute), Type: HibernationRefused, Reason: "peers", Peers: []string{"node2"}})
+
+	got := NewRecorder(2, file).Events(time.Time{}, time.Time{}, nil)
+
+	require.Equal(t, r.Events(time.Time{}, time.Time{}, nil), got)
+	require.Len(t, got, 2)
+	require.Equal(t, Woken, got[0].Type)
+	require.Equal(t, HibernationRefused, got[1].Type)
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+
+	r.Record(Event{Type: Woken})
+
+	require.Nil(t, r.Events(time.Time{}, time.Time{}, nil))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ConsenSys/quorum-hibernate/config"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 	"github.com/ConsenSys/quorum-hibernate/node"
 	"github.com/ConsenSys/quorum-hibernate/proxy"
 	"github.com/ConsenSys/quorum-hibernate/rpc"
@@ -18,6 +19,7 @@ type NodeHibernatorApp struct {
 	node         *node.NodeControl
 	proxyServers []proxy.Proxy
 	rpcService   *rpc.RPCService
+	metricsSrv   *metrics.Server
 }
 
 var nhApp = NodeHibernatorApp{}
@@ -46,12 +48,18 @@ func main() {
 }
 
 func Start(nodeConfig *config.Node, err error, proxyBackendErrCh chan error, rpcBackendErrCh chan error) bool {
-	nhApp.node = node.NewNodeControl(nodeConfig)
+	if nhApp.node, err = node.NewNodeControl(nodeConfig); err != nil {
+		log.Error("Start - creating node control failed", "err", err)
+		return false
+	}
 	if nhApp.proxyServers, err = proxy.MakeProxyServices(nhApp.node, proxyBackendErrCh); err != nil {
 		log.Error("Start - creating proxies failed", "err", err)
 		return false
 	}
 	nhApp.rpcService = rpc.NewRPCService(nhApp.node, nhApp.node.GetRPCConfig(), rpcBackendErrCh)
+	if m := nodeConfig.BasicConfig.Metrics; m != nil && m.IsSeparateServer() {
+		nhApp.metricsSrv = metrics.NewServer(m.ListenAddress, rpcBackendErrCh)
+	}
 
 	// start node service
 	nhApp.node.Start()
@@ -66,6 +74,11 @@ func Start(nodeConfig *config.Node, err error, proxyBackendErrCh chan error, rpc
 		log.Info("Start - rpc server failed", "err", err)
 		return false
 	}
+
+	// start metrics server
+	if nhApp.metricsSrv != nil {
+		nhApp.metricsSrv.Start()
+	}
 	return true
 }
 
@@ -109,20 +122,23 @@ func readNodeConfigFromFile(configFile string) (*config.Node, error) {
 		return nil, err
 	}
 
-	log.Debug("readNodeConfigFromFile - loading peers config file")
-	peersReader, err := config.NewPeersReader(nhConfig.PeersConfigFile)
-	if err != nil {
-		return nil, err
-	}
+	var peersConfig config.PeerArr
+	if nhConfig.Discovery == nil {
+		log.Debug("readNodeConfigFromFile - loading peers config file")
+		peersReader, err := config.NewPeersReader(nhConfig.PeersConfigFile)
+		if err != nil {
+			return nil, err
+		}
 
-	peersConfig, err := peersReader.Read()
-	if err != nil {
-		return nil, err
-	}
-	log.Debug("readNodeConfigFromFile - validating peers config file")
+		peersConfig, err = peersReader.Read()
+		if err != nil {
+			return nil, err
+		}
+		log.Debug("readNodeConfigFromFile - validating peers config file")
 
-	if err := peersConfig.IsValid(); err != nil {
-		return nil, err
+		if err := peersConfig.IsValid(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &config.Node{
@@ -136,6 +152,9 @@ func Shutdown() {
 		p.Stop()
 	}
 	nhApp.rpcService.Stop()
+	if nhApp.metricsSrv != nil {
+		nhApp.metricsSrv.Stop()
+	}
 	nhApp.node.Stop()
 	log.ErrWriter.Close()
 }
@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "nodehibernator"
+
+var nodeStatusNames = map[core.NodeStatus]string{
+	core.ShutdownInprogress: "shutdownInprogress",
+	core.StartupInprogress:  "startupInprogress",
+	core.ConsensusWait:      "consensusWait",
+	core.OK:                 "ok",
+}
+
+var (
+	nodeStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_status",
+		Help:      "Status of node hibernator. 1 for the current status, 0 for all other statuses",
+	}, []string{"status"})
+
+	clientUp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "client_up",
+		Help:      "Combined status of blockchain client and privacy manager. 1 if up, 0 if down",
+	})
+
+	inactivitySeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inactivity_seconds",
+		Help:      "Number of seconds the node has been inactive",
+	})
+
+	hibernations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hibernations_total",
+		Help:      "Number of hibernations by trigger and result",
+	}, []string{"trigger", "result"})
+
+	wakes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "wakes_total",
+		Help:      "Number of wakes by trigger and result",
+	}, []string{"trigger", "result"})
+
+	refusals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refusals_total",
+		Help:      "Number of refused hibernate/wake requests by action and reason",
+	}, []string{"action", "reason"})
+
+	processDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "process_action_duration_seconds",
+		Help:      "Time taken to start/stop blockchain client and privacy manager processes",
+		Buckets:   []float64{1, 2, 5, 10, 15, 20, 30, 45, 60, 90, 120},
+	}, []string{"process", "action", "result"})
+
+	processExits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "process_unexpected_exits_total",
+		Help:      "Number of times a process spawned by node hibernator exited without being stopped by node hibernator",
+	}, []string{"process"})
+
+	proxyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxy_requests_total",
+		Help:      "Number of requests received by each proxy. For websocket proxies every message is counted",
+	}, []string{"proxy"})
+
+	proxyCacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxy_cache_hits_total",
+		Help:      "Number of requests answered from the response cache of each proxy while the node is down",
+	}, []string{"proxy"})
+
+	proxyTCPConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "proxy_tcp_connections",
+		Help:      "Number of open connections of each tcp proxy by state. A connection is idle if no data has been transferred recently",
+	}, []string{"proxy", "state"})
+
+	proxyUpstreamHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "proxy_upstream_healthy",
+		Help:      "Whether each upstream of a http proxy with multiple upstreams is healthy (1) or not (0)",
+	}, []string{"proxy", "upstream"})
+
+	peerRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "peer_rpc_duration_seconds",
+		Help:      "Latency of rpc calls to peer node hibernators",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"peer", "method"})
+
+	peerRPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "peer_rpc_errors_total",
+		Help:      "Number of failed rpc calls to peer node hibernators",
+	}, []string{"peer", "method"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		nodeStatus,
+		clientUp,
+		inactivitySeconds,
+		hibernations,
+		wakes,
+		refusals,
+		processDuration,
+		processExits,
+		proxyRequests,
+		proxyCacheHits,
+		proxyTCPConnections,
+		proxyUpstreamHealthy,
+		peerRPCDuration,
+		peerRPCErrors,
+	)
+}
+
+// Handler returns the http handler serving the metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func SetNodeStatus(s core.NodeStatus) {
+	for status, name := range nodeStatusNames {
+		if status == s {
+			nodeStatus.WithLabelValues(name).Set(1)
+		} else {
+			nodeStatus.WithLabelValues(name).Set(0)
+		}
+	}
+}
+
+func SetClientStatus(s core.ClientStatus) {
+	if s == core.Up {
+		clientUp.Set(1)
+	} else {
+		clientUp.Set(0)
+	}
+}
+
+func SetInactivitySeconds(s int) {
+	inactivitySeconds.Set(float64(s))
+}
+
+func IncHibernations(trigger string, success bool) {
+	hibernations.WithLabelValues(trigger, result(success)).Inc()
+}
+
+func IncWakes(trigger string, success bool) {
+	wakes.WithLabelValues(trigger, result(success)).Inc()
+}
+
+// IncRefusals increments the refusals of action (hibernate or wake) for the given reason code
+func IncRefusals(action, reason string) {
+	refusals.WithLabelValues(action, reason).Inc()
+}
+
+// ObserveProcessAction records the duration of a process action (start or stop) that began at start
+func ObserveProcessAction(process, action string, start time.Time, success bool) {
+	processDuration.WithLabelValues(process, action, result(success)).Observe(time.Since(start).Seconds())
+}
+
+func IncProcessUnexpectedExits(process string) {
+	processExits.WithLabelValues(process).Inc()
+}
+
+func IncProxyRequests(proxy string) {
+	proxyRequests.WithLabelValues(proxy).Inc()
+}
+
+// IncProxyCacheHits counts a request answered from the response cache of the proxy
+func IncProxyCacheHits(proxy string) {
+	proxyCacheHits.WithLabelValues(proxy).Inc()
+}
+
+// SetProxyTCPConnections sets the number of active and idle connections of the tcp proxy
+func SetProxyTCPConnections(proxy string, active, idle int) {
+	proxyTCPConnections.WithLabelValues(proxy, "active").Set(float64(active))
+	proxyTCPConnections.WithLabelValues(proxy, "idle").Set(float64(idle))
+}
+
+// SetProxyUpstreamHealthy sets whether the upstream of the http proxy is healthy
+func SetProxyUpstreamHealthy(proxy, upstream string, healthy bool) {
+	v := 0.0
+	if healthy {
+		v = 1
+	}
+	proxyUpstreamHealthy.WithLabelValues(proxy, upstream).Set(v)
+}
+
+// ObservePeerRPC records the latency of a rpc call to a peer that began at start, and counts it if it failed
+func ObservePeerRPC(peer, method string, start time.Time, err error) {
+	peerRPCDuration.WithLabelValues(peer, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		peerRPCErrors.WithLabelValues(peer, method).Inc()
+	}
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetNodeStatus(t *testing.T) {
+	SetNodeStatus(core.ShutdownInprogress)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(nodeStatus.WithLabelValues("shutdownInprogress")))
+	require.Equal(t, float64(0), testutil.ToFloat64(nodeStatus.WithLabelValues("ok")))
+
+	SetNodeStatus(core.OK)
+
+	require.Equal(t, float64(0), testutil.ToFloat64(nodeStatus.WithLabelValues("shutdownInprogress")))
+	require.Equal(t, float64(1), testutil.ToFloat64(nodeStatus.WithLabelValues("ok")))
+}
+
+func TestSetClientStatus(t *testing.T) {
+	SetClientStatus(core.Up)
+	require.Equal(t, float64(1), testutil.ToFloat64(clientUp))
+
+	SetClientStatus(core.Down)
+	require.Equal(t, float64(0), testutil.ToFloat64(clientUp))
+}
+
+func TestObservePeerRPC_CountsErrors(t *testing.T) {
+	ObservePeerRPC("peer1", "node.NodeStatus", time.Now(), nil)
+	ObservePeerRPC("peer1", "node.NodeStatus", time.Now(), errors.New("unreachable"))
+
+	require.Equal(t, float64(1), testutil.ToFloat64(peerRPCErrors.WithLabelValues("peer1", "node.NodeStatus")))
+}
+
+func TestHandler(t *testing.T) {
+	IncRefusals("hibernate", "peers")
+	IncProxyRequests("proxy1")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `nodehibernator_refusals_total{action="hibernate",reason="peers"} 1`)
+	require.Contains(t, string(body), `nodehibernator_proxy_requests_total{proxy="proxy1"} 1`)
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	golog "log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+const Path = "/metrics"
+
+// Server serves the metrics on a separate listen address from the RPC server
+type Server struct {
+	httpAddress string
+	httpServer  *http.Server
+	errCh       chan error
+	shutdownWg  sync.WaitGroup
+}
+
+func NewServer(httpAddress string, backendErrorChan chan error) *Server {
+	return &Server{
+		httpAddress: httpAddress,
+		errCh:       backendErrorChan,
+	}
+}
+
+func (s *Server) Start() {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	s.httpServer = &http.Server{
+		Addr:         s.httpAddress,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		ErrorLog:     golog.New(log.ErrWriter, "", 0),
+	}
+
+	s.shutdownWg.Add(1)
+	go func() {
+		defer s.shutdownWg.Done()
+		log.Info("Started metrics server", "Addr", s.httpAddress)
+		if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Error("Unable to start metrics server", "err", err)
+			s.errCh <- err
+		}
+	}()
+}
+
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			log.Error("metrics server shutdown failed", "err", err)
+		}
+		s.shutdownWg.Wait()
+	}
+	log.Info("metrics server stopped")
+}
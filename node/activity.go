@@ -1,9 +1,13 @@
 package node
 
 import (
+	"sync"
 	"time"
 
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 )
 
 // InactivityResyncMonitor tracks inactivity of the node and starts the blcokchain client and privacy manager based on resync timer
@@ -13,11 +17,20 @@ import (
 type InactivityResyncMonitor struct {
 	nodeCtrl          *NodeControl
 	inactiveTimeCount int
+	resyncTimerStart  time.Time        // time at which the resync timer was last reset. zero if the timer has expired
+	windows           []scheduleWindow // schedule windows overriding the inactivity rule
+	scheduleMode      string           // mode of the schedule window active at the last tick
 	stopCh            chan bool
+	mux               sync.Mutex // lock for inactiveTimeCount and resyncTimerStart
 }
 
 func NewInactivityResyncMonitor(qn *NodeControl) *InactivityResyncMonitor {
-	return &InactivityResyncMonitor{qn, 0, make(chan bool)}
+	return &InactivityResyncMonitor{
+		nodeCtrl:     qn,
+		windows:      newScheduleWindows(qn.config.BasicConfig.Schedules, qn.config.BasicConfig.InactivityTime),
+		scheduleMode: config.ScheduleInactivity,
+		stopCh:       make(chan bool),
+	}
 }
 
 func (nh *InactivityResyncMonitor) Start() {
@@ -26,20 +39,16 @@ func (nh *InactivityResyncMonitor) Start() {
 }
 
 // trackInactivity tracks node's inactivity time in seconds.
-// when inactive time exceeds limit(as per config) it requests the node to be shutdown
+// when inactive time exceeds limit(as per config) it requests the node to be shutdown.
+// schedule windows are evaluated every second and override the inactivity limit while active.
 func (nh *InactivityResyncMonitor) trackInactivity() {
 	timer := time.NewTicker(time.Second)
 	defer timer.Stop()
-	log.Info("trackInactivity - node inactivity tracker started", "inactivityTime", nh.nodeCtrl.config.BasicConfig.InactivityTime)
+	log.Info("trackInactivity - node inactivity tracker started", "inactivityTime", nh.nodeCtrl.config.BasicConfig.InactivityTime, "scheduleWindows", len(nh.windows))
 	for {
 		select {
-		case <-timer.C:
-			if nh.inactiveTimeCount == nh.nodeCtrl.config.BasicConfig.InactivityTime {
-				nh.processInactivity()
-			} else {
-				log.Trace("trackInactivity - inactivity ticking", "inactive seconds", nh.inactiveTimeCount)
-				nh.inactiveTimeCount++
-			}
+		case now := <-timer.C:
+			nh.processTick(now)
 		case <-nh.nodeCtrl.inactivityResetCh:
 			nh.ResetInactivity()
 		case <-nh.stopCh:
@@ -49,6 +58,55 @@ func (nh *InactivityResyncMonitor) trackInactivity() {
 	}
 }
 
+// processTick applies the schedule window active at now
+func (nh *InactivityResyncMonitor) processTick(now time.Time) {
+	window := activeScheduleWindow(nh.windows, now)
+	if window.mode != nh.scheduleMode {
+		log.Info("processTick - schedule window changed", "from", nh.scheduleMode, "to", window.mode)
+		nh.scheduleMode = window.mode
+	}
+	switch window.mode {
+	case config.ScheduleAwake:
+		nh.processAwakeWindow()
+	case config.ScheduleHibernate:
+		nh.tickInactivity(window.inactivityTime, history.TriggerSchedule)
+	default:
+		nh.tickInactivity(nh.nodeCtrl.config.BasicConfig.InactivityTime, history.TriggerInactivity)
+	}
+}
+
+// tickInactivity increments the inactivity time and processes inactivity once it reaches inactivityTime
+func (nh *InactivityResyncMonitor) tickInactivity(inactivityTime int, trigger history.Trigger) {
+	nh.mux.Lock()
+	if nh.inactiveTimeCount >= inactivityTime {
+		nh.mux.Unlock()
+		nh.processInactivity(trigger)
+		return
+	}
+	nh.inactiveTimeCount++
+	count := nh.inactiveTimeCount
+	nh.mux.Unlock()
+	log.Trace("trackInactivity - inactivity ticking", "inactive seconds", count)
+	metrics.SetInactivitySeconds(count)
+	if count%stateFlushInterval == 0 {
+		nh.nodeCtrl.persistState()
+	}
+}
+
+// processAwakeWindow starts the node if it is down. Inactivity is not tracked while an awake window is active.
+func (nh *InactivityResyncMonitor) processAwakeWindow() {
+	if nh.GetInactivityTimeCount() != 0 {
+		nh.ResetInactivity()
+	}
+	if nh.nodeCtrl.IsClientUp() || nh.nodeCtrl.IsNodeBusy() != nil {
+		return
+	}
+	log.Info("processAwakeWindow - node is down in awake window, requesting node start")
+	nh.nodeCtrl.RequestStartClient(history.TriggerSchedule)
+	status := nh.nodeCtrl.WaitStartClient()
+	log.Info("processAwakeWindow - node start completed", "status", status)
+}
+
 // trackResyncTimer brings up the node after certain period of hibernation to
 // resync with the network
 func (nh *InactivityResyncMonitor) trackResyncTimer() {
@@ -57,7 +115,7 @@ func (nh *InactivityResyncMonitor) trackResyncTimer() {
 		return
 	}
 	resyncTime := time.Duration(nh.nodeCtrl.config.BasicConfig.ResyncTime) * time.Second
-	timer := time.NewTimer(resyncTime)
+	timer := time.NewTimer(nh.initialResyncWait(resyncTime))
 	defer timer.Stop()
 
 	log.Info("trackResyncTimer - node resync tracker started", "resyncTime", nh.nodeCtrl.config.BasicConfig.ResyncTime)
@@ -65,10 +123,13 @@ func (nh *InactivityResyncMonitor) trackResyncTimer() {
 	for {
 		select {
 		case <-timer.C:
+			nh.setResyncTimerStart(time.Time{})
 			nh.processResyncRequest()
 
 		case <-nh.nodeCtrl.syncResetCh:
 			timer.Reset(resyncTime)
+			nh.setResyncTimerStart(time.Now())
+			nh.nodeCtrl.persistState()
 
 		case <-nh.stopCh:
 			log.Info("trackResyncTimer - stopped inactivity monitor")
@@ -78,11 +139,34 @@ func (nh *InactivityResyncMonitor) trackResyncTimer() {
 
 }
 
+// initialResyncWait returns the time to wait before the first resync.
+// If the resync timer was restored from persisted state it resumes the remaining time,
+// otherwise it waits for the full resyncTime.
+func (nh *InactivityResyncMonitor) initialResyncWait(resyncTime time.Duration) time.Duration {
+	nh.mux.Lock()
+	defer nh.mux.Unlock()
+	if nh.resyncTimerStart.IsZero() {
+		nh.resyncTimerStart = time.Now()
+		return resyncTime
+	}
+	remaining := resyncTime - time.Since(nh.resyncTimerStart)
+	if remaining < 0 {
+		remaining = 0
+	}
+	log.Info("initialResyncWait - resuming resync timer", "remaining", remaining)
+	return remaining
+}
+
 func (nh *InactivityResyncMonitor) processResyncRequest() {
+	if activeScheduleMode(nh.windows, time.Now()) == config.ScheduleHibernate {
+		log.Info("trackResyncTimer - hibernate window is active, skipping resync")
+		return
+	}
 	if err := nh.nodeCtrl.IsNodeBusy(); err == nil {
+		nh.nodeCtrl.history.Record(history.Event{Type: history.ResyncDue, Trigger: history.TriggerResyncTimer})
 		nh.ResetInactivity()
 		// restart node for sync. node shut down will happen based on inactivity
-		nh.nodeCtrl.RequestStartClient()
+		nh.nodeCtrl.RequestStartClient(history.TriggerResyncTimer)
 		log.Info("trackResyncTimer - requested node start, waiting for start complete")
 		status := nh.nodeCtrl.WaitStartClient()
 		log.Info("trackResyncTimer - resuming resync timer", "start status", status)
@@ -92,7 +176,7 @@ func (nh *InactivityResyncMonitor) processResyncRequest() {
 }
 
 // processInactivity requests the node to be stopped if the node  is not busy.
-func (nh *InactivityResyncMonitor) processInactivity() {
+func (nh *InactivityResyncMonitor) processInactivity(trigger history.Trigger) {
 
 	log.Info("processInactivity - going to try stop node as it has been inactive", "inactivetime", nh.nodeCtrl.config.BasicConfig.InactivityTime)
 	if err := nh.nodeCtrl.IsNodeBusy(); err != nil {
@@ -104,7 +188,8 @@ func (nh *InactivityResyncMonitor) processInactivity() {
 		// client. This is to handle scenarios where in the node was
 		// brought up in the backend bypassing node hibernator
 		if nh.nodeCtrl.CheckClientUpStatus(true) {
-			nh.nodeCtrl.RequestStopClient()
+			nh.nodeCtrl.history.Record(history.Event{Type: history.InactivityLimitReached, Trigger: trigger})
+			nh.nodeCtrl.RequestStopClient(trigger)
 			log.Info("processInactivity - requested node shutdown, waiting for shutdown complete")
 			status := nh.nodeCtrl.WaitStopClient()
 			log.Info("processInactivity - resuming inactivity time tracker", "shutdown status", status)
@@ -116,9 +201,13 @@ func (nh *InactivityResyncMonitor) processInactivity() {
 }
 
 func (nh *InactivityResyncMonitor) ResetInactivity() {
+	nh.mux.Lock()
 	wasInactive := nh.inactiveTimeCount
 	nh.inactiveTimeCount = 0
+	nh.mux.Unlock()
+	metrics.SetInactivitySeconds(0)
 	log.Info("ResetInactivity - inactivity reset", "was inactive for (seconds)", wasInactive)
+	nh.nodeCtrl.persistState()
 }
 
 func (nh *InactivityResyncMonitor) Stop() {
@@ -126,5 +215,20 @@ func (nh *InactivityResyncMonitor) Stop() {
 }
 
 func (nh *InactivityResyncMonitor) GetInactivityTimeCount() int {
+	nh.mux.Lock()
+	defer nh.mux.Unlock()
 	return nh.inactiveTimeCount
 }
+
+func (nh *InactivityResyncMonitor) setResyncTimerStart(t time.Time) {
+	nh.mux.Lock()
+	defer nh.mux.Unlock()
+	nh.resyncTimerStart = t
+}
+
+// state returns the inactivity time count and the resync timer start to be persisted
+func (nh *InactivityResyncMonitor) state() (int, time.Time) {
+	nh.mux.Lock()
+	defer nh.mux.Unlock()
+	return nh.inactiveTimeCount, nh.resyncTimerStart
+}
@@ -1,9 +1,12 @@
 package node
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/history"
 
 	"github.com/ConsenSys/quorum-hibernate/log"
 	"github.com/ConsenSys/quorum-hibernate/p2p"
@@ -22,6 +25,35 @@ type PrivateTxPrepReply struct {
 	Status bool
 }
 
+type HibernateArgs struct {
+	From  string `json:"from"`  // name of the caller
+	Force bool   `json:"force"` // skip consensus and peer validations
+}
+
+// NodeActionReply is the reply to a request to hibernate or wake the node.
+// Status is true if the request was accepted.
+// If the request was refused Reason and Message describe why it was refused.
+type NodeActionReply struct {
+	Status  bool
+	Reason  string
+	Message string
+}
+
+type ResetStatusReply struct {
+	ClientUp bool // true if blockchain client and privacy manager are up after the reset
+}
+
+type HistoryArgs struct {
+	From  string              `json:"from"`  // name of the caller
+	Since *time.Time          `json:"since"` // return events at or after this time (RFC 3339). optional
+	Until *time.Time          `json:"until"` // return events at or before this time (RFC 3339). optional
+	Types []history.EventType `json:"types"` // return events of these types only. optional
+}
+
+type HistoryReply struct {
+	Events []history.Event
+}
+
 func NewNodeRPCAPIs(qn ControllerApiService, conf *config.Node) *NodeRPCAPIs {
 	return &NodeRPCAPIs{
 		service: qn,
@@ -55,11 +87,11 @@ func (n *NodeRPCAPIs) PrepareForPrivateTx(_ *http.Request, from *string, reply *
 			*reply = PrivateTxPrepReply{Status: false}
 			go func() {
 				log.Info("PrepareForPrivateTx - rpc call - prepareNode triggered")
-				s := n.service.PrepareClient()
+				s := n.service.PrepareClient(history.TriggerPeerPrivateTx)
 				log.Info("PrepareForPrivateTx - rpc call - prepareNode triggered completed", "status", s)
 			}()
 		} else {
-			status = n.service.PrepareClient()
+			status = n.service.PrepareClient(history.TriggerPeerPrivateTx)
 			*reply = PrivateTxPrepReply{Status: status}
 		}
 	}
@@ -76,3 +108,94 @@ func (n *NodeRPCAPIs) NodeStatus(_ *http.Request, from *string, reply *p2p.NodeS
 	log.Info("ClientStatus - rpc call", "from", *from, "status", status)
 	return nil
 }
+
+// Hibernate requests the node to be hibernated.
+// It runs the same consensus and peer validations as hibernation on inactivity, unless force is set.
+// Status is true if the validations passed and the node is being hibernated in the background.
+func (n *NodeRPCAPIs) Hibernate(_ *http.Request, args *HibernateArgs, reply *NodeActionReply) error {
+	log.Info("Hibernate - rpc call - request received to hibernate node", "from", args.From, "force", args.Force)
+	*reply = newNodeActionReply(n.service.Hibernate(args.Force))
+	log.Info("Hibernate - rpc call - request processed", "from", args.From, "reply", *reply)
+	return nil
+}
+
+// Wake requests the node to be woken up.
+// Status is true if the node is being started in the background.
+func (n *NodeRPCAPIs) Wake(_ *http.Request, from *string, reply *NodeActionReply) error {
+	log.Info("Wake - rpc call - request received to wake node", "from", *from)
+	*reply = newNodeActionReply(n.service.Wake())
+	log.Info("Wake - rpc call - request processed", "from", *from, "reply", *reply)
+	return nil
+}
+
+// ResetStatus forces the node status to be reset. It should be used to recover the node hibernator when
+// the node status is stuck after a failed shutdown/startup.
+// The client status is set based on the current status of blockchain client and privacy manager.
+func (n *NodeRPCAPIs) ResetStatus(_ *http.Request, from *string, reply *ResetStatusReply) error {
+	log.Warn("ResetStatus - rpc call - request received to reset node status", "from", *from)
+	*reply = ResetStatusReply{ClientUp: n.service.ResetStatus()}
+	log.Info("ResetStatus - rpc call - request processed", "from", *from, "reply", *reply)
+	return nil
+}
+
+// History returns the lifecycle events of the node in chronological order, optionally filtered by time range and event types.
+func (n *NodeRPCAPIs) History(_ *http.Request, args *HistoryArgs, reply *HistoryReply) error {
+	log.Debug("History - rpc call - request received", "from", args.From, "since", args.Since, "until", args.Until, "types", args.Types)
+	var since, until time.Time
+	if args.Since != nil {
+		since = *args.Since
+	}
+	if args.Until != nil {
+		until = *args.Until
+	}
+	events := n.service.History(since, until, args.Types)
+	if events == nil {
+		events = []history.Event{}
+	}
+	*reply = HistoryReply{Events: events}
+	log.Debug("History - rpc call - request processed", "from", args.From, "events", len(events))
+	return nil
+}
+
+// Peers exchanges peer records with a peer when peer discovery is enabled. The peer records of the caller are merged
+// and the peer records known to this node hibernator are returned.
+func (n *NodeRPCAPIs) Peers(_ *http.Request, args *p2p.PeersArgs, reply *p2p.PeersReply) error {
+	log.Debug("Peers - rpc call - request received", "from", args.From, "records", len(args.Records))
+	records, err := n.service.ExchangePeerRecords(args.Records)
+	if err != nil {
+		return err
+	}
+	*reply = p2p.PeersReply{Records: records}
+	log.Debug("Peers - rpc call - request processed", "from", args.From, "records", len(records))
+	return nil
+}
+
+// RequestHibernationLease is called by a peer to acquire a hibernation lease before it hibernates.
+// The lease is granted if this node hibernator has not granted the configured number of concurrent leases yet.
+func (n *NodeRPCAPIs) RequestHibernationLease(_ *http.Request, args *p2p.LeaseArgs, reply *p2p.LeaseReply) error {
+	log.Debug("RequestHibernationLease - rpc call - request received", "from", args.From, "id", args.ID)
+	*reply = n.service.GrantLease(args.From, args.ID)
+	log.Debug("RequestHibernationLease - rpc call - request processed", "from", args.From, "reply", *reply)
+	return nil
+}
+
+// ReleaseLease is called by a peer to release the hibernation lease it acquired with RequestHibernationLease.
+func (n *NodeRPCAPIs) ReleaseLease(_ *http.Request, args *p2p.LeaseArgs, reply *p2p.ReleaseLeaseReply) error {
+	log.Debug("ReleaseLease - rpc call - request received", "from", args.From, "id", args.ID)
+	*reply = p2p.ReleaseLeaseReply{Released: n.service.ReleaseLease(args.From, args.ID)}
+	log.Debug("ReleaseLease - rpc call - request processed", "from", args.From, "reply", *reply)
+	return nil
+}
+
+func newNodeActionReply(err error) NodeActionReply {
+	if err == nil {
+		return NodeActionReply{Status: true}
+	}
+	reply := NodeActionReply{Status: false, Message: err.Error()}
+	var r *RefusalError
+	if errors.As(err, &r) {
+		reply.Reason = r.Reason
+		reply.Message = r.Cause.Error()
+	}
+	return reply
+}
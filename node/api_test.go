@@ -10,6 +10,7 @@ import (
 
 	"github.com/ConsenSys/quorum-hibernate/config"
 	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/p2p"
 
 	"github.com/stretchr/testify/require"
@@ -232,7 +233,241 @@ func TestNodeRPCAPIs_NodeStatus(t *testing.T) {
 	require.Equal(t, callCounts, service.callCount)
 }
 
+func TestNodeRPCAPIs_Hibernate(t *testing.T) {
+	conf := &config.Node{}
+
+	tests := []struct {
+		name               string
+		args               HibernateArgs
+		mockServiceResults map[string]interface{}
+		want               NodeActionReply
+	}{
+		{
+			name: "accepted",
+			args: HibernateArgs{From: "admin"},
+			want: NodeActionReply{Status: true},
+		},
+		{
+			name: "refused",
+			args: HibernateArgs{From: "admin"},
+			mockServiceResults: map[string]interface{}{
+				"Hibernate": newRefusalError(ReasonPeers, errors.New("some node managers did not respond")),
+			},
+			want: NodeActionReply{Status: false, Reason: ReasonPeers, Message: "some node managers did not respond"},
+		},
+		{
+			name: "forced",
+			args: HibernateArgs{From: "admin", Force: true},
+			want: NodeActionReply{Status: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewMockControllerApiService(tt.mockServiceResults)
+
+			api := NewNodeRPCAPIs(service, conf)
+
+			var got NodeActionReply
+
+			err := api.Hibernate(nil, &tt.args, &got)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, map[string]int{"Hibernate": 1}, service.callCount)
+			require.Equal(t, tt.args.Force, service.results["HibernateForce"])
+		})
+	}
+}
+
+func TestNodeRPCAPIs_Wake(t *testing.T) {
+	var (
+		conf  = &config.Node{}
+		param = new(string)
+	)
+
+	tests := []struct {
+		name               string
+		mockServiceResults map[string]interface{}
+		want               NodeActionReply
+	}{
+		{
+			name: "accepted",
+			want: NodeActionReply{Status: true},
+		},
+		{
+			name: "refused",
+			mockServiceResults: map[string]interface{}{
+				"Wake": newRefusalError(ReasonBusy, errors.New(core.NodeIsBeingShutdown)),
+			},
+			want: NodeActionReply{Status: false, Reason: ReasonBusy, Message: core.NodeIsBeingShutdown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewMockControllerApiService(tt.mockServiceResults)
+
+			api := NewNodeRPCAPIs(service, conf)
+
+			var got NodeActionReply
+
+			err := api.Wake(nil, param, &got)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, map[string]int{"Wake": 1}, service.callCount)
+		})
+	}
+}
+
+func TestNodeRPCAPIs_ResetStatus(t *testing.T) {
+	var (
+		conf  = &config.Node{}
+		param = new(string)
+	)
+
+	service := NewMockControllerApiService(map[string]interface{}{
+		"ResetStatus": true,
+	})
+
+	api := NewNodeRPCAPIs(service, conf)
+
+	var got ResetStatusReply
+
+	err := api.ResetStatus(nil, param, &got)
+
+	require.NoError(t, err)
+	require.Equal(t, ResetStatusReply{ClientUp: true}, got)
+	require.Equal(t, map[string]int{"ResetStatus": 1}, service.callCount)
+}
+
+func TestNodeRPCAPIs_History(t *testing.T) {
+	var (
+		conf   = &config.Node{}
+		since  = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		events = []history.Event{
+			{Time: since.Add(time.Minute), Type: history.Hibernated, Trigger: history.TriggerInactivity, Duration: 1500},
+		}
+	)
+
+	tests := []struct {
+		name     string
+		args     HistoryArgs
+		results  []history.Event
+		wantArgs []interface{}
+		want     HistoryReply
+	}{
+		{
+			name:     "noFilters",
+			args:     HistoryArgs{From: "admin"},
+			results:  events,
+			wantArgs: []interface{}{time.Time{}, time.Time{}, []history.EventType(nil)},
+			want:     HistoryReply{Events: events},
+		},
+		{
+			name:     "filters",
+			args:     HistoryArgs{From: "admin", Since: &since, Types: []history.EventType{history.Hibernated}},
+			results:  events,
+			wantArgs: []interface{}{since, time.Time{}, []history.EventType{history.Hibernated}},
+			want:     HistoryReply{Events: events},
+		},
+		{
+			name:     "noEvents",
+			args:     HistoryArgs{From: "admin"},
+			results:  nil,
+			wantArgs: []interface{}{time.Time{}, time.Time{}, []history.EventType(nil)},
+			want:     HistoryReply{Events: []history.Event{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := map[string]interface{}{}
+			if tt.results != nil {
+				results["History"] = tt.results
+			}
+			service := NewMockControllerApiService(results)
+
+			api := NewNodeRPCAPIs(service, conf)
+
+			var got HistoryReply
+
+			err := api.History(nil, &tt.args, &got)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantArgs, service.results["HistoryArgs"])
+			require.Equal(t, 1, service.callCount["History"])
+		})
+	}
+}
+
+func TestNodeRPCAPIs_Peers(t *testing.T) {
+	var (
+		conf     = &config.Node{}
+		received = []p2p.PeerRecord{{Name: "caller"}}
+		known    = []p2p.PeerRecord{{Name: "caller"}, {Name: "self"}}
+	)
+
+	service := NewMockControllerApiService(map[string]interface{}{
+		"ExchangePeerRecords": known,
+	})
+	api := NewNodeRPCAPIs(service, conf)
+
+	var got p2p.PeersReply
+	err := api.Peers(nil, &p2p.PeersArgs{From: "caller", Records: received}, &got)
+
+	require.NoError(t, err)
+	require.Equal(t, p2p.PeersReply{Records: known}, got)
+	require.Equal(t, received, service.results["ExchangePeerRecordsArgs"])
+	require.Equal(t, map[string]int{"ExchangePeerRecords": 1}, service.callCount)
+
+	service = NewMockControllerApiService(map[string]interface{}{
+		"ExchangePeerRecords": p2p.ErrDiscoveryDisabled,
+	})
+	api = NewNodeRPCAPIs(service, conf)
+
+	err = api.Peers(nil, &p2p.PeersArgs{From: "caller"}, &got)
+
+	require.Equal(t, p2p.ErrDiscoveryDisabled, err)
+}
+
+func TestNodeRPCAPIs_RequestHibernationLease(t *testing.T) {
+	want := p2p.LeaseReply{Granted: true, Expiry: time.Unix(1600000000, 0), Holders: []string{"caller"}}
+	service := NewMockControllerApiService(map[string]interface{}{
+		"GrantLease": want,
+	})
+	api := NewNodeRPCAPIs(service, &config.Node{})
+
+	var got p2p.LeaseReply
+	err := api.RequestHibernationLease(nil, &p2p.LeaseArgs{From: "caller", ID: "id1"}, &got)
+
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, []string{"caller", "id1"}, service.results["GrantLeaseArgs"])
+	require.Equal(t, map[string]int{"GrantLease": 1}, service.callCount)
+}
+
+func TestNodeRPCAPIs_ReleaseLease(t *testing.T) {
+	service := NewMockControllerApiService(map[string]interface{}{
+		"ReleaseLease": true,
+	})
+	api := NewNodeRPCAPIs(service, &config.Node{})
+
+	var got p2p.ReleaseLeaseReply
+	err := api.ReleaseLease(nil, &p2p.LeaseArgs{From: "caller", ID: "id1"}, &got)
+
+	require.NoError(t, err)
+	require.Equal(t, p2p.ReleaseLeaseReply{Released: true}, got)
+	require.Equal(t, []string{"caller", "id1"}, service.results["ReleaseLeaseArgs"])
+	require.Equal(t, map[string]int{"ReleaseLease": 1}, service.callCount)
+}
+
 func NewMockControllerApiService(results map[string]interface{}) *mockControllerApiService {
+	if results == nil {
+		results = make(map[string]interface{})
+	}
 	return &mockControllerApiService{
 		results:   results,
 		callCount: make(map[string]int),
@@ -273,8 +508,9 @@ func (s *mockControllerApiService) IsNodeBusy() error {
 	return s.results[getMethodName()].(error)
 }
 
-func (s *mockControllerApiService) PrepareClient() bool {
+func (s *mockControllerApiService) PrepareClient(trigger history.Trigger) bool {
 	s.callCount[getMethodName()]++
+	s.results["PrepareClientTrigger"] = trigger
 	if s.results[getMethodName()] == nil {
 		return false
 	}
@@ -297,6 +533,70 @@ func (s *mockControllerApiService) GetInactivityTimeCount() int {
 	return s.results[getMethodName()].(int)
 }
 
+func (s *mockControllerApiService) Hibernate(force bool) error {
+	s.callCount[getMethodName()]++
+	s.results["HibernateForce"] = force
+	if s.results[getMethodName()] == nil {
+		return nil
+	}
+	return s.results[getMethodName()].(error)
+}
+
+func (s *mockControllerApiService) Wake() error {
+	s.callCount[getMethodName()]++
+	if s.results[getMethodName()] == nil {
+		return nil
+	}
+	return s.results[getMethodName()].(error)
+}
+
+func (s *mockControllerApiService) ResetStatus() bool {
+	s.callCount[getMethodName()]++
+	if s.results[getMethodName()] == nil {
+		return false
+	}
+	return s.results[getMethodName()].(bool)
+}
+
+func (s *mockControllerApiService) History(since, until time.Time, types []history.EventType) []history.Event {
+	s.callCount[getMethodName()]++
+	s.results["HistoryArgs"] = []interface{}{since, until, types}
+	if s.results[getMethodName()] == nil {
+		return nil
+	}
+	return s.results[getMethodName()].([]history.Event)
+}
+
+func (s *mockControllerApiService) ExchangePeerRecords(records []p2p.PeerRecord) ([]p2p.PeerRecord, error) {
+	s.callCount[getMethodName()]++
+	s.results["ExchangePeerRecordsArgs"] = records
+	if err, ok := s.results[getMethodName()].(error); ok {
+		return nil, err
+	}
+	if s.results[getMethodName()] == nil {
+		return nil, nil
+	}
+	return s.results[getMethodName()].([]p2p.PeerRecord), nil
+}
+
+func (s *mockControllerApiService) GrantLease(holder, id string) p2p.LeaseReply {
+	s.callCount[getMethodName()]++
+	s.results["GrantLeaseArgs"] = []string{holder, id}
+	if s.results[getMethodName()] == nil {
+		return p2p.LeaseReply{}
+	}
+	return s.results[getMethodName()].(p2p.LeaseReply)
+}
+
+func (s *mockControllerApiService) ReleaseLease(holder, id string) bool {
+	s.callCount[getMethodName()]++
+	s.results["ReleaseLeaseArgs"] = []string{holder, id}
+	if s.results[getMethodName()] == nil {
+		return false
+	}
+	return s.results[getMethodName()].(bool)
+}
+
 func getMethodName() string {
 	pc, _, _, _ := runtime.Caller(1)
 	nameFull := runtime.FuncForPC(pc).Name()
@@ -2,7 +2,12 @@ package node
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,7 +16,9 @@ import (
 	besu "github.com/ConsenSys/quorum-hibernate/consensus/besu"
 	qnh "github.com/ConsenSys/quorum-hibernate/consensus/quorum"
 	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 	"github.com/ConsenSys/quorum-hibernate/p2p"
 	"github.com/ConsenSys/quorum-hibernate/privatetx"
 	proc "github.com/ConsenSys/quorum-hibernate/process"
@@ -19,38 +26,52 @@ import (
 
 const CONSENSUS_WAIT_TIME = 60
 
+// leaseAcquireAttempts is the number of attempts to acquire a hibernation lease before hibernation is refused
+const leaseAcquireAttempts = 3
+
 // NodeControl represents a node hibernator controller.
 // It implements ControllerApiService
 // It tracks blockchain client/privacyManager processes' inactivity and it allows inactivity to be reset when
 // there is some activity.
-// It accepts request to stop blockchain client/privacyManager when there is inactivity.
-// It starts blockchain client/privacyManager processes when there is a activity.
-// It takes care of managing combined status of blockchain client & privacyManager.
+// It accepts request to stop blockchain client/privacyManager and any additional processes when there is inactivity.
+// It starts blockchain client/privacyManager and any additional processes when there is a activity.
+// It takes care of managing combined status of all the managed processes.
 type NodeControl struct {
 	config              *config.Node             // config of this node
 	im                  *InactivityResyncMonitor // inactivity monitor
 	nh                  *p2p.PeerManager         // node hibernator to communicate with other node hibernator
-	bcclntProcess       proc.Process             // blockchain client process controller
-	pmclntProcess       proc.Process             // privacy manager process controller
+	processes           [][]*managedProcess      // managed processes grouped in dependency order. see config.Basic.ProcessLevels
 	bcclntHttpClient    *http.Client             // blockchain client http client
 	pmclntHttpClient    *http.Client             // privacy manager http client
 	consensus           cons.Consensus           // consensus validator
 	txh                 privatetx.TxHandler      // Transaction handler
+	stateStore          *StateStore              // persists node hibernator state across restarts. nil if dataDir is not set
+	history             *history.Recorder        // history of lifecycle events of the node
 	withPrivMan         bool                     // indicates if the node is running with a privacy manage
 	consValid           bool                     // indicates if network level consensus is valid
-	clientStatus        core.ClientStatus        // combined status of blockchain client and privacy manager processes
+	clientStatus        core.ClientStatus        // combined status of all managed processes
 	nodeStatus          core.NodeStatus          // status of node hibernator
+	nodeStatusTime      time.Time                // time at which node status was last changed
+	wd                  *Watchdog                // watchdog to recover from stuck shutdown/startup. nil if not configured
+	leaseID             string                   // id of the hibernation lease held while the node is shutdown or hibernated. empty if no lease is held
 	inactivityResetCh   chan bool                // channel to reset inactivity
 	syncResetCh         chan bool                // channel to reset sync timer
-	stopClntCh          chan bool                // channel to request stop node
+	stopClntCh          chan history.Trigger     // channel to request stop node
 	stopClntCompleteCh  chan bool                // channel to notify stop node action status
-	startClntCh         chan bool                // channel to request start node
+	startClntCh         chan history.Trigger     // channel to request start node
 	startClntCompleteCh chan bool                // channel to notify start node action status
 	stopCh              chan bool                // channel to stop start/stop node monitor
 	clntStatMonStopCh   chan bool                // channel to stop node status monitor
 	startStopMux        sync.Mutex               // lock for starting and stopping node
 	clntStatusMux       sync.Mutex               // lock for setting the client status
 	nodeStatusMux       sync.Mutex               // lock for setting the node status
+	leaseMux            sync.Mutex               // lock for leaseID
+}
+
+// managedProcess is a process controller together with the name of the process it controls
+type managedProcess struct {
+	name    string
+	process proc.Process
 }
 
 func (n *NodeControl) ClientStatus() core.ClientStatus {
@@ -59,17 +80,23 @@ func (n *NodeControl) ClientStatus() core.ClientStatus {
 	return n.clientStatus
 }
 
-func NewNodeControl(cfg *config.Node) *NodeControl {
+func NewNodeControl(cfg *config.Node) (*NodeControl, error) {
+	h := newHistoryRecorder(cfg)
+	nh, err := p2p.NewPeerManager(cfg, h)
+	if err != nil {
+		return nil, err
+	}
 	node := &NodeControl{
 		config:              cfg,
-		nh:                  p2p.NewPeerManager(cfg),
+		nh:                  nh,
+		history:             h,
 		withPrivMan:         cfg.BasicConfig.PrivacyManager != nil,
 		nodeStatus:          core.OK,
 		inactivityResetCh:   make(chan bool, 1),
 		syncResetCh:         make(chan bool, 1),
-		stopClntCh:          make(chan bool, 1),
+		stopClntCh:          make(chan history.Trigger, 1),
 		stopClntCompleteCh:  make(chan bool, 1),
-		startClntCh:         make(chan bool, 1),
+		startClntCh:         make(chan history.Trigger, 1),
 		startClntCompleteCh: make(chan bool, 1),
 		stopCh:              make(chan bool, 1),
 		clntStatMonStopCh:   make(chan bool, 1),
@@ -77,27 +104,21 @@ func NewNodeControl(cfg *config.Node) *NodeControl {
 
 	setHttpClients(cfg, node)
 
-	if cfg.BasicConfig.BlockchainClient.BcClntProcess.IsShell() {
-		node.bcclntProcess = proc.NewShellProcess(node.bcclntHttpClient, cfg.BasicConfig.BlockchainClient.BcClntProcess, true)
-	} else if cfg.BasicConfig.BlockchainClient.BcClntProcess.IsDocker() {
-		node.bcclntProcess = proc.NewDockerProcess(node.bcclntHttpClient, cfg.BasicConfig.BlockchainClient.BcClntProcess, true)
-	}
-
-	if node.WithPrivMan() {
-		if cfg.BasicConfig.PrivacyManager.PrivManProcess.IsShell() {
-			node.pmclntProcess = proc.NewShellProcess(node.pmclntHttpClient, cfg.BasicConfig.PrivacyManager.PrivManProcess, true)
-		} else if cfg.BasicConfig.PrivacyManager.PrivManProcess.IsDocker() {
-			node.pmclntProcess = proc.NewDockerProcess(node.pmclntHttpClient, cfg.BasicConfig.PrivacyManager.PrivManProcess, true)
-		}
+	if err := setProcesses(cfg, node); err != nil {
+		return nil, err
 	}
 	node.im = NewInactivityResyncMonitor(node)
+	if cfg.BasicConfig.Watchdog != nil {
+		node.wd = NewWatchdog(node, cfg.BasicConfig.Watchdog)
+	}
+	loadState(node)
 	populateConsensusHandler(node)
 	if node.config.BasicConfig.IsGoQuorumClient() {
 		node.txh = privatetx.NewQuorumTxHandler(node.config)
 	} // TODO add tx handler for Besu
 	node.config.BasicConfig.InactivityTime += getRandomBufferTime(node.config.BasicConfig.InactivityTime)
 	log.Debug("Node config - inactivity time after random buffer", "InactivityTime", node.config.BasicConfig.InactivityTime)
-	return node
+	return node, nil
 }
 
 func setHttpClients(cfg *config.Node, node *NodeControl) {
@@ -116,6 +137,118 @@ func setHttpClients(cfg *config.Node, node *NodeControl) {
 	}
 }
 
+// setProcesses creates the controllers of the managed processes grouped in dependency order
+func setProcesses(cfg *config.Node, node *NodeControl) error {
+	levels, err := cfg.BasicConfig.ProcessLevels()
+	if err != nil {
+		return err
+	}
+	for _, level := range levels {
+		var mps []*managedProcess
+		for _, p := range level {
+			client := core.NewHttpClient(nil)
+			if p == cfg.BasicConfig.BlockchainClient.BcClntProcess {
+				client = node.bcclntHttpClient
+			} else if node.WithPrivMan() && p == cfg.BasicConfig.PrivacyManager.PrivManProcess {
+				client = node.pmclntHttpClient
+			}
+			mps = append(mps, &managedProcess{name: p.Name, process: newProcess(client, p)})
+		}
+		node.processes = append(node.processes, mps)
+	}
+	return nil
+}
+
+// newProcess returns the controller for the process based on its control type
+func newProcess(client *http.Client, p *config.Process) proc.Process {
+	if p.IsDocker() {
+		return proc.NewDockerProcess(client, p, true)
+	}
+	if p.IsExec() {
+		return proc.NewExecProcess(client, p, true)
+	}
+	if p.IsKubernetes() {
+		return proc.NewKubernetesProcess(client, p, true)
+	}
+	return proc.NewShellProcess(client, p, true)
+}
+
+// newHistoryRecorder returns the recorder for lifecycle events. Events are persisted to dataDir if configured.
+func newHistoryRecorder(cfg *config.Node) *history.Recorder {
+	h := cfg.BasicConfig.History
+	if h == nil {
+		return history.NewRecorder(history.DefaultSize, "")
+	}
+	var file string
+	if h.Persist {
+		if err := os.MkdirAll(cfg.BasicConfig.DataDir, 0700); err != nil {
+			log.Error("newHistoryRecorder - unable to create data dir, history will not be persisted", "dataDir", cfg.BasicConfig.DataDir, "err", err)
+		} else {
+			file = filepath.Join(cfg.BasicConfig.DataDir, historyFileName)
+		}
+	}
+	return history.NewRecorder(h.Size, file)
+}
+
+// loadState restores the node hibernator state persisted before the last restart if dataDir is set
+func loadState(node *NodeControl) {
+	if !node.config.BasicConfig.IsDataDirSet() {
+		return
+	}
+	var err error
+	if node.stateStore, err = NewStateStore(node.config.BasicConfig.DataDir); err != nil {
+		log.Error("loadState - unable to create state store, state will not be persisted", "dataDir", node.config.BasicConfig.DataDir, "err", err)
+		return
+	}
+	st, err := node.stateStore.Load()
+	if err != nil {
+		log.Error("loadState - unable to load persisted state, starting with default state", "err", err)
+		return
+	}
+	if st == nil {
+		log.Info("loadState - no persisted state found, starting with default state")
+		return
+	}
+	node.restoreState(st)
+}
+
+// restoreState sets the statuses and inactivity/resync timers from the given persisted state
+func (n *NodeControl) restoreState(st *NodeState) {
+	log.Info("restoreState - restoring persisted state", "state", *st)
+	if st.NodeStatus != core.OK {
+		// the start/stop action in progress was interrupted by the restart, it cannot be resumed
+		// so node status is left as OK
+		log.Warn("restoreState - node hibernator was restarted while busy, resetting node status", "status", st.NodeStatus)
+	}
+	if st.ClientStatus == core.Up || st.ClientStatus == core.Down {
+		n.clientStatus = st.ClientStatus
+	}
+	n.im.inactiveTimeCount = st.InactiveTimeCount
+	if n.im.inactiveTimeCount > n.config.BasicConfig.InactivityTime {
+		n.im.inactiveTimeCount = n.config.BasicConfig.InactivityTime
+	}
+	if !st.ResyncTimerStart.IsZero() {
+		n.im.resyncTimerStart = st.ResyncTimerStart
+	}
+}
+
+// persistState saves the current state of the node hibernator if dataDir is set
+func (n *NodeControl) persistState() {
+	if n.stateStore == nil {
+		return
+	}
+	st := NodeState{
+		NodeStatus:   n.GetNodeStatus(),
+		ClientStatus: n.ClientStatus(),
+	}
+	if n.im != nil {
+		st.InactiveTimeCount, st.ResyncTimerStart = n.im.state()
+	}
+	if err := n.stateStore.Save(st); err != nil {
+		log.Error("persistState - saving state failed", "err", err)
+	}
+}
+
 func (n *NodeControl) WithPrivMan() bool {
 	return n.withPrivMan
 }
@@ -160,6 +293,13 @@ func (n *NodeControl) GetNodeStatus() core.NodeStatus {
 	return n.nodeStatus
 }
 
+// getNodeStatusWithTime returns the node status and the time at which it was set
+func (n *NodeControl) getNodeStatusWithTime() (core.NodeStatus, time.Time) {
+	n.nodeStatusMux.Lock()
+	defer n.nodeStatusMux.Unlock()
+	return n.nodeStatus, n.nodeStatusTime
+}
+
 func (n *NodeControl) GetProxyConfig() []*config.Proxy {
 	return n.config.BasicConfig.Proxies
 }
@@ -169,17 +309,22 @@ func (n *NodeControl) GetTxHandler() privatetx.TxHandler {
 }
 
 func (n *NodeControl) SetClntStatus(ns core.ClientStatus) {
-	defer n.clntStatusMux.Unlock()
 	n.clntStatusMux.Lock()
 	log.Debug("SetClntStatus", "old", n.clientStatus, "new", ns)
 	n.clientStatus = ns
+	n.clntStatusMux.Unlock()
+	metrics.SetClientStatus(ns)
+	n.persistState()
 }
 
 func (n *NodeControl) SetNodeStatus(ns core.NodeStatus) {
-	defer n.nodeStatusMux.Unlock()
 	n.nodeStatusMux.Lock()
 	log.Debug("SetNodeStatus", "old", n.nodeStatus, "new", ns)
 	n.nodeStatus = ns
+	n.nodeStatusTime = time.Now()
+	n.nodeStatusMux.Unlock()
+	metrics.SetNodeStatus(ns)
+	n.persistState()
 }
 
 func (n *NodeControl) IsClientUp() bool {
@@ -197,10 +342,8 @@ func (n *NodeControl) CheckClientUpStatus(connectToClient bool) bool {
 		return false
 	}
 
-	bcclntStatus, pmStatus := n.fetchCurrentClientStatuses()
-	log.Debug("CheckClientUpStatus", "blockchain client", bcclntStatus, "privacy manager", pmStatus)
-
-	areClientsUp := bcclntStatus && pmStatus
+	areClientsUp, _ := n.fetchCurrentClientStatuses()
+	log.Debug("CheckClientUpStatus", "processes up", areClientsUp)
 
 	if areClientsUp {
 		n.SetClntStatus(core.Up)
@@ -210,54 +353,69 @@ func (n *NodeControl) CheckClientUpStatus(connectToClient bool) bool {
 	return areClientsUp
 }
 
-// fetchCurrentClientStatuses gets the current statuses of the blockchain client and privacy manager in parallel
-func (n *NodeControl) fetchCurrentClientStatuses() (bool, bool) {
-	var bcclntStatus bool
+// fetchCurrentClientStatuses gets the current statuses of all managed processes in parallel.
+// It returns whether all the processes are up and whether all the processes are down.
+func (n *NodeControl) fetchCurrentClientStatuses() (allUp bool, allDown bool) {
+	var statuses []bool
+	var mux sync.Mutex
 	var wg = sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		bcclntStatus = n.bcclntProcess.UpdateStatus()
-	}()
-
-	pmStatus := true
-	if n.WithPrivMan() {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			pmStatus = n.pmclntProcess.UpdateStatus()
-		}()
+	for _, level := range n.processes {
+		for _, mp := range level {
+			wg.Add(1)
+			go func(mp *managedProcess) {
+				defer wg.Done()
+				up := mp.process.UpdateStatus()
+				log.Debug("fetchCurrentClientStatuses", "process", mp.name, "up", up)
+				mux.Lock()
+				statuses = append(statuses, up)
+				mux.Unlock()
+			}(mp)
+		}
 	}
-
 	wg.Wait()
-	return bcclntStatus, pmStatus
+
+	allUp, allDown = true, true
+	for _, up := range statuses {
+		allUp = allUp && up
+		allDown = allDown && !up
+	}
+	return allUp, allDown
 }
 
 // IsNodeBusy returns error if the node hibernator is busy with shutdown/startup
 func (n *NodeControl) IsNodeBusy() error {
 	switch n.GetNodeStatus() {
 	case core.ShutdownInprogress:
-		return errors.New(core.NodeIsBeingShutdown)
+		return core.ErrNodeBeingShutdown
 	case core.StartupInprogress:
-		return errors.New(core.NodeIsBeingStarted)
+		return core.ErrNodeBeingStarted
 	case core.OK:
 		return nil
 	}
 	return nil
 }
 
-// Start starts blockchain client and privacy manager start/stop monitor and inactivity tracker
+// Start starts blockchain client and privacy manager start/stop monitor, inactivity tracker and watchdog
 func (n *NodeControl) Start() {
 	n.StartNodeMonitor()
 	n.startClientStatusMonitor()
 	n.im.Start()
+	if n.wd != nil {
+		n.wd.Start()
+	}
+	n.nh.Start()
 }
 
-// Stop stops blockchain client and privacy manager start/stop monitor and inactivity tracker
+// Stop stops blockchain client and privacy manager start/stop monitor, inactivity tracker and watchdog
 func (n *NodeControl) Stop() {
+	if n.wd != nil {
+		n.wd.Stop()
+	}
+	n.nh.Stop()
 	n.im.Stop()
 	n.stopCh <- true
 	n.clntStatMonStopCh <- true
+	n.persistState()
 }
 
 // ResetInactiveSyncTime resets inactivity time of the tracker
@@ -304,18 +462,18 @@ func (n *NodeControl) StartNodeMonitor() {
 		log.Info("StartNodeMonitor - node start/stop monitor started")
 		for {
 			select {
-			case <-n.stopClntCh:
-				log.Debug("StartNodeMonitor - request received to stop node")
-				if !n.StopClient() {
+			case trigger := <-n.stopClntCh:
+				log.Debug("StartNodeMonitor - request received to stop node", "trigger", trigger)
+				if !n.StopClient(trigger) {
 					log.Error("StartNodeMonitor - stopping failed")
 					n.stopClntCompleteCh <- false
 				} else {
 					log.Debug("StartNodeMonitor - stopping complete")
 					n.stopClntCompleteCh <- true
 				}
-			case <-n.startClntCh:
-				log.Debug("StartNodeMonitor - request received to start node")
-				if !n.StartClient() {
+			case trigger := <-n.startClntCh:
+				log.Debug("StartNodeMonitor - request received to start node", "trigger", trigger)
+				if !n.StartClient(trigger) {
 					log.Error("StartNodeMonitor - starting failed")
 					n.startClntCompleteCh <- false
 				} else {
@@ -330,12 +488,12 @@ func (n *NodeControl) StartNodeMonitor() {
 	}()
 }
 
-func (n *NodeControl) RequestStartClient() {
-	n.startClntCh <- true
+func (n *NodeControl) RequestStartClient(trigger history.Trigger) {
+	n.startClntCh <- trigger
 }
 
-func (n *NodeControl) RequestStopClient() {
-	n.stopClntCh <- true
+func (n *NodeControl) RequestStopClient(trigger history.Trigger) {
+	n.stopClntCh <- trigger
 }
 
 func (n *NodeControl) WaitStartClient() bool {
@@ -348,60 +506,198 @@ func (n *NodeControl) WaitStopClient() bool {
 	return status
 }
 
-func (n *NodeControl) PrepareClient() bool {
-	log.Debug("PrepareClient - starting node")
-	status := n.StartClient()
+func (n *NodeControl) PrepareClient(trigger history.Trigger) bool {
+	log.Debug("PrepareClient - starting node", "trigger", trigger)
+	status := n.StartClient(trigger)
 	log.Debug("PrepareClient - node start completed", "status", status)
 	return status
 }
 
-func (n *NodeControl) StopClient() bool {
+func (n *NodeControl) StopClient(trigger history.Trigger) bool {
 	defer n.startStopMux.Unlock()
 	n.startStopMux.Lock()
 
+	// wait for a random period before p2p validation to prevent multiple nodes
+	// going down at the same time
+	w := time.Duration(core.RandomInt(10, 5000)) * time.Millisecond
+	if err := n.validateShutdown(false, w); err != nil {
+		if refusalReason(err) == ReasonAlreadyDown {
+			log.Debug("StopClient - node is already down")
+			return true
+		}
+		log.Info("StopClient - node cannot be shutdown", "err", err)
+		n.recordRefusal(history.HibernationRefused, trigger, err)
+		return false
+	}
+	status := n.stopClient(trigger)
+	n.keepOrReleaseLease(status)
+	return status
+}
+
+// Hibernate validates that the node can be shutdown and then stops the blockchain client
+// and privacy manager in the background.
+// If force is true the consensus and peer validations are skipped.
+// It returns RefusalError if the node cannot be shutdown.
+func (n *NodeControl) Hibernate(force bool) error {
+	n.startStopMux.Lock()
+	if err := n.validateShutdown(force, 0); err != nil {
+		n.startStopMux.Unlock()
+		log.Info("Hibernate - node cannot be shutdown", "err", err)
+		n.recordRefusal(history.HibernationRefused, history.TriggerAdmin, err)
+		return err
+	}
+	go func() {
+		defer n.startStopMux.Unlock()
+		status := n.stopClient(history.TriggerAdmin)
+		n.keepOrReleaseLease(status)
+		log.Info("Hibernate - node shutdown completed", "status", status)
+	}()
+	return nil
+}
+
+// Wake starts the blockchain client and privacy manager in the background.
+// It returns RefusalError if the node hibernator is busy or the node is already up.
+func (n *NodeControl) Wake() error {
+	var err error
+	if busyErr := n.IsNodeBusy(); busyErr != nil {
+		err = newRefusalError(ReasonBusy, busyErr)
+	} else if n.IsClientUp() {
+		err = newRefusalError(ReasonAlreadyUp, errAlreadyUp)
+	}
+	if err != nil {
+		n.recordRefusal(history.WakeRefused, history.TriggerAdmin, err)
+		return err
+	}
+	// reset inactivity to prevent node being shutdown right after start up
+	n.ResetInactiveSyncTime()
+	go func() {
+		status := n.PrepareClient(history.TriggerAdmin)
+		log.Info("Wake - node start completed", "status", status)
+	}()
+	return nil
+}
+
+// validateShutdown checks whether the node can be shutdown. It should be called with startStopMux held.
+// If the checks pass node status is set to ShutdownInprogress.
+// If force is true consensus and peer validations are skipped.
+// If hibernation leases are configured a lease is acquired from a majority of peers before validating peers.
+// It is kept while the node is hibernated with keepOrReleaseLease and released with releaseLease once the node is up.
+// peerValidationWait is the maximum time to wait before acquiring the lease and validating peers. If it is set,
+// acquiring the lease is retried after another random wait of up to peerValidationWait.
+// It returns RefusalError if the node cannot be shutdown.
+func (n *NodeControl) validateShutdown(force bool, peerValidationWait time.Duration) error {
 	if !n.IsClientUp() {
-		log.Debug("StopClient - node is already down")
-		return true
+		return newRefusalError(ReasonAlreadyDown, errAlreadyDown)
 	}
 	if err := n.IsNodeBusy(); err != nil {
-		log.Error("StopClient - cannot be shutdown", "err", err)
-		return false
+		return newRefusalError(ReasonBusy, err)
+	}
+	if force {
+		log.Warn("validateShutdown - forced shutdown, skipping consensus and p2p validation")
+		n.SetNodeStatus(core.ShutdownInprogress)
+		return nil
 	}
-	var peersStatus []p2p.NodeStatusInfo
-	var err error
 
 	consensusNode, err := n.checkAndValidateConsensus()
 	if err != nil {
-		log.Info("StopClient - consensus check failed, node cannot be shutdown", "err", err)
+		log.Info("validateShutdown - consensus check failed, node cannot be shutdown", "err", err)
 		n.SetNodeStatus(core.OK)
-		return false
+		return newRefusalError(ReasonConsensus, err)
 	}
-	log.Info("StopClient - consensus check passed, node can be shutdown")
+	log.Info("validateShutdown - consensus check passed, node can be shutdown")
 
 	if consensusNode && !n.config.BasicConfig.DisableStrictMode {
 		// consensus node running in strict mode. node cannot be brought down
-		log.Info("StopClient - node hibernator running in strict mode. consensus node cannot be shut down")
-		return false
+		log.Info("validateShutdown - node hibernator running in strict mode. consensus node cannot be shut down")
+		return newRefusalError(ReasonStrictMode, errStrictMode)
 	}
 
 	// consensus is ok. check with network to prevent multiple nodes
 	// going down at the same time
-	w := core.RandomInt(10, 5000)
-	log.Info("StopClient - waiting for p2p validation try", "wait time in milliseconds", w)
-	time.Sleep(time.Duration(w) * time.Millisecond)
+	if peerValidationWait > 0 {
+		log.Info("validateShutdown - waiting for p2p validation try", "wait time in milliseconds", peerValidationWait.Milliseconds())
+		time.Sleep(peerValidationWait)
+	}
+	if n.config.BasicConfig.HibernationLease != nil {
+		if err := n.acquireLease(peerValidationWait); err != nil {
+			log.Info("validateShutdown - node cannot be shutdown, hibernation lease not acquired", "err", err)
+			return newRefusalError(ReasonLease, err)
+		}
+		log.Info("validateShutdown - hibernation lease acquired")
+	}
 	n.SetNodeStatus(core.ShutdownInprogress)
 
-	if peersStatus, err = n.nh.ValidatePeers(); err != nil {
+	peersStatus, err := n.nh.ValidatePeers()
+	if err != nil {
 		n.SetNodeStatus(core.OK)
-		log.Error("StopClient - node cannot be shutdown, p2p validation failed", "err", err)
-		return false
+		n.releaseLease()
+		log.Error("validateShutdown - node cannot be shutdown, p2p validation failed", "err", err)
+		return newRefusalError(ReasonPeers, err)
+	}
+	log.Info("validateShutdown - all checks passed for shutdown", "peerStatus", peersStatus)
+	return nil
+}
+
+// acquireLease acquires a hibernation lease from a majority of peers. Peers contending for the lease at the same
+// time may each prevent the others from acquiring it, so if retryWait is set acquiring is retried up to
+// leaseAcquireAttempts times after waiting a random time of up to retryWait.
+func (n *NodeControl) acquireLease(retryWait time.Duration) error {
+	attempts := 1
+	if retryWait > 0 {
+		attempts = leaseAcquireAttempts
 	}
-	log.Info("StopClient - all checks passed for shutdown", "peerStatus", peersStatus)
+	var err error
+	for i := 1; i <= attempts; i++ {
+		var id string
+		if id, err = n.nh.AcquireHibernationLease(); err == nil {
+			n.leaseMux.Lock()
+			n.leaseID = id
+			n.leaseMux.Unlock()
+			return nil
+		}
+		if i < attempts {
+			w := time.Duration(core.RandomInt(10, int(retryWait.Milliseconds()))) * time.Millisecond
+			log.Info("acquireLease - hibernation lease not acquired, retrying", "attempt", i, "wait time in milliseconds", w.Milliseconds(), "err", err)
+			time.Sleep(w)
+		}
+	}
+	return err
+}
 
-	bcStatus, pmStatus := n.stopProcesses()
-	if bcStatus && pmStatus {
-		log.Debug("StopClient - bcclnt and privman processes stopped")
+// keepOrReleaseLease keeps the hibernation lease acquired by validateShutdown, if any, while the node is
+// hibernated if it was shutdown. Otherwise the lease is released.
+func (n *NodeControl) keepOrReleaseLease(shutdown bool) {
+	if !shutdown {
+		n.releaseLease()
+		return
+	}
+	n.leaseMux.Lock()
+	defer n.leaseMux.Unlock()
+	if n.leaseID != "" {
+		n.nh.KeepHibernationLease(n.leaseID)
+	}
+}
+
+// releaseLease releases the hibernation lease acquired by validateShutdown, if any
+func (n *NodeControl) releaseLease() {
+	n.leaseMux.Lock()
+	defer n.leaseMux.Unlock()
+	if n.leaseID == "" {
+		return
+	}
+	n.nh.ReleaseHibernationLease(n.leaseID)
+	n.leaseID = ""
+}
+
+// stopClient stops all managed processes. It should be called with startStopMux held
+// after validateShutdown has passed.
+func (n *NodeControl) stopClient(trigger history.Trigger) bool {
+	start := time.Now()
+	err := n.stopProcesses()
+	if err == nil {
+		log.Debug("StopClient - processes stopped")
 		n.SetClntStatus(core.Down)
+		n.recordAction(history.Hibernated, trigger, start, nil)
 
 		// for IBFT and Clique, since we rely on block signed data
 		// do not want to mark the QNM status as OK immediately
@@ -412,11 +708,12 @@ func (n *NodeControl) StopClient() bool {
 		n.SetNodeStatus(core.OK)
 		log.Debug("StopClient", "nodeStatus", n.GetNodeStatus())
 	} else {
-		log.Error("StopClient - bcclnt and privman processes not stopped")
+		log.Error("StopClient - processes not stopped", "err", err)
+		n.recordAction(history.HibernationFailed, trigger, start, err)
 	}
-	// if stopping of blockchain client or privacy manager fails Status will remain as ShutdownInprogress and node hibernator will not process any requests from clients
-	// it will need some manual intervention to set it to the correct status
-	return bcStatus && pmStatus
+	// if stopping of any process fails Status will remain as ShutdownInprogress and node hibernator will not process any requests from clients
+	// it will need some manual intervention or the watchdog to set it to the correct status
+	return err == nil
 }
 
 func (n *NodeControl) checkAndValidateConsensus() (bool, error) {
@@ -432,32 +729,18 @@ func (n *NodeControl) checkAndValidateConsensus() (bool, error) {
 	return n.consensus.ValidateShutdown()
 }
 
-// stopProcesses stops blockchain client and privacy manager processes in parallel
-func (n *NodeControl) stopProcesses() (bool, bool) {
-	gs := true
-	ts := true
-	var wg = sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if n.bcclntProcess.Stop() != nil {
-			gs = false
+// stopProcesses stops the managed processes in the reverse dependency order. Processes in the same level are
+// stopped in parallel. If any process of a level fails to stop, the processes it depends on are not stopped.
+func (n *NodeControl) stopProcesses() error {
+	for i := len(n.processes) - 1; i >= 0; i-- {
+		if err := runLevel(n.processes[i], proc.Process.Stop); err != nil {
+			return fmt.Errorf("stop failed: %v", err)
 		}
-	}()
-	if n.pmclntProcess != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if n.pmclntProcess.Stop() != nil {
-				ts = false
-			}
-		}()
 	}
-	wg.Wait()
-	return gs, ts
+	return nil
 }
 
-func (n *NodeControl) StartClient() bool {
+func (n *NodeControl) StartClient(trigger history.Trigger) bool {
 	defer n.startStopMux.Unlock()
 	n.startStopMux.Lock()
 	// if the node status is down, enfornce client check to get the true client status
@@ -465,30 +748,159 @@ func (n *NodeControl) StartClient() bool {
 	// brought up in the backend bypassing QNM
 	if n.IsClientUp() || (!n.IsClientUp() && n.CheckClientUpStatus(true)) {
 		log.Debug("StartClient - node is already up")
+		n.releaseLease()
 		return true
 	}
+	start := time.Now()
 	n.SetNodeStatus(core.StartupInprogress)
-	gs := true
-	ts := true
-	if n.withPrivMan && n.pmclntProcess.Start() != nil {
-		gs = false
+	err := n.startProcesses()
+	if err == nil {
+		n.SetClntStatus(core.Up)
+		n.SetNodeStatus(core.OK)
+		n.releaseLease()
+		n.recordAction(history.Woken, trigger, start, nil)
+	} else {
+		log.Error("StartClient - processes not started", "err", err)
+		n.recordAction(history.WakeFailed, trigger, start, err)
 	}
-	if n.bcclntProcess.Start() != nil {
-		ts = false
+	// if start up of any process fails Status will remain as StartupInprogress and node hibernator will not process any requests from clients
+	// it will need some manual intervention or the watchdog to set it to the correct status
+	return err == nil
+}
+
+// startProcesses starts the managed processes in dependency order. Processes in the same level are
+// started in parallel. If any process of a level fails to start, the processes depending on it are not started.
+func (n *NodeControl) startProcesses() error {
+	for _, level := range n.processes {
+		if err := runLevel(level, proc.Process.Start); err != nil {
+			return fmt.Errorf("start failed: %v", err)
+		}
 	}
-	if gs && ts {
+	return nil
+}
+
+// runLevel runs action on all processes of level in parallel. It returns an error naming the processes
+// for which action failed.
+func runLevel(level []*managedProcess, action func(proc.Process) error) error {
+	var failed []string
+	var mux sync.Mutex
+	var wg = sync.WaitGroup{}
+	for _, mp := range level {
+		wg.Add(1)
+		go func(mp *managedProcess) {
+			defer wg.Done()
+			if err := action(mp.process); err != nil {
+				log.Error("runLevel - action failed", "process", mp.name, "err", err)
+				mux.Lock()
+				failed = append(failed, mp.name)
+				mux.Unlock()
+			}
+		}(mp)
+	}
+	wg.Wait()
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("processes %v", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// ResetStatus forces the node status to OK and sets the client status based on the current status
+// of all managed processes. It returns true if all are up.
+func (n *NodeControl) ResetStatus() bool {
+	allUp, _ := n.fetchCurrentClientStatuses()
+	log.Warn("ResetStatus - resetting node status", "nodeStatus", n.GetNodeStatus(), "processes up", allUp)
+	n.reconcileStatus(allUp, history.TriggerAdmin)
+	return allUp
+}
+
+// reconcileStatus sets the client status to match the given processes status and the node status to OK
+func (n *NodeControl) reconcileStatus(areClientsUp bool, trigger history.Trigger) {
+	oldStatus := n.GetNodeStatus()
+	if areClientsUp {
 		n.SetClntStatus(core.Up)
-		n.SetNodeStatus(core.OK)
+		n.releaseLease()
+	} else {
+		n.SetClntStatus(core.Down)
+	}
+	n.SetNodeStatus(core.OK)
+	n.history.Record(history.Event{
+		Type:    history.StatusReset,
+		Trigger: trigger,
+		Message: fmt.Sprintf("nodeStatus=%v clientUp=%v", oldStatus, areClientsUp),
+	})
+}
+
+// History returns the lifecycle events in the time range [since, until] matching any of the given types.
+// A zero since or until means the range is unbounded on that side. Empty types matches all types.
+func (n *NodeControl) History(since, until time.Time, types []history.EventType) []history.Event {
+	return n.history.Events(since, until, types)
+}
+
+// recordRefusal records a refused hibernate/wake request
+func (n *NodeControl) recordRefusal(eventType history.EventType, trigger history.Trigger, err error) {
+	e := history.Event{
+		Type:    eventType,
+		Trigger: trigger,
+		Reason:  refusalReason(err),
+		Message: err.Error(),
+	}
+	var r *RefusalError
+	if errors.As(err, &r) {
+		e.Message = r.Cause.Error()
+	}
+	n.history.Record(e)
+	if eventType == history.WakeRefused {
+		metrics.IncRefusals("wake", e.Reason)
+	} else {
+		metrics.IncRefusals("hibernate", e.Reason)
+	}
+}
+
+// recordAction records the result of a start/stop action that began at start
+func (n *NodeControl) recordAction(eventType history.EventType, trigger history.Trigger, start time.Time, err error) {
+	e := history.Event{
+		Type:     eventType,
+		Trigger:  trigger,
+		Duration: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		e.Message = err.Error()
+	}
+	n.history.Record(e)
+	switch eventType {
+	case history.Hibernated, history.HibernationFailed:
+		metrics.IncHibernations(string(trigger), err == nil)
+	case history.Woken, history.WakeFailed:
+		metrics.IncWakes(string(trigger), err == nil)
 	}
-	// if start up of blockchain client or privacy manager fails Status will remain as StartupInprogress and node hibernator will not process any requests from clients
-	// it will need some manual intervention to set it to the correct status
-	return gs && ts
 }
 
 func (n *NodeControl) PrepareNodeHibernatorForPrivateTx(privateFor []string) (bool, error) {
 	return n.nh.ValidatePeerPrivateTxStatus(privateFor)
 }
 
+// ExchangePeerRecords merges the peer records received from a peer and returns the peer records known to this node hibernator
+func (n *NodeControl) ExchangePeerRecords(records []p2p.PeerRecord) ([]p2p.PeerRecord, error) {
+	return n.nh.ExchangePeerRecords(records)
+}
+
+// GrantLease grants a hibernation lease to the peer holder if the configured number of concurrent leases
+// has not been granted yet
+func (n *NodeControl) GrantLease(holder, id string) p2p.LeaseReply {
+	return n.nh.GrantLease(holder, id)
+}
+
+// ReleaseLease releases the hibernation lease with the given id granted to the peer holder
+func (n *NodeControl) ReleaseLease(holder, id string) bool {
+	return n.nh.ReleaseLease(holder, id)
+}
+
+// PeerNameBySubject returns the name of the peer calling with a client certificate with the given subject common name
+func (n *NodeControl) PeerNameBySubject(subject string) (string, bool) {
+	return n.nh.PeerNameBySubject(subject)
+}
+
 func (n *NodeControl) GetInactivityTimeCount() int {
 	return n.im.GetInactivityTimeCount()
 }
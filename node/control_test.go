@@ -1,9 +1,13 @@
 package node
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/ConsenSys/quorum-hibernate/config"
 	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/process"
 	"github.com/stretchr/testify/require"
 )
@@ -66,10 +70,9 @@ func TestNodeControl_CheckClientUpStatus_IfCachedStatusIsDownThenOnlyUpdateCache
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			n := NodeControl{
-				clientStatus:  initialClientStatus,
-				bcclntProcess: tt.bcClient,
-				pmclntProcess: tt.pmClient,
-				withPrivMan:   true,
+				clientStatus: initialClientStatus,
+				processes:    newTestProcesses(tt.bcClient, tt.pmClient),
+				withPrivMan:  true,
 			}
 
 			got := n.CheckClientUpStatus(tt.forceConnectToClient)
@@ -126,10 +129,9 @@ func TestNodeControl_CheckClientUpStatus_IfCachedStatusIsUpThenAlwaysUpdateCache
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			n := NodeControl{
-				clientStatus:  initialClientStatus,
-				bcclntProcess: tt.bcClient,
-				pmclntProcess: tt.pmClient,
-				withPrivMan:   true,
+				clientStatus: initialClientStatus,
+				processes:    newTestProcesses(tt.bcClient, tt.pmClient),
+				withPrivMan:  true,
 			}
 
 			got := n.CheckClientUpStatus(forceConnectToClient)
@@ -140,6 +142,107 @@ func TestNodeControl_CheckClientUpStatus_IfCachedStatusIsUpThenAlwaysUpdateCache
 	}
 }
 
+func TestNodeControl_validateShutdown_Refused(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientStatus core.ClientStatus
+		nodeStatus   core.NodeStatus
+		force        bool
+		wantReason   string
+	}{
+		{
+			name:         "alreadyDown",
+			clientStatus: core.Down,
+			nodeStatus:   core.OK,
+			wantReason:   ReasonAlreadyDown,
+		},
+		{
+			name:         "busy",
+			clientStatus: core.Up,
+			nodeStatus:   core.StartupInprogress,
+			wantReason:   ReasonBusy,
+		},
+		{
+			name:         "busyEvenIfForced",
+			clientStatus: core.Up,
+			nodeStatus:   core.ShutdownInprogress,
+			force:        true,
+			wantReason:   ReasonBusy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NodeControl{
+				clientStatus: tt.clientStatus,
+				nodeStatus:   tt.nodeStatus,
+			}
+
+			err := n.validateShutdown(tt.force, 0)
+
+			require.IsType(t, &RefusalError{}, err)
+			require.Equal(t, tt.wantReason, refusalReason(err))
+			require.Equal(t, tt.nodeStatus, n.GetNodeStatus())
+		})
+	}
+}
+
+func TestNodeControl_validateShutdown_ForcedSkipsValidations(t *testing.T) {
+	// consensus and peer manager are not set, so the test would panic if validations were performed
+	n := NodeControl{
+		clientStatus: core.Up,
+		nodeStatus:   core.OK,
+	}
+
+	err := n.validateShutdown(true, 0)
+
+	require.NoError(t, err)
+	require.Equal(t, core.ShutdownInprogress, n.GetNodeStatus())
+}
+
+func TestNodeControl_Wake_Refused(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientStatus core.ClientStatus
+		nodeStatus   core.NodeStatus
+		wantReason   string
+	}{
+		{
+			name:         "alreadyUp",
+			clientStatus: core.Up,
+			nodeStatus:   core.OK,
+			wantReason:   ReasonAlreadyUp,
+		},
+		{
+			name:         "busy",
+			clientStatus: core.Down,
+			nodeStatus:   core.ShutdownInprogress,
+			wantReason:   ReasonBusy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NodeControl{
+				clientStatus: tt.clientStatus,
+				nodeStatus:   tt.nodeStatus,
+				history:      history.NewRecorder(10, ""),
+			}
+
+			err := n.Wake()
+
+			require.IsType(t, &RefusalError{}, err)
+			require.Equal(t, tt.wantReason, refusalReason(err))
+
+			events := n.History(time.Time{}, time.Time{}, nil)
+			require.Len(t, events, 1)
+			require.Equal(t, history.WakeRefused, events[0].Type)
+			require.Equal(t, history.TriggerAdmin, events[0].Trigger)
+			require.Equal(t, tt.wantReason, events[0].Reason)
+		})
+	}
+}
+
 type mockUpProcess struct{}
 
 func (p *mockUpProcess) Start() error {
@@ -175,3 +278,114 @@ func (p *mockDownProcess) UpdateStatus() bool {
 func (p *mockDownProcess) Status() bool {
 	return false
 }
+
+func TestNodeControl_startProcesses_DependencyOrder(t *testing.T) {
+	var calls []string
+	n := &NodeControl{
+		processes: [][]*managedProcess{
+			{{name: "enclave", process: &orderedProcess{name: "enclave", calls: &calls}}},
+			{{name: "privman", process: &orderedProcess{name: "privman", calls: &calls}}},
+			{{name: "bcclnt", process: &orderedProcess{name: "bcclnt", calls: &calls}}},
+		},
+	}
+
+	require.NoError(t, n.startProcesses())
+	require.Equal(t, []string{"start enclave", "start privman", "start bcclnt"}, calls)
+
+	calls = nil
+	require.NoError(t, n.stopProcesses())
+	require.Equal(t, []string{"stop bcclnt", "stop privman", "stop enclave"}, calls)
+}
+
+func TestNodeControl_startProcesses_StopsAtFailedLevel(t *testing.T) {
+	pm := &mockProcess{startErr: errors.New("failed to start")}
+	other := &mockProcess{}
+	bc := &mockProcess{}
+	n := &NodeControl{
+		processes: [][]*managedProcess{
+			{{name: "privman", process: pm}, {name: "ethsigner", process: other}},
+			{{name: "bcclnt", process: bc}},
+		},
+	}
+
+	err := n.startProcesses()
+
+	require.EqualError(t, err, "start failed: processes privman")
+	require.Equal(t, 1, other.startCalls)
+	require.Zero(t, bc.startCalls)
+}
+
+func TestSetProcesses_InvalidDependencies(t *testing.T) {
+	cfg := &config.Node{
+		BasicConfig: &config.Basic{
+			BlockchainClient: &config.BlockchainClient{
+				BcClntProcess: &config.Process{Name: "bcclnt", ControlType: "shell", DependsOn: []string{"signer"}},
+			},
+			Processes: []*config.Process{{Name: "signer", ControlType: "shell", DependsOn: []string{"bcclnt"}}},
+		},
+	}
+
+	require.Error(t, setProcesses(cfg, &NodeControl{}))
+}
+
+func TestNodeControl_fetchCurrentClientStatuses(t *testing.T) {
+	tests := []struct {
+		name                 string
+		statuses             []bool
+		wantAllUp, wantAllDn bool
+	}{
+		{name: "allUp", statuses: []bool{true, true, true}, wantAllUp: true},
+		{name: "allDown", statuses: []bool{false, false, false}, wantAllDn: true},
+		{name: "mixed", statuses: []bool{true, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var level []*managedProcess
+			for _, up := range tt.statuses {
+				level = append(level, &managedProcess{name: "p", process: &mockProcess{up: up}})
+			}
+			n := &NodeControl{processes: [][]*managedProcess{level}}
+
+			allUp, allDown := n.fetchCurrentClientStatuses()
+
+			require.Equal(t, tt.wantAllUp, allUp)
+			require.Equal(t, tt.wantAllDn, allDown)
+		})
+	}
+}
+
+// newTestProcesses returns the managed processes of a node with a blockchain client and privacy manager
+func newTestProcesses(bcClient, pmClient process.Process) [][]*managedProcess {
+	return [][]*managedProcess{
+		{{name: "privman", process: pmClient}},
+		{{name: "bcclnt", process: bcClient}},
+	}
+}
+
+// orderedProcess is a process which records the order of start and stop calls
+type orderedProcess struct {
+	name  string
+	up    bool
+	calls *[]string
+}
+
+func (p *orderedProcess) Start() error {
+	*p.calls = append(*p.calls, "start "+p.name)
+	p.up = true
+	return nil
+}
+
+func (p *orderedProcess) Stop() error {
+	*p.calls = append(*p.calls, "stop "+p.name)
+	p.up = false
+	return nil
+}
+
+func (p *orderedProcess) UpdateStatus() bool {
+	return p.up
+}
+
+func (p *orderedProcess) Status() bool {
+	return p.up
+}
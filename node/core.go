@@ -1,7 +1,11 @@
 package node
 
 import (
+	"time"
+
 	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
+	"github.com/ConsenSys/quorum-hibernate/p2p"
 )
 
 // TODO(cjh) for testing so methods can be mocked
@@ -10,7 +14,14 @@ type ControllerApiService interface {
 	IsClientUp() bool
 	ResetInactiveSyncTime()
 	IsNodeBusy() error
-	PrepareClient() bool
+	PrepareClient(trigger history.Trigger) bool
 	GetNodeStatus() core.NodeStatus
 	GetInactivityTimeCount() int
+	Hibernate(force bool) error
+	Wake() error
+	ResetStatus() bool
+	History(since, until time.Time, types []history.EventType) []history.Event
+	ExchangePeerRecords(records []p2p.PeerRecord) ([]p2p.PeerRecord, error)
+	GrantLease(holder, id string) p2p.LeaseReply
+	ReleaseLease(holder, id string) bool
 }
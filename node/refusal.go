@@ -0,0 +1,49 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons for which a request to hibernate or wake the node is refused
+const (
+	ReasonAlreadyDown = "alreadyDown" // node is already hibernated
+	ReasonAlreadyUp   = "alreadyUp"   // node is already awake
+	ReasonBusy        = "busy"        // node hibernator is busy starting or stopping the node
+	ReasonConsensus   = "consensus"   // consensus validation failed
+	ReasonStrictMode  = "strictMode"  // consensus node cannot be hibernated in strict mode
+	ReasonPeers       = "peers"       // peer validation failed
+	ReasonLease       = "lease"       // hibernation lease was not granted by a majority of peers
+)
+
+var (
+	errAlreadyDown = errors.New("node is already down")
+	errAlreadyUp   = errors.New("node is already up")
+	errStrictMode  = errors.New("node hibernator running in strict mode. consensus node cannot be shut down")
+)
+
+// RefusalError is returned when the node hibernator refuses to hibernate or wake the node.
+type RefusalError struct {
+	Reason string // one of the Reason constants
+	Cause  error  // cause of the refusal
+}
+
+func newRefusalError(reason string, cause error) error {
+	return &RefusalError{
+		Reason: reason,
+		Cause:  cause,
+	}
+}
+
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Reason, e.Cause)
+}
+
+// refusalReason returns the reason of err if it is a RefusalError, else it returns empty string
+func refusalReason(err error) string {
+	var r *RefusalError
+	if errors.As(err, &r) {
+		return r.Reason
+	}
+	return ""
+}
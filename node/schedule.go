@@ -0,0 +1,62 @@
+package node
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+// scheduleWindow is a time window in which the node is kept awake, hibernated or hibernated based on inactivity
+type scheduleWindow struct {
+	mode string         // one of config.ScheduleAwake, config.ScheduleHibernate or config.ScheduleInactivity
+	cron *core.CronSpec // the window is active during every minute matching the cron expression
+	loc  *time.Location // timezone in which cron is evaluated
+	// inactivity time in seconds after which the node is hibernated in a hibernate window.
+	// it prevents hibernating the node while it is serving requests.
+	inactivityTime int
+}
+
+// newScheduleWindows returns the schedule windows from config. Invalid windows are skipped.
+// inactivityTime is the inactivity time outside windows, used to default the inactivity time of hibernate windows.
+func newScheduleWindows(schedules []*config.Schedule, inactivityTime int) []scheduleWindow {
+	var windows []scheduleWindow
+	for _, s := range schedules {
+		c, err := core.ParseCron(s.Cron)
+		if err != nil {
+			log.Error("newScheduleWindows - invalid cron expression, skipping schedule", "cron", s.Cron, "err", err)
+			continue
+		}
+		loc, err := s.Location()
+		if err != nil {
+			log.Error("newScheduleWindows - invalid timezone, skipping schedule", "timezone", s.Timezone, "err", err)
+			continue
+		}
+		windows = append(windows, scheduleWindow{
+			mode:           strings.ToLower(s.Mode),
+			cron:           c,
+			loc:            loc,
+			inactivityTime: s.InactivityTimeOrDefault(inactivityTime),
+		})
+	}
+	return windows
+}
+
+// activeScheduleWindow returns the first window active at t.
+// If no window is active it returns a window applying the inactivity rule.
+func activeScheduleWindow(windows []scheduleWindow, t time.Time) scheduleWindow {
+	for _, w := range windows {
+		if w.cron.Matches(t.In(w.loc)) {
+			return w
+		}
+	}
+	return scheduleWindow{mode: config.ScheduleInactivity}
+}
+
+// activeScheduleMode returns the mode of the first window active at t.
+// If no window is active the inactivity rule applies.
+func activeScheduleMode(windows []scheduleWindow, t time.Time) string {
+	return activeScheduleWindow(windows, t).mode
+}
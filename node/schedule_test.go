@@ -0,0 +1,131 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveScheduleMode(t *testing.T) {
+	windows := newScheduleWindows([]*config.Schedule{
+		{Mode: "awake", Cron: "* 9-17 * * 1-5", Timezone: "America/New_York"},
+		{Mode: "Hibernate", Cron: "* * * * *"},
+	}, 60)
+
+	tests := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{
+			name: "awakeInWindowTimezone",
+			time: time.Date(2021, 3, 1, 14, 0, 0, 0, time.UTC), // Monday 09:00 in New York
+			want: config.ScheduleAwake,
+		},
+		{
+			name: "beforeAwakeInWindowTimezone",
+			time: time.Date(2021, 3, 1, 13, 59, 0, 0, time.UTC), // Monday 08:59 in New York
+			want: config.ScheduleHibernate,
+		},
+		{
+			name: "weekend",
+			time: time.Date(2021, 3, 6, 15, 0, 0, 0, time.UTC),
+			want: config.ScheduleHibernate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, activeScheduleMode(windows, tt.time))
+		})
+	}
+}
+
+func TestActiveScheduleMode_NoWindows(t *testing.T) {
+	require.Equal(t, config.ScheduleInactivity, activeScheduleMode(nil, time.Now()))
+}
+
+func TestNewScheduleWindows_SkipsInvalid(t *testing.T) {
+	windows := newScheduleWindows([]*config.Schedule{
+		{Mode: "awake", Cron: "* 25 * * *"},
+		{Mode: "awake", Cron: "* * * * *", Timezone: "Mars/Olympus_Mons"},
+		{Mode: "hibernate", Cron: "* * * * *"},
+	}, 60)
+
+	require.Len(t, windows, 1)
+	require.Equal(t, config.ScheduleHibernate, windows[0].mode)
+}
+
+func TestNewScheduleWindows_HibernateInactivityTime(t *testing.T) {
+	windows := newScheduleWindows([]*config.Schedule{
+		{Mode: "hibernate", Cron: "* * * * *"},
+		{Mode: "hibernate", Cron: "* * * * *", InactivityTime: 10},
+	}, 600)
+
+	require.Len(t, windows, 2)
+	require.Equal(t, 60, windows[0].inactivityTime)
+	require.Equal(t, 10, windows[1].inactivityTime)
+}
+
+func TestInactivityResyncMonitor_processTick_HibernateWindowUsesWindowInactivityTime(t *testing.T) {
+	n := &NodeControl{
+		config: &config.Node{
+			BasicConfig: &config.Basic{
+				InactivityTime: 600,
+				Schedules:      []*config.Schedule{{Mode: "hibernate", Cron: "* * * * *", InactivityTime: 10}},
+			},
+		},
+		clientStatus: core.Up,
+		nodeStatus:   core.OK,
+	}
+	n.im = NewInactivityResyncMonitor(n)
+	n.im.inactiveTimeCount = 9
+
+	n.im.processTick(time.Now())
+
+	require.Equal(t, 10, n.im.inactiveTimeCount)
+	require.Equal(t, config.ScheduleHibernate, n.im.scheduleMode)
+}
+
+func TestInactivityResyncMonitor_processTick_AwakeWindowResetsInactivity(t *testing.T) {
+	n := &NodeControl{
+		config: &config.Node{
+			BasicConfig: &config.Basic{
+				InactivityTime: 60,
+				Schedules:      []*config.Schedule{{Mode: "awake", Cron: "* * * * *"}},
+			},
+		},
+		clientStatus: core.Up,
+		nodeStatus:   core.OK,
+	}
+	n.im = NewInactivityResyncMonitor(n)
+	n.im.inactiveTimeCount = 30
+
+	n.im.processTick(time.Now())
+
+	require.Equal(t, 0, n.im.inactiveTimeCount)
+	require.Equal(t, config.ScheduleAwake, n.im.scheduleMode)
+}
+
+func TestInactivityResyncMonitor_processTick_TracksInactivityOutsideWindows(t *testing.T) {
+	n := &NodeControl{
+		config: &config.Node{
+			BasicConfig: &config.Basic{
+				InactivityTime: 60,
+				Schedules:      []*config.Schedule{{Mode: "awake", Cron: "* * 1 1 *"}},
+			},
+		},
+		clientStatus: core.Up,
+		nodeStatus:   core.OK,
+	}
+	n.im = NewInactivityResyncMonitor(n)
+	n.im.inactiveTimeCount = 30
+
+	n.im.processTick(time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC))
+
+	require.Equal(t, 31, n.im.inactiveTimeCount)
+	require.Equal(t, config.ScheduleInactivity, n.im.scheduleMode)
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+)
+
+const (
+	stateFileName      = "nodehibernator-state.json"
+	historyFileName    = "nodehibernator-history.json"
+	stateFlushInterval = 10 // interval in seconds at which the inactivity count is persisted
+)
+
+// NodeState represents the state of the node hibernator that is persisted across restarts.
+// It allows the node hibernator to resume the inactivity countdown and resync timer
+// from where it left off instead of re-waking or immediately re-hibernating the node.
+type NodeState struct {
+	NodeStatus        core.NodeStatus   `json:"nodeStatus"`        // status of node hibernator
+	ClientStatus      core.ClientStatus `json:"clientStatus"`      // combined status of blockchain client and privacy manager processes
+	InactiveTimeCount int               `json:"inactiveTimeCount"` // seconds the node has been inactive
+	ResyncTimerStart  time.Time         `json:"resyncTimerStart"`  // time at which the resync timer was last reset. zero if the timer has expired
+	UpdatedAt         time.Time         `json:"updatedAt"`         // time at which the state was persisted
+}
+
+// StateStore persists NodeState to a file in the data dir
+type StateStore struct {
+	file string
+	mux  sync.Mutex
+}
+
+// NewStateStore returns a StateStore that persists the state in dataDir.
+// dataDir is created if it does not exist.
+func NewStateStore(dataDir string) (*StateStore, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+	return &StateStore{file: filepath.Join(dataDir, stateFileName)}, nil
+}
+
+// Load reads the persisted state. It returns nil if no state has been persisted yet.
+func (s *StateStore) Load() (*NodeState, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	data, err := ioutil.ReadFile(s.file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st NodeState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Save persists the state. The state is written to a temporary file first and then renamed
+// so that a crash while writing does not leave a corrupted state file behind.
+func (s *StateStore) Save(st NodeState) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	st.UpdatedAt = time.Now()
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	tmp := s.file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file)
+}
@@ -0,0 +1,175 @@
+package node
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStateStore_CreatesDataDir(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "nested", "data")
+
+	_, err := NewStateStore(dataDir)
+	require.NoError(t, err)
+	require.DirExists(t, dataDir)
+}
+
+func TestStateStore_Load_NoPersistedState(t *testing.T) {
+	s, err := NewStateStore(t.TempDir())
+	require.NoError(t, err)
+
+	got, err := s.Load()
+
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestStateStore_SaveAndLoad(t *testing.T) {
+	s, err := NewStateStore(t.TempDir())
+	require.NoError(t, err)
+
+	resyncTimerStart := time.Now().Add(-time.Minute).Round(0)
+	want := NodeState{
+		NodeStatus:        core.OK,
+		ClientStatus:      core.Down,
+		InactiveTimeCount: 42,
+		ResyncTimerStart:  resyncTimerStart,
+	}
+
+	require.NoError(t, s.Save(want))
+
+	got, err := s.Load()
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, want.NodeStatus, got.NodeStatus)
+	require.Equal(t, want.ClientStatus, got.ClientStatus)
+	require.Equal(t, want.InactiveTimeCount, got.InactiveTimeCount)
+	require.True(t, want.ResyncTimerStart.Equal(got.ResyncTimerStart))
+	require.False(t, got.UpdatedAt.IsZero())
+}
+
+func TestNodeControl_restoreState(t *testing.T) {
+	resyncTimerStart := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name                  string
+		state                 NodeState
+		wantNodeStatus        core.NodeStatus
+		wantClientStatus      core.ClientStatus
+		wantInactiveTimeCount int
+		wantResyncTimerStart  time.Time
+	}{
+		{
+			name:                  "hibernatedNode",
+			state:                 NodeState{NodeStatus: core.OK, ClientStatus: core.Down, InactiveTimeCount: 30, ResyncTimerStart: resyncTimerStart},
+			wantNodeStatus:        core.OK,
+			wantClientStatus:      core.Down,
+			wantInactiveTimeCount: 30,
+			wantResyncTimerStart:  resyncTimerStart,
+		},
+		{
+			name:                  "busyStatusIsReset",
+			state:                 NodeState{NodeStatus: core.ShutdownInprogress, ClientStatus: core.Up, InactiveTimeCount: 10},
+			wantNodeStatus:        core.OK,
+			wantClientStatus:      core.Up,
+			wantInactiveTimeCount: 10,
+		},
+		{
+			name:                  "inactivityIsCappedToLimit",
+			state:                 NodeState{NodeStatus: core.OK, ClientStatus: core.Up, InactiveTimeCount: 500},
+			wantNodeStatus:        core.OK,
+			wantClientStatus:      core.Up,
+			wantInactiveTimeCount: 60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &NodeControl{
+				config:     &config.Node{BasicConfig: &config.Basic{InactivityTime: 60}},
+				nodeStatus: core.OK,
+			}
+			n.im = NewInactivityResyncMonitor(n)
+
+			n.restoreState(&tt.state)
+
+			require.Equal(t, tt.wantNodeStatus, n.GetNodeStatus())
+			require.Equal(t, tt.wantClientStatus, n.ClientStatus())
+			require.Equal(t, tt.wantInactiveTimeCount, n.GetInactivityTimeCount())
+			require.Equal(t, tt.wantResyncTimerStart, n.im.resyncTimerStart)
+		})
+	}
+}
+
+func TestInactivityResyncMonitor_initialResyncWait(t *testing.T) {
+	resyncTime := 10 * time.Minute
+
+	tests := []struct {
+		name             string
+		resyncTimerStart time.Time
+		wantMin, wantMax time.Duration
+	}{
+		{
+			name:    "notRestored",
+			wantMin: resyncTime,
+			wantMax: resyncTime,
+		},
+		{
+			name:             "restoredResumesRemainingTime",
+			resyncTimerStart: time.Now().Add(-4 * time.Minute),
+			wantMin:          5*time.Minute + 59*time.Second,
+			wantMax:          6 * time.Minute,
+		},
+		{
+			name:             "restoredAndExpired",
+			resyncTimerStart: time.Now().Add(-time.Hour),
+			wantMin:          0,
+			wantMax:          0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im := &InactivityResyncMonitor{resyncTimerStart: tt.resyncTimerStart}
+
+			got := im.initialResyncWait(resyncTime)
+
+			require.True(t, got >= tt.wantMin && got <= tt.wantMax, "got %v", got)
+			require.False(t, im.resyncTimerStart.IsZero())
+		})
+	}
+}
+
+func TestNodeControl_persistState_WhileMonitorTicks(t *testing.T) {
+	store, err := NewStateStore(t.TempDir())
+	require.NoError(t, err)
+	n := &NodeControl{
+		config:     &config.Node{BasicConfig: &config.Basic{InactivityTime: 1000}},
+		nodeStatus: core.OK,
+		stateStore: store,
+	}
+	n.im = NewInactivityResyncMonitor(n)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			n.im.tickInactivity(1000, history.TriggerInactivity)
+			n.im.setResyncTimerStart(time.Now())
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		n.SetClntStatus(core.Up)
+	}
+	<-done
+	n.persistState()
+
+	st, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 100, st.InactiveTimeCount)
+}
@@ -0,0 +1,118 @@
+package node
+
+import (
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+const watchdogPollInterval = 5 * time.Second
+
+// Watchdog recovers the node hibernator from a node status stuck in ShutdownInprogress or StartupInprogress.
+// This happens when stopping or starting the blockchain client or privacy manager fails.
+// Once the status has been stuck beyond the deadline, the watchdog checks the real status of the processes,
+// retries the failed action with backoff and finally reconciles the node status so that
+// the node hibernator can process requests again.
+type Watchdog struct {
+	nodeCtrl   *NodeControl
+	deadline   time.Duration // time after which a busy node status is considered stuck
+	retryLimit int           // number of times the failed action is retried
+	backoff    time.Duration // wait before the first retry, doubled for every subsequent retry
+	stopCh     chan bool
+}
+
+func NewWatchdog(qn *NodeControl, cfg *config.Watchdog) *Watchdog {
+	return &Watchdog{
+		nodeCtrl:   qn,
+		deadline:   time.Duration(cfg.Deadline) * time.Second,
+		retryLimit: cfg.RetryLimit,
+		backoff:    time.Duration(cfg.RetryBackoff) * time.Second,
+		stopCh:     make(chan bool),
+	}
+}
+
+func (w *Watchdog) Start() {
+	go w.monitor()
+}
+
+func (w *Watchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *Watchdog) monitor() {
+	timer := time.NewTicker(watchdogPollInterval)
+	defer timer.Stop()
+	log.Info("watchdog - started", "deadline", w.deadline)
+	for {
+		select {
+		case <-timer.C:
+			if w.isStuck() {
+				w.recover()
+			}
+		case <-w.stopCh:
+			log.Info("watchdog - stopped")
+			return
+		}
+	}
+}
+
+// isStuck returns true if the node status has been ShutdownInprogress or StartupInprogress for longer than the deadline
+func (w *Watchdog) isStuck() bool {
+	status, since := w.nodeCtrl.getNodeStatusWithTime()
+	if status != core.ShutdownInprogress && status != core.StartupInprogress {
+		return false
+	}
+	return time.Since(since) > w.deadline
+}
+
+// recover retries the failed stop/start action until the processes reach the expected status or the
+// retry limit is reached, and then reconciles the node status with the real status of the processes.
+func (w *Watchdog) recover() {
+	n := w.nodeCtrl
+	// wait for any start/stop action in progress to complete
+	n.startStopMux.Lock()
+	defer n.startStopMux.Unlock()
+
+	// the action in progress might have completed while waiting for the lock
+	if !w.isStuck() {
+		return
+	}
+	status := n.GetNodeStatus()
+	wantUp := status == core.StartupInprogress
+	log.Warn("watchdog - node status is stuck, recovering", "status", status, "deadline", w.deadline)
+
+	var allUp, allDown bool
+	for attempt := 1; ; attempt++ {
+		allUp, allDown = n.fetchCurrentClientStatuses()
+		log.Info("watchdog - current process status", "all up", allUp, "all down", allDown)
+		if wantUp && allUp {
+			log.Info("watchdog - processes are up")
+			break
+		}
+		if !wantUp && allDown {
+			log.Info("watchdog - processes are down")
+			break
+		}
+		if attempt > w.retryLimit {
+			log.Error("watchdog - retry limit reached", "retryLimit", w.retryLimit)
+			break
+		}
+		wait := w.backoff << uint(attempt-1)
+		log.Info("watchdog - retrying", "attempt", attempt, "wait", wait)
+		select {
+		case <-time.After(wait):
+		case <-w.stopCh:
+			return
+		}
+		if wantUp {
+			n.startProcesses()
+		} else {
+			n.stopProcesses()
+		}
+	}
+	n.reconcileStatus(allUp, history.TriggerWatchdog)
+	log.Info("watchdog - node status reconciled", "nodeStatus", n.GetNodeStatus(), "clientStatus", n.ClientStatus())
+}
@@ -0,0 +1,215 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWatchdog(n *NodeControl, retryLimit int) *Watchdog {
+	return &Watchdog{
+		nodeCtrl:   n,
+		deadline:   time.Minute,
+		retryLimit: retryLimit,
+		backoff:    time.Millisecond,
+		stopCh:     make(chan bool),
+	}
+}
+
+func TestWatchdog_isStuck(t *testing.T) {
+	tests := []struct {
+		name       string
+		nodeStatus core.NodeStatus
+		since      time.Duration
+		want       bool
+	}{
+		{
+			name:       "okStatus",
+			nodeStatus: core.OK,
+			since:      time.Hour,
+			want:       false,
+		},
+		{
+			name:       "consensusWait",
+			nodeStatus: core.ConsensusWait,
+			since:      time.Hour,
+			want:       false,
+		},
+		{
+			name:       "startupWithinDeadline",
+			nodeStatus: core.StartupInprogress,
+			since:      time.Second,
+			want:       false,
+		},
+		{
+			name:       "startupBeyondDeadline",
+			nodeStatus: core.StartupInprogress,
+			since:      time.Hour,
+			want:       true,
+		},
+		{
+			name:       "shutdownBeyondDeadline",
+			nodeStatus: core.ShutdownInprogress,
+			since:      time.Hour,
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &NodeControl{
+				nodeStatus:     tt.nodeStatus,
+				nodeStatusTime: time.Now().Add(-tt.since),
+			}
+			w := newTestWatchdog(n, 0)
+
+			require.Equal(t, tt.want, w.isStuck())
+		})
+	}
+}
+
+func TestWatchdog_recover(t *testing.T) {
+	tests := []struct {
+		name             string
+		nodeStatus       core.NodeStatus
+		bcClient         *mockProcess
+		pmClient         *mockProcess
+		retryLimit       int
+		wantClientStatus core.ClientStatus
+		wantStartCalls   int
+		wantStopCalls    int
+	}{
+		{
+			name:             "startupStuckButProcessesUp",
+			nodeStatus:       core.StartupInprogress,
+			bcClient:         &mockProcess{up: true},
+			pmClient:         &mockProcess{up: true},
+			retryLimit:       3,
+			wantClientStatus: core.Up,
+		},
+		{
+			name:             "startupRetrySucceeds",
+			nodeStatus:       core.StartupInprogress,
+			bcClient:         &mockProcess{up: false},
+			pmClient:         &mockProcess{up: true},
+			retryLimit:       3,
+			wantClientStatus: core.Up,
+			wantStartCalls:   1,
+		},
+		{
+			name:             "startupRetryLimitReached",
+			nodeStatus:       core.StartupInprogress,
+			bcClient:         &mockProcess{up: false, startErr: errors.New("failed to start")},
+			pmClient:         &mockProcess{up: true},
+			retryLimit:       3,
+			wantClientStatus: core.Down,
+			wantStartCalls:   3,
+		},
+		{
+			name:             "shutdownRetrySucceeds",
+			nodeStatus:       core.ShutdownInprogress,
+			bcClient:         &mockProcess{up: true},
+			pmClient:         &mockProcess{up: false},
+			retryLimit:       3,
+			wantClientStatus: core.Down,
+			wantStopCalls:    1,
+		},
+		{
+			name:             "shutdownNoRetries",
+			nodeStatus:       core.ShutdownInprogress,
+			bcClient:         &mockProcess{up: true, stopErr: errors.New("failed to stop")},
+			pmClient:         &mockProcess{up: true},
+			retryLimit:       0,
+			wantClientStatus: core.Up,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &NodeControl{
+				nodeStatus:     tt.nodeStatus,
+				nodeStatusTime: time.Now().Add(-time.Hour),
+				processes:      newTestProcesses(tt.bcClient, tt.pmClient),
+				withPrivMan:    true,
+			}
+			w := newTestWatchdog(n, tt.retryLimit)
+
+			w.recover()
+
+			require.Equal(t, core.OK, n.GetNodeStatus())
+			require.Equal(t, tt.wantClientStatus, n.ClientStatus())
+			require.Equal(t, tt.wantStartCalls, tt.bcClient.startCalls)
+			require.Equal(t, tt.wantStopCalls, tt.bcClient.stopCalls)
+		})
+	}
+}
+
+func TestWatchdog_recover_NotStuck(t *testing.T) {
+	bcClient := &mockProcess{up: false}
+	n := &NodeControl{
+		nodeStatus:     core.OK,
+		nodeStatusTime: time.Now().Add(-time.Hour),
+		clientStatus:   core.Up,
+		processes:      [][]*managedProcess{{{name: "bcclnt", process: bcClient}}},
+	}
+	w := newTestWatchdog(n, 3)
+
+	w.recover()
+
+	require.Equal(t, core.OK, n.GetNodeStatus())
+	require.Equal(t, core.Up, n.ClientStatus())
+	require.Zero(t, bcClient.startCalls)
+}
+
+func TestNodeControl_ResetStatus(t *testing.T) {
+	n := &NodeControl{
+		nodeStatus:   core.StartupInprogress,
+		clientStatus: core.Down,
+		processes:    newTestProcesses(&mockProcess{up: true}, &mockProcess{up: true}),
+		withPrivMan:  true,
+	}
+
+	got := n.ResetStatus()
+
+	require.True(t, got)
+	require.Equal(t, core.OK, n.GetNodeStatus())
+	require.Equal(t, core.Up, n.ClientStatus())
+}
+
+// mockProcess is a process whose start and stop can be made to fail
+type mockProcess struct {
+	up         bool
+	startErr   error
+	stopErr    error
+	startCalls int
+	stopCalls  int
+}
+
+func (p *mockProcess) Start() error {
+	p.startCalls++
+	if p.startErr != nil {
+		return p.startErr
+	}
+	p.up = true
+	return nil
+}
+
+func (p *mockProcess) Stop() error {
+	p.stopCalls++
+	if p.stopErr != nil {
+		return p.stopErr
+	}
+	p.up = false
+	return nil
+}
+
+func (p *mockProcess) UpdateStatus() bool {
+	return p.up
+}
+
+func (p *mockProcess) Status() bool {
+	return p.up
+}
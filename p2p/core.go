@@ -1,8 +1,11 @@
 package p2p
 
 import (
+	"sync"
+
 	"github.com/ConsenSys/quorum-hibernate/config"
 	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 )
 
 type PeerPrivateTxPrepResult struct {
@@ -17,6 +20,13 @@ type PrivateTxPrepStatus struct {
 type PeerManager struct {
 	cfg          *config.Node
 	configReader config.PeersReader
+	discovery    *Discovery        // discovery of peers. nil if the peers are read from the peers config file
+	history      *history.Recorder // records peer events. nil if events are not recorded
+	leases       *leaseTable       // hibernation leases granted by this node hibernator
+	health       *peerHealthCache  // health of the peers called by this node hibernator
+	renewStopCh  chan struct{}     // closed to stop renewing the hibernation lease held by this node hibernator. nil if not renewing
+	renewDoneCh  chan struct{}     // closed once renewing the hibernation lease has stopped
+	renewMux     sync.Mutex        // lock for renewStopCh and renewDoneCh
 }
 
 type PeerNodeStatusResult struct {
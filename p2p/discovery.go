@@ -0,0 +1,379 @@
+package p2p
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
+)
+
+const (
+	PeersMethod     = `{"jsonrpc":"2.0", "method":"node.Peers", "params":[%s], "id":77}`
+	peersMethodName = "node.Peers"
+
+	// peersCacheFileName is the file in the data dir in which discovered peer records are cached
+	peersCacheFileName = "nodehibernator-peers.json"
+)
+
+var ErrDiscoveryDisabled = errors.New("peer discovery is not enabled")
+
+// PeerRecord is the signed record of a node hibernator exchanged through peer discovery
+type PeerRecord struct {
+	Name           string `json:"name"`              // name of the node hibernator
+	PrivManKey     string `json:"privacyManagerKey"` // privacy manager key managed by the node hibernator
+	RpcUrl         string `json:"rpcUrl"`            // RPC url of the node hibernator
+	TLSFingerprint string `json:"tlsFingerprint"`    // hex encoded sha256 fingerprint of the RPC server certificate. empty if the RPC server does not use tls
+	Timestamp      int64  `json:"timestamp"`         // unix time at which the record was signed. newer records replace older ones
+	PublicKey      string `json:"publicKey"`         // hex encoded ed25519 public key of the node hibernator
+	Signature      string `json:"signature"`         // hex encoded ed25519 signature of the other fields
+}
+
+type PeersArgs struct {
+	From    string       `json:"from"`    // name of the caller
+	Records []PeerRecord `json:"records"` // peer records known to the caller
+}
+
+type PeersReply struct {
+	Records []PeerRecord
+}
+
+type PeerRecordsResult struct {
+	Result PeersReply `json:"result"`
+	Error  error      `json:"error"`
+}
+
+// signedBytes returns the bytes of the record which are signed
+func (r PeerRecord) signedBytes() []byte {
+	r.Signature = ""
+	b, _ := json.Marshal(r)
+	return b
+}
+
+// verify returns nil if the record is signed by its public key
+func (r PeerRecord) verify() error {
+	pub, err := config.ParsePublicKey(r.PublicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(r.Signature)
+	if err != nil || !ed25519.Verify(pub, r.signedBytes(), sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// Discovery maintains the peers of this node hibernator by exchanging signed peer records with other node
+// hibernators through the node.Peers RPC. Only records signed by trusted keys are accepted. The records are cached
+// in the data dir so that the peers are known on restart before any exchange has completed.
+type Discovery struct {
+	cfg       *config.Discovery
+	self      PeerRecord
+	trusted   map[string]bool // trusted public keys
+	cacheFile string
+	records   map[string]PeerRecord // records of other node hibernators by public key
+	mux       sync.Mutex            // lock for records
+	stopCh    chan bool
+}
+
+// NewDiscovery returns the discovery for the node hibernator with the given config and loads the cached peer records
+func NewDiscovery(cfg *config.Node) (*Discovery, error) {
+	dc := cfg.BasicConfig.Discovery
+	d := &Discovery{
+		cfg:       dc,
+		trusted:   make(map[string]bool),
+		cacheFile: filepath.Join(cfg.BasicConfig.DataDir, peersCacheFileName),
+		records:   make(map[string]PeerRecord),
+		stopCh:    make(chan bool),
+	}
+	for _, k := range dc.TrustedKeys {
+		d.trusted[strings.ToLower(k)] = true
+	}
+
+	fingerprint, err := serverTLSFingerprint(cfg.BasicConfig.Server)
+	if err != nil {
+		return nil, err
+	}
+	pub := dc.PrivateKey.Public().(ed25519.PublicKey)
+	d.self = PeerRecord{
+		Name:           cfg.BasicConfig.Name,
+		PrivManKey:     dc.PrivManKey,
+		RpcUrl:         dc.RpcUrl,
+		TLSFingerprint: fingerprint,
+		Timestamp:      time.Now().Unix(),
+		PublicKey:      hex.EncodeToString(pub),
+	}
+	d.self.Signature = hex.EncodeToString(ed25519.Sign(dc.PrivateKey, d.self.signedBytes()))
+	log.Info("Discovery - peer record signed", "name", d.self.Name, "publicKey", d.self.PublicKey)
+
+	if err := d.loadCache(); err != nil {
+		log.Warn("Discovery - loading cached peer records failed", "file", d.cacheFile, "err", err)
+	}
+	return d, nil
+}
+
+// serverTLSFingerprint returns the fingerprint of the certificate of the RPC server, or "" if it does not use tls
+func serverTLSFingerprint(srv *config.RPCServer) (string, error) {
+	if srv == nil || srv.TLSConfig == nil {
+		return "", nil
+	}
+	tlsCfg := srv.TLSConfig.TlsCfg
+	if tlsCfg == nil {
+		var err error
+		if tlsCfg, err = srv.TLSConfig.TLSConfig(); err != nil {
+			return "", err
+		}
+	}
+	if len(tlsCfg.Certificates) == 0 || len(tlsCfg.Certificates[0].Certificate) == 0 {
+		return "", errors.New("RPC server certificate not found")
+	}
+	sum := sha256.Sum256(tlsCfg.Certificates[0].Certificate[0])
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Records returns the peer records known to this node hibernator, including its own record
+func (d *Discovery) Records() []PeerRecord {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	records := []PeerRecord{d.self}
+	for _, r := range d.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+	return records
+}
+
+// Merge adds the valid records signed by trusted keys which are newer than the known records of the same node
+// hibernators. It returns true if any record was added.
+func (d *Discovery) Merge(records []PeerRecord) bool {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	changed := false
+	for _, r := range records {
+		key, err := d.accept(r)
+		if err != nil {
+			log.Debug("Discovery - peer record ignored", "name", r.Name, "publicKey", r.PublicKey, "err", err)
+			continue
+		}
+		d.records[key] = r
+		changed = true
+		log.Info("Discovery - peer record added", "name", r.Name, "rpcUrl", r.RpcUrl, "publicKey", r.PublicKey)
+	}
+	if changed {
+		if err := d.saveCache(); err != nil {
+			log.Error("Discovery - caching peer records failed", "file", d.cacheFile, "err", err)
+		}
+	}
+	return changed
+}
+
+// accept returns the key of the node hibernator of r if r should replace its known record.
+// It must be called with the lock held.
+func (d *Discovery) accept(r PeerRecord) (string, error) {
+	key := strings.ToLower(r.PublicKey)
+	if key == d.self.PublicKey {
+		return "", errors.New("own record")
+	}
+	if !d.trusted[key] {
+		return "", errors.New("public key is not trusted")
+	}
+	if err := r.verify(); err != nil {
+		return "", err
+	}
+	if err := (config.Peer{Name: r.Name, PrivManKey: r.PrivManKey, RpcUrl: r.RpcUrl}).IsValid(); err != nil {
+		return "", err
+	}
+	if old, ok := d.records[key]; ok && old.Timestamp >= r.Timestamp {
+		return "", errors.New("record is not newer than the known record")
+	}
+	if r.Name == d.self.Name {
+		return "", errors.New("name is not unique")
+	}
+	for k, o := range d.records {
+		if k != key && o.Name == r.Name {
+			return "", errors.New("name is not unique")
+		}
+	}
+	return key, nil
+}
+
+// Peers returns the peers discovered so far, excluding this node hibernator
+func (d *Discovery) Peers() config.PeerArr {
+	var base *tls.Config
+	if d.cfg.TLSConfig != nil {
+		base = d.cfg.TLSConfig.TlsCfg
+	}
+	var peers config.PeerArr
+	for _, r := range d.Records() {
+		if r.PublicKey != d.self.PublicKey {
+			peers = append(peers, r.peer(base))
+		}
+	}
+	return peers
+}
+
+// peer returns the peer config for the record. If the record has a tls fingerprint the RPC server certificate is
+// pinned to it, else base is used for https urls.
+func (r PeerRecord) peer(base *tls.Config) *config.Peer {
+	p := &config.Peer{Name: r.Name, PrivManKey: r.PrivManKey, RpcUrl: r.RpcUrl}
+	if r.TLSFingerprint != "" {
+		p.TLSConfig = &config.ClientTLS{TlsCfg: pinnedTLSConfig(base, r.TLSFingerprint)}
+	} else if base != nil && strings.HasPrefix(strings.ToLower(r.RpcUrl), "https") {
+		p.TLSConfig = &config.ClientTLS{TlsCfg: base}
+	}
+	return p
+}
+
+// pinnedTLSConfig returns a copy of base which only accepts a server certificate with the given sha256 fingerprint
+func pinnedTLSConfig(base *tls.Config, fingerprint string) *tls.Config {
+	c := &tls.Config{}
+	if base != nil {
+		c = base.Clone()
+	}
+	// the certificate is verified by its fingerprint instead of its chain
+	c.InsecureSkipVerify = true
+	c.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("no server certificate")
+		}
+		sum := sha256.Sum256(rawCerts[0])
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), fingerprint) {
+			return errors.New("server certificate does not match tls fingerprint")
+		}
+		return nil
+	}
+	return c
+}
+
+// Start exchanges peer records with the bootstrap and known peers now and then periodically until Stop is called
+func (d *Discovery) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(d.cfg.Interval) * time.Second)
+		defer ticker.Stop()
+		d.exchange()
+		for {
+			select {
+			case <-ticker.C:
+				d.exchange()
+			case <-d.stopCh:
+				log.Info("Discovery - stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (d *Discovery) Stop() {
+	close(d.stopCh)
+}
+
+// exchange sends the known peer records to the bootstrap and known peers and merges the records they reply with
+func (d *Discovery) exchange() {
+	targets := make(map[string]*config.Peer)
+	var base *tls.Config
+	if d.cfg.TLSConfig != nil {
+		base = d.cfg.TLSConfig.TlsCfg
+	}
+	for _, u := range d.cfg.BootstrapPeers {
+		targets[u] = &config.Peer{Name: u, RpcUrl: u, TLSConfig: &config.ClientTLS{TlsCfg: base}}
+	}
+	for _, p := range d.Peers() {
+		// known peers replace bootstrap peers with the same url as their certificate can be pinned
+		targets[p.RpcUrl] = p
+	}
+	delete(targets, d.self.RpcUrl)
+
+	args, _ := json.Marshal(PeersArgs{From: d.self.Name, Records: d.Records()})
+	req := []byte(fmt.Sprintf(PeersMethod, args))
+	var wg sync.WaitGroup
+	for _, p := range targets {
+		wg.Add(1)
+		go func(p *config.Peer) {
+			defer wg.Done()
+			var res PeerRecordsResult
+			if err := d.call(p, req, &res); err != nil {
+				log.Warn("Discovery - exchanging peer records failed", "peer", p.Name, "rpcUrl", p.RpcUrl, "err", err)
+				return
+			}
+			if res.Error != nil {
+				log.Warn("Discovery - exchanging peer records failed", "peer", p.Name, "rpcUrl", p.RpcUrl, "err", res.Error)
+				return
+			}
+			d.Merge(res.Result.Records)
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (d *Discovery) call(p *config.Peer, req []byte, res interface{}) error {
+	var client *http.Client
+	if p.TLSConfig != nil && p.TLSConfig.TlsCfg != nil {
+		client = core.NewHttpClient(p.TLSConfig.TlsCfg)
+	}
+	start := time.Now()
+	err := core.CallRPC(client, p.RpcUrl, req, res)
+	metrics.ObservePeerRPC(p.Name, peersMethodName, start, err)
+	return err
+}
+
+// loadCache merges the cached peer records
+func (d *Discovery) loadCache() error {
+	data, err := ioutil.ReadFile(d.cacheFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var records []PeerRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return err
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	for _, r := range records {
+		if key, err := d.accept(r); err == nil {
+			d.records[key] = r
+		}
+	}
+	log.Info("Discovery - loaded cached peer records", "file", d.cacheFile, "count", len(d.records))
+	return nil
+}
+
+// saveCache writes the peer records to the cache file. It must be called with the lock held.
+func (d *Discovery) saveCache() error {
+	records := make([]PeerRecord, 0, len(d.records))
+	for _, r := range d.records {
+		records = append(records, r)
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.cacheFile), 0700); err != nil {
+		return err
+	}
+	tmp := d.cacheFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.cacheFile)
+}
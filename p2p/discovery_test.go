@@ -0,0 +1,161 @@
+package p2p
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKey returns a private key derived from b and its hex encoded public key
+func newTestKey(b byte) (ed25519.PrivateKey, string) {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = b
+	key := ed25519.NewKeyFromSeed(seed)
+	return key, hex.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+func newTestDiscovery(t *testing.T, name string, key ed25519.PrivateKey, dataDir string, trustedKeys ...string) *Discovery {
+	d, err := NewDiscovery(&config.Node{
+		BasicConfig: &config.Basic{
+			Name:    name,
+			DataDir: dataDir,
+			Discovery: &config.Discovery{
+				RpcUrl:      "http://" + name,
+				PrivManKey:  name + "key",
+				TrustedKeys: trustedKeys,
+				Interval:    60,
+				PrivateKey:  key,
+			},
+		},
+	})
+	require.NoError(t, err)
+	return d
+}
+
+func TestPeerRecord_Verify(t *testing.T) {
+	key, _ := newTestKey(1)
+	d := newTestDiscovery(t, "a", key, t.TempDir())
+
+	r := d.self
+	require.NoError(t, r.verify())
+
+	r.RpcUrl = "http://attacker"
+	require.EqualError(t, r.verify(), "invalid signature")
+}
+
+func TestDiscovery_Merge(t *testing.T) {
+	keyA, pubA := newTestKey(1)
+	keyB, pubB := newTestKey(2)
+	keyC, _ := newTestKey(3)
+	a := newTestDiscovery(t, "a", keyA, t.TempDir(), pubB)
+	b := newTestDiscovery(t, "b", keyB, t.TempDir(), pubA)
+	c := newTestDiscovery(t, "c", keyC, t.TempDir(), pubA)
+
+	tampered := b.self
+	tampered.RpcUrl = "http://attacker"
+	require.False(t, a.Merge([]PeerRecord{a.self, c.self, tampered}), "own, untrusted and tampered records must be ignored")
+	require.Empty(t, a.Peers())
+
+	require.True(t, a.Merge(b.Records()))
+	require.Equal(t, config.PeerArr{{Name: "b", PrivManKey: "bkey", RpcUrl: "http://b"}}, a.Peers())
+
+	require.False(t, a.Merge(b.Records()), "known records must be ignored")
+
+	older := b.self
+	older.Timestamp--
+	older.Signature = hex.EncodeToString(ed25519.Sign(keyB, older.signedBytes()))
+	require.False(t, a.Merge([]PeerRecord{older}), "older records must be ignored")
+
+	newer := b.self
+	newer.Timestamp++
+	newer.RpcUrl = "http://b2"
+	newer.Signature = hex.EncodeToString(ed25519.Sign(keyB, newer.signedBytes()))
+	require.True(t, a.Merge([]PeerRecord{newer}))
+	require.Equal(t, "http://b2", a.Peers()[0].RpcUrl)
+}
+
+func TestDiscovery_Cache(t *testing.T) {
+	keyA, pubA := newTestKey(1)
+	keyB, pubB := newTestKey(2)
+	dataDir := t.TempDir()
+	a := newTestDiscovery(t, "a", keyA, dataDir, pubB)
+	b := newTestDiscovery(t, "b", keyB, t.TempDir(), pubA)
+	require.True(t, a.Merge(b.Records()))
+
+	restarted := newTestDiscovery(t, "a", keyA, dataDir, pubB)
+
+	require.Equal(t, a.Peers(), restarted.Peers())
+}
+
+func TestDiscovery_Exchange(t *testing.T) {
+	keyA, pubA := newTestKey(1)
+	keyB, pubB := newTestKey(2)
+	keyC, pubC := newTestKey(3)
+	a := newTestDiscovery(t, "a", keyA, t.TempDir(), pubB, pubC)
+	b := newTestDiscovery(t, "b", keyB, t.TempDir(), pubA, pubC)
+	c := newTestDiscovery(t, "c", keyC, t.TempDir(), pubA, pubB)
+	// b already knows c
+	require.True(t, b.Merge(c.Records()))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params []PeersArgs `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		b.Merge(req.Params[0].Records)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 77, "result": PeersReply{Records: b.Records()}})
+	}))
+	defer srv.Close()
+	a.cfg.BootstrapPeers = []string{srv.URL}
+
+	a.exchange()
+
+	var names []string
+	for _, p := range a.Peers() {
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{"b", "c"}, names, "peers known to the bootstrap peer must be discovered")
+	require.Len(t, b.Peers(), 2, "the bootstrap peer must discover the caller")
+}
+
+func TestPinnedTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":77,"result":{}}`))
+	}))
+	defer srv.Close()
+	sum := sha256.Sum256(srv.Certificate().Raw)
+
+	var res map[string]interface{}
+	client := core.NewHttpClient(pinnedTLSConfig(nil, hex.EncodeToString(sum[:])))
+	require.NoError(t, core.CallRPC(client, srv.URL, []byte(`{}`), &res))
+
+	client = core.NewHttpClient(pinnedTLSConfig(nil, hex.EncodeToString(make([]byte, sha256.Size))))
+	require.Error(t, core.CallRPC(client, srv.URL, []byte(`{}`), &res))
+}
+
+func TestNewPeerManager_DiscoveryError(t *testing.T) {
+	key, _ := newTestKey(1)
+	_, err := NewPeerManager(&config.Node{
+		BasicConfig: &config.Basic{
+			Name:    "a",
+			DataDir: t.TempDir(),
+			Server: &config.RPCServer{
+				TLSConfig: &config.ServerTLS{KeyFile: "notexist.key", CertFile: "notexist.crt"},
+			},
+			Discovery: &config.Discovery{
+				RpcUrl:     "http://a",
+				Interval:   60,
+				PrivateKey: key,
+			},
+		},
+	}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package p2p
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+var errCircuitOpen = errors.New("peer is unreachable, call skipped")
+
+// peerHealth is the health of a peer as observed by the calls made to it
+type peerHealth struct {
+	failures         int       // number of consecutive failed calls
+	unreachableSince time.Time // time of the first of the consecutive failed calls. zero if the last call succeeded
+	openUntil        time.Time // time until which calls to the peer are skipped
+}
+
+// peerHealthCache tracks the health of peers and acts as a circuit breaker per peer. Once threshold consecutive
+// calls to a peer have failed, calls to it are skipped for openTime. The next call after that is made to the peer
+// and reopens the circuit if it fails.
+type peerHealthCache struct {
+	threshold int
+	openTime  time.Duration
+	peers     map[string]*peerHealth // health by peer name
+	now       func() time.Time
+	mux       sync.Mutex
+}
+
+func newPeerHealthCache(cfg *config.UnreachablePeers) *peerHealthCache {
+	if cfg == nil {
+		cfg = &config.UnreachablePeers{}
+	}
+	return &peerHealthCache{
+		threshold: cfg.FailureThresholdOrDefault(),
+		openTime:  time.Duration(cfg.OpenTimeOrDefault()) * time.Second,
+		peers:     make(map[string]*peerHealth),
+		now:       time.Now,
+	}
+}
+
+// allow returns errCircuitOpen if calls to the peer are skipped
+func (c *peerHealthCache) allow(peer string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if h, ok := c.peers[peer]; ok && c.now().Before(h.openUntil) {
+		return errCircuitOpen
+	}
+	return nil
+}
+
+// record records the result of a call to the peer
+func (c *peerHealthCache) record(peer string, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if err == nil {
+		if h, ok := c.peers[peer]; ok && h.failures > 0 {
+			log.Info("peerHealthCache - peer is reachable again", "peer", peer)
+		}
+		delete(c.peers, peer)
+		return
+	}
+	h, ok := c.peers[peer]
+	if !ok {
+		h = &peerHealth{unreachableSince: c.now()}
+		c.peers[peer] = h
+	}
+	h.failures++
+	if h.failures >= c.threshold {
+		h.openUntil = c.now().Add(c.openTime)
+		log.Warn("peerHealthCache - skipping calls to unreachable peer", "peer", peer, "failures", h.failures, "until", h.openUntil)
+	}
+}
+
+// unreachableFor returns the time for which the peer has been unreachable. zero if the last call succeeded
+func (c *peerHealthCache) unreachableFor(peer string) time.Duration {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if h, ok := c.peers[peer]; ok {
+		return c.now().Sub(h.unreachableSince)
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package p2p
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerHealthCache_CircuitBreaker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	c := newPeerHealthCache(&config.UnreachablePeers{FailureThreshold: 2, OpenTime: 30})
+	c.now = func() time.Time { return now }
+	callErr := errors.New("connection refused")
+
+	require.NoError(t, c.allow("a"))
+	c.record("a", callErr)
+	require.NoError(t, c.allow("a"), "calls must be made until the threshold is reached")
+
+	now = now.Add(10 * time.Second)
+	c.record("a", callErr)
+	require.Equal(t, errCircuitOpen, c.allow("a"))
+	require.NoError(t, c.allow("b"))
+	require.Equal(t, 10*time.Second, c.unreachableFor("a"))
+
+	now = now.Add(30 * time.Second)
+	require.NoError(t, c.allow("a"), "a call must be made once openTime has passed")
+	c.record("a", callErr)
+	require.Equal(t, errCircuitOpen, c.allow("a"), "circuit must reopen if the call fails")
+	require.Equal(t, 40*time.Second, c.unreachableFor("a"))
+
+	now = now.Add(30 * time.Second)
+	c.record("a", nil)
+	require.NoError(t, c.allow("a"))
+	require.Zero(t, c.unreachableFor("a"))
+}
+
+func newStatusPeer(status NodeStatusInfo) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 77, "result": status})
+	}))
+}
+
+func TestPeerManager_ValidatePeers_UnreachablePeers(t *testing.T) {
+	ok := newStatusPeer(NodeStatusInfo{Status: core.OK})
+	defer ok.Close()
+
+	tests := []struct {
+		name             string
+		unreachablePeers *config.UnreachablePeers
+		peers            []string // names of the reachable peers. peers u1 and u2 are unreachable
+		unreachableFor   time.Duration
+		wantErrMsg       string
+	}{
+		{
+			name:       "all by default",
+			peers:      []string{"a", "b"},
+			wantErrMsg: "some node managers did not respond",
+		},
+		{
+			name:             "down",
+			unreachablePeers: &config.UnreachablePeers{Policy: "down"},
+		},
+		{
+			name:             "quorum reached",
+			unreachablePeers: &config.UnreachablePeers{Policy: "quorum"},
+			peers:            []string{"a", "b"},
+		},
+		{
+			name:             "quorum not reached",
+			unreachablePeers: &config.UnreachablePeers{Policy: "quorum"},
+			peers:            []string{"a"},
+			wantErrMsg:       "2 of 4 node managers responded, a majority is required",
+		},
+		{
+			name:             "recently unreachable peers not ignored",
+			unreachablePeers: &config.UnreachablePeers{Policy: "ignoreAfter", IgnoreAfter: 600},
+			unreachableFor:   time.Minute,
+			wantErrMsg:       "node managers [u1 u2] did not respond",
+		},
+		{
+			name:             "unreachable peers ignored after ignoreAfter",
+			unreachablePeers: &config.UnreachablePeers{Policy: "ignoreAfter", IgnoreAfter: 600},
+			unreachableFor:   time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peers := config.PeerArr{
+				{Name: "self", RpcUrl: "http://self"},
+				{Name: "u1", RpcUrl: "http://localhost:1"},
+			}
+			for _, p := range tt.peers {
+				peers = append(peers, &config.Peer{Name: p, RpcUrl: ok.URL})
+			}
+			peers = append(peers, &config.Peer{Name: "u2", RpcUrl: "http://localhost:1"})
+			cfg := &config.Node{
+				BasicConfig: &config.Basic{Name: "self", UnreachablePeers: tt.unreachablePeers},
+				Peers:       peers,
+			}
+			pm := &PeerManager{cfg: cfg, health: newPeerHealthCache(tt.unreachablePeers)}
+			start := time.Now()
+			pm.health.now = func() time.Time { return start }
+			pm.health.record("u1", errCircuitOpen)
+			pm.health.record("u2", errCircuitOpen)
+			pm.health.now = func() time.Time { return start.Add(tt.unreachableFor) }
+
+			_, err := pm.ValidatePeers()
+
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErrMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+const (
+	RequestLeaseMethod = `{"jsonrpc":"2.0", "method":"node.RequestHibernationLease", "params":[%s], "id":77}`
+	ReleaseLeaseMethod = `{"jsonrpc":"2.0", "method":"node.ReleaseLease", "params":[%s], "id":77}`
+
+	requestLeaseMethodName = "node.RequestHibernationLease"
+	releaseLeaseMethodName = "node.ReleaseLease"
+)
+
+type LeaseArgs struct {
+	From string `json:"from"` // name of the node hibernator requesting or releasing the lease
+	ID   string `json:"id"`   // id of the lease chosen by the requester. a lease is only released by its holder with the same id
+}
+
+type LeaseReply struct {
+	Granted bool
+	Expiry  time.Time // time at which the lease expires. zero if the lease is not granted
+	Holders []string  // names of the holders of the leases granted by the node hibernator
+}
+
+type ReleaseLeaseReply struct {
+	Released bool
+}
+
+type PeerLeaseResult struct {
+	Result LeaseReply `json:"result"`
+	Error  error      `json:"error"`
+}
+
+type PeerReleaseLeaseResult struct {
+	Result ReleaseLeaseReply `json:"result"`
+	Error  error             `json:"error"`
+}
+
+// lease is a hibernation lease granted to a node hibernator
+type lease struct {
+	id     string
+	expiry time.Time
+}
+
+// leaseTable grants time limited hibernation leases. A lease is granted if the holder already holds a lease or
+// fewer than max leases are held. A lease expires duration after it is granted unless it is released earlier.
+type leaseTable struct {
+	max      int
+	duration time.Duration
+	leases   map[string]lease // leases by holder name
+	now      func() time.Time
+	mux      sync.Mutex
+}
+
+func newLeaseTable(cfg *config.HibernationLease) *leaseTable {
+	if cfg == nil {
+		cfg = &config.HibernationLease{}
+	}
+	return &leaseTable{
+		max:      cfg.MaxConcurrentOrDefault(),
+		duration: time.Duration(cfg.DurationOrDefault()) * time.Second,
+		leases:   make(map[string]lease),
+		now:      time.Now,
+	}
+}
+
+// grant grants a lease with the given id to holder. A lease already held by holder is replaced.
+func (t *leaseTable) grant(holder, id string) LeaseReply {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.expire()
+	if _, ok := t.leases[holder]; !ok && len(t.leases) >= t.max {
+		return LeaseReply{Granted: false, Holders: t.holders()}
+	}
+	l := lease{id: id, expiry: t.now().Add(t.duration)}
+	t.leases[holder] = l
+	return LeaseReply{Granted: true, Expiry: l.expiry, Holders: t.holders()}
+}
+
+// release releases the lease of holder if it has the given id
+func (t *leaseTable) release(holder, id string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if l, ok := t.leases[holder]; ok && l.id == id {
+		delete(t.leases, holder)
+		return true
+	}
+	return false
+}
+
+// expire removes the expired leases. It should be called with mux held.
+func (t *leaseTable) expire() {
+	now := t.now()
+	for h, l := range t.leases {
+		if !now.Before(l.expiry) {
+			log.Info("leaseTable - hibernation lease expired", "holder", h, "expiry", l.expiry)
+			delete(t.leases, h)
+		}
+	}
+}
+
+// holders returns the sorted names of the lease holders. It should be called with mux held.
+func (t *leaseTable) holders() []string {
+	var h []string
+	for k := range t.leases {
+		h = append(h, k)
+	}
+	sort.Strings(h)
+	return h
+}
+
+// GrantLease grants a hibernation lease with the given id to the peer holder if this node hibernator has not
+// granted the configured number of concurrent leases yet
+func (pm *PeerManager) GrantLease(holder, id string) LeaseReply {
+	reply := pm.leases.grant(holder, id)
+	log.Info("GrantLease - hibernation lease requested", "holder", holder, "granted", reply.Granted, "holders", reply.Holders)
+	return reply
+}
+
+// ReleaseLease releases the hibernation lease with the given id granted to the peer holder
+func (pm *PeerManager) ReleaseLease(holder, id string) bool {
+	released := pm.leases.release(holder, id)
+	log.Info("ReleaseLease - hibernation lease released", "holder", holder, "released", released)
+	return released
+}
+
+// AcquireHibernationLease acquires a hibernation lease from this node hibernator and a majority of all node
+// hibernators including itself. It returns the id of the lease. If a majority does not grant the lease the leases
+// granted are released and an error is returned.
+func (pm *PeerManager) AcquireHibernationLease() (string, error) {
+	id, err := newLeaseID()
+	if err != nil {
+		return "", err
+	}
+	self := pm.cfg.BasicConfig.Name
+	if reply := pm.leases.grant(self, id); !reply.Granted {
+		return "", fmt.Errorf("hibernation lease not granted by this node hibernator, held by %v", reply.Holders)
+	}
+
+	peers := pm.otherPeers()
+	required := (len(peers)+1)/2 + 1
+	granted := 1 + pm.requestLease(peers, id)
+	if granted < required {
+		pm.ReleaseHibernationLease(id)
+		return "", fmt.Errorf("hibernation lease granted by %d of %d node hibernators, %d required", granted, len(peers)+1, required)
+	}
+	log.Info("AcquireHibernationLease - hibernation lease acquired", "id", id, "granted", granted, "required", required)
+	return id, nil
+}
+
+// KeepHibernationLease renews the hibernation lease with the given id at this node hibernator and its peers
+// every third of the lease duration until it is released with ReleaseHibernationLease. It is called once the
+// node is hibernated so that the lease is held for as long as the node is hibernated.
+func (pm *PeerManager) KeepHibernationLease(id string) {
+	pm.renewMux.Lock()
+	defer pm.renewMux.Unlock()
+	pm.stopLeaseRenewal()
+	stopCh, doneCh := make(chan struct{}), make(chan struct{})
+	pm.renewStopCh, pm.renewDoneCh = stopCh, doneCh
+	go func() {
+		defer close(doneCh)
+		ticker := time.NewTicker(pm.leases.duration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				pm.renewHibernationLease(id)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	log.Info("KeepHibernationLease - renewing hibernation lease while hibernated", "id", id)
+}
+
+// renewHibernationLease requests the lease with the given id again, extending its expiry where it is still held
+func (pm *PeerManager) renewHibernationLease(id string) {
+	self := pm.cfg.BasicConfig.Name
+	granted := 0
+	if reply := pm.leases.grant(self, id); reply.Granted {
+		granted++
+	}
+	peers := pm.otherPeers()
+	required := (len(peers)+1)/2 + 1
+	granted += pm.requestLease(peers, id)
+	if granted < required {
+		log.Warn("renewHibernationLease - hibernation lease renewed by less than a majority", "id", id, "granted", granted, "required", required)
+		return
+	}
+	log.Debug("renewHibernationLease - hibernation lease renewed", "id", id, "granted", granted)
+}
+
+// stopLeaseRenewal stops renewing the held lease, if any, and waits for a renewal in progress to complete so that
+// the lease is not granted again once released. It should be called with renewMux held.
+func (pm *PeerManager) stopLeaseRenewal() {
+	if pm.renewStopCh != nil {
+		close(pm.renewStopCh)
+		<-pm.renewDoneCh
+		pm.renewStopCh, pm.renewDoneCh = nil, nil
+	}
+}
+
+// requestLease requests the lease with the given id from peers and returns the number of peers granting it
+func (pm *PeerManager) requestLease(peers []*config.Peer, id string) int {
+	args, _ := json.Marshal(LeaseArgs{From: pm.cfg.BasicConfig.Name, ID: id})
+	req := []byte(fmt.Sprintf(RequestLeaseMethod, args))
+
+	var (
+		granted int
+		mux     sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p *config.Peer) {
+			defer wg.Done()
+			var res PeerLeaseResult
+			if err := pm.callPeer(p, requestLeaseMethodName, req, &res); err != nil {
+				log.Error("requestLease - requesting lease failed", "peer", p.Name, "err", err)
+				pm.recordPeerUnreachable(p.Name, err)
+				return
+			}
+			if res.Error != nil || !res.Result.Granted {
+				log.Info("requestLease - lease not granted", "peer", p.Name, "holders", res.Result.Holders, "err", res.Error)
+				return
+			}
+			mux.Lock()
+			granted++
+			mux.Unlock()
+		}(p)
+	}
+	wg.Wait()
+	return granted
+}
+
+// ReleaseHibernationLease stops renewing the hibernation lease with the given id held by this node hibernator
+// and releases it at itself and its peers
+func (pm *PeerManager) ReleaseHibernationLease(id string) {
+	pm.renewMux.Lock()
+	pm.stopLeaseRenewal()
+	pm.renewMux.Unlock()
+
+	self := pm.cfg.BasicConfig.Name
+	pm.leases.release(self, id)
+
+	args, _ := json.Marshal(LeaseArgs{From: self, ID: id})
+	req := []byte(fmt.Sprintf(ReleaseLeaseMethod, args))
+	var wg sync.WaitGroup
+	for _, p := range pm.otherPeers() {
+		wg.Add(1)
+		go func(p *config.Peer) {
+			defer wg.Done()
+			var res PeerReleaseLeaseResult
+			if err := pm.callPeer(p, releaseLeaseMethodName, req, &res); err != nil {
+				// the lease expires at the peer
+				log.Warn("ReleaseHibernationLease - releasing lease failed", "peer", p.Name, "err", err)
+				pm.recordPeerUnreachable(p.Name, err)
+			}
+		}(p)
+	}
+	wg.Wait()
+	log.Info("ReleaseHibernationLease - hibernation lease released", "id", id)
+}
+
+// otherPeers returns the peers excluding this node hibernator
+func (pm *PeerManager) otherPeers() []*config.Peer {
+	var peers []*config.Peer
+	for _, p := range pm.readPeersConfig() {
+		if !pm.isPeerSelf(p.Name) {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+func newLeaseID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
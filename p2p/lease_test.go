@@ -0,0 +1,140 @@
+package p2p
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseTable_Grant(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	lt := newLeaseTable(&config.HibernationLease{Duration: 60, MaxConcurrent: 2})
+	lt.now = func() time.Time { return now }
+
+	require.Equal(t, LeaseReply{Granted: true, Expiry: now.Add(time.Minute), Holders: []string{"b"}}, lt.grant("b", "id1"))
+	require.Equal(t, LeaseReply{Granted: true, Expiry: now.Add(time.Minute), Holders: []string{"a", "b"}}, lt.grant("a", "id2"))
+	require.Equal(t, LeaseReply{Granted: false, Holders: []string{"a", "b"}}, lt.grant("c", "id3"), "max concurrent leases are granted")
+
+	now = now.Add(30 * time.Second)
+	require.Equal(t, LeaseReply{Granted: true, Expiry: now.Add(time.Minute), Holders: []string{"a", "b"}}, lt.grant("b", "id4"), "holder must be able to renew its lease")
+
+	require.False(t, lt.release("a", "id3"), "lease must only be released by its id")
+	require.True(t, lt.release("a", "id2"))
+	require.True(t, lt.grant("c", "id3").Granted)
+
+	now = now.Add(time.Minute)
+	require.Equal(t, LeaseReply{Granted: true, Expiry: now.Add(time.Minute), Holders: []string{"d"}}, lt.grant("d", "id5"), "expired leases must be removed")
+}
+
+func TestLeaseTable_Defaults(t *testing.T) {
+	lt := newLeaseTable(nil)
+
+	require.Equal(t, 1, lt.max)
+	require.Equal(t, 300*time.Second, lt.duration)
+}
+
+// newLeasePeer returns a node hibernator RPC server granting leases from pm
+func newLeasePeer(t *testing.T, pm *PeerManager) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string      `json:"method"`
+			Params []LeaseArgs `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var result interface{}
+		switch req.Method {
+		case requestLeaseMethodName:
+			result = pm.GrantLease(req.Params[0].From, req.Params[0].ID)
+		case releaseLeaseMethodName:
+			result = ReleaseLeaseReply{Released: pm.ReleaseLease(req.Params[0].From, req.Params[0].ID)}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 77, "result": result})
+	}))
+}
+
+func newLeasePeerManager(name string, peers ...*config.Peer) *PeerManager {
+	cfg := &config.Node{
+		BasicConfig: &config.Basic{Name: name, HibernationLease: &config.HibernationLease{}},
+		Peers:       peers,
+	}
+	return &PeerManager{cfg: cfg, leases: newLeaseTable(cfg.BasicConfig.HibernationLease), health: newPeerHealthCache(nil)}
+}
+
+func TestPeerManager_AcquireHibernationLease(t *testing.T) {
+	b := newLeasePeerManager("b")
+	c := newLeasePeerManager("c")
+	srvB := newLeasePeer(t, b)
+	defer srvB.Close()
+	srvC := newLeasePeer(t, c)
+	defer srvC.Close()
+
+	a := newLeasePeerManager("a", &config.Peer{Name: "a", RpcUrl: "http://a"}, &config.Peer{Name: "b", RpcUrl: srvB.URL}, &config.Peer{Name: "c", RpcUrl: srvC.URL})
+
+	// c granted a lease to d
+	require.True(t, c.GrantLease("d", "id").Granted)
+
+	id, err := a.AcquireHibernationLease()
+	require.NoError(t, err, "a and b are a majority")
+	require.Equal(t, []string{"a"}, a.leases.grant("x", "x").Holders)
+	require.Equal(t, []string{"a"}, b.leases.grant("x", "x").Holders)
+
+	a.ReleaseHibernationLease(id)
+	require.Empty(t, a.leases.holders())
+	require.Empty(t, b.leases.holders())
+}
+
+func TestPeerManager_AcquireHibernationLease_NotGrantedByMajority(t *testing.T) {
+	b := newLeasePeerManager("b")
+	srvB := newLeasePeer(t, b)
+	defer srvB.Close()
+
+	a := newLeasePeerManager("a", &config.Peer{Name: "b", RpcUrl: srvB.URL}, &config.Peer{Name: "c", RpcUrl: "http://localhost:1"})
+
+	// b granted a lease to c
+	require.True(t, b.GrantLease("c", "id").Granted)
+
+	_, err := a.AcquireHibernationLease()
+
+	require.EqualError(t, err, "hibernation lease granted by 1 of 3 node hibernators, 2 required")
+	require.Empty(t, a.leases.holders(), "own lease must be released")
+	require.Equal(t, []string{"c"}, b.leases.holders())
+}
+
+func TestPeerManager_AcquireHibernationLease_NotGrantedBySelf(t *testing.T) {
+	a := newLeasePeerManager("a")
+	require.True(t, a.GrantLease("b", "id").Granted)
+
+	_, err := a.AcquireHibernationLease()
+
+	require.EqualError(t, err, "hibernation lease not granted by this node hibernator, held by [b]")
+}
+
+func TestPeerManager_KeepHibernationLease(t *testing.T) {
+	b := newLeasePeerManager("b")
+	b.leases.duration = 60 * time.Millisecond
+	srvB := newLeasePeer(t, b)
+	defer srvB.Close()
+
+	a := newLeasePeerManager("a", &config.Peer{Name: "b", RpcUrl: srvB.URL})
+	a.leases.duration = 60 * time.Millisecond
+
+	id, err := a.AcquireHibernationLease()
+	require.NoError(t, err)
+	a.KeepHibernationLease(id)
+
+	time.Sleep(200 * time.Millisecond)
+	b.leases.mux.Lock()
+	b.leases.expire()
+	holders := b.leases.holders()
+	b.leases.mux.Unlock()
+	require.Equal(t, []string{"a"}, holders, "lease must be renewed while it is kept")
+
+	a.ReleaseHibernationLease(id)
+	require.Empty(t, a.leases.holders())
+	require.Empty(t, b.leases.holders())
+}
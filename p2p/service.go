@@ -4,26 +4,78 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/ConsenSys/quorum-hibernate/config"
 
 	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 )
 
 const (
 	NodeStatusMethod   = `{"jsonrpc":"2.0", "method":"node.NodeStatus", "params":["%s"], "id":77}`
 	PreparePvtTxMethod = `{"jsonrpc":"2.0", "method":"node.PrepareForPrivateTx", "params":["%s"], "id":77}`
+
+	nodeStatusMethodName   = "node.NodeStatus"
+	preparePvtTxMethodName = "node.PrepareForPrivateTx"
 )
 
-func NewPeerManager(cfg *config.Node) *PeerManager {
-	configReader, _ := config.NewPeersReader(cfg.BasicConfig.PeersConfigFile)
+// NewPeerManager returns the peer manager. It returns an error if peer discovery is enabled and cannot be created.
+func NewPeerManager(cfg *config.Node, h *history.Recorder) (*PeerManager, error) {
+	pm := &PeerManager{
+		cfg:     cfg,
+		history: h,
+		leases:  newLeaseTable(cfg.BasicConfig.HibernationLease),
+		health:  newPeerHealthCache(cfg.BasicConfig.UnreachablePeers),
+	}
+	if cfg.BasicConfig.Discovery != nil {
+		var err error
+		if pm.discovery, err = NewDiscovery(cfg); err != nil {
+			return nil, fmt.Errorf("creating peer discovery failed: %v", err)
+		}
+	} else {
+		pm.configReader, _ = config.NewPeersReader(cfg.BasicConfig.PeersConfigFile)
+	}
+	return pm, nil
+}
+
+// Start starts peer discovery if it is enabled
+func (pm *PeerManager) Start() {
+	if pm.discovery != nil {
+		pm.discovery.Start()
+	}
+}
+
+// Stop stops peer discovery if it is enabled
+func (pm *PeerManager) Stop() {
+	if pm.discovery != nil {
+		pm.discovery.Stop()
+	}
+}
+
+// ExchangePeerRecords merges the peer records received from a peer and returns the peer records known to this
+// node hibernator. It returns ErrDiscoveryDisabled if peer discovery is not enabled.
+func (pm *PeerManager) ExchangePeerRecords(records []PeerRecord) ([]PeerRecord, error) {
+	if pm.discovery == nil {
+		return nil, ErrDiscoveryDisabled
+	}
+	pm.discovery.Merge(records)
+	return pm.discovery.Records(), nil
+}
 
-	return &PeerManager{
-		cfg:          cfg,
-		configReader: configReader,
+// PeerNameBySubject returns the name of the peer calling with a client certificate with the given subject common name.
+// It returns false if no peer uses the subject.
+func (pm *PeerManager) PeerNameBySubject(subject string) (string, bool) {
+	for _, p := range pm.readPeersConfig() {
+		if !pm.isPeerSelf(p.Name) && p.CertSubjectOrName() == subject {
+			return p.Name, true
+		}
 	}
+	return "", false
 }
 
 func (pm *PeerManager) getConfigByPrivManKey(key string) *config.Peer {
@@ -37,6 +89,13 @@ func (pm *PeerManager) getConfigByPrivManKey(key string) *config.Peer {
 }
 
 func (pm *PeerManager) readPeersConfig() []*config.Peer {
+	if pm.discovery != nil {
+		pm.cfg.Peers = pm.discovery.Peers()
+		return pm.cfg.Peers
+	}
+	if pm.configReader == nil {
+		return pm.cfg.Peers
+	}
 	newPeers, err := pm.configReader.Read()
 	if err != nil {
 		log.Error("readPeersConfig - error updating node hibernator config. will use old config", "path", pm.cfg.BasicConfig.PeersConfigFile, "err", err)
@@ -56,10 +115,10 @@ func (pm *PeerManager) readPeersConfig() []*config.Peer {
 	return pm.cfg.Peers
 }
 
-// TODO if a node hibernator is down/not reachable should we mark it as down and proceed?
-// ValidatePeerPrivateTxStatus validates participants readiness status to process private tx
+// ValidatePeerPrivateTxStatus validates participants readiness status to process private tx.
+// Participants whose node hibernator is not reachable are considered not ready.
 func (pm *PeerManager) ValidatePeerPrivateTxStatus(participantKeys []string) (bool, error) {
-	statusArr := pm.peerPrivateTxStatus(participantKeys)
+	peers, statusArr := pm.peerPrivateTxStatus(participantKeys)
 	finalStatus := true
 	if len(statusArr) == 0 {
 		finalStatus = false
@@ -71,6 +130,13 @@ func (pm *PeerManager) ValidatePeerPrivateTxStatus(participantKeys []string) (bo
 		}
 	}
 	log.Debug("ValidatePeerPrivateTxStatus completed", "final status", finalStatus, "statusArr", statusArr)
+	if len(peers) > 0 {
+		pm.history.Record(history.Event{
+			Type:    history.PeersPreparedForPvtTx,
+			Message: fmt.Sprintf("ready=%v", finalStatus),
+			Peers:   peers,
+		})
+	}
 	return finalStatus, nil
 }
 
@@ -84,17 +150,18 @@ func (pm *PeerManager) peersByParticipantKeyCount(participantKeys []string) int
 	return c
 }
 
-// peerPrivateTxStatus returns readiness status of peers to process private transaction
-func (pm *PeerManager) peerPrivateTxStatus(participantKeys []string) []bool {
+// peerPrivateTxStatus returns the names of the peers requested and their readiness status to process private transaction
+func (pm *PeerManager) peerPrivateTxStatus(participantKeys []string) ([]string, []bool) {
 	var wg = sync.WaitGroup{}
 	var resDoneCh = make(chan bool, 1)
 	var resCh = make(chan PeerPrivateTxPrepResult, 1)
 	var expResCnt = pm.peersByParticipantKeyCount(participantKeys)
 	var preparePvtTxReq = []byte(fmt.Sprintf(PreparePvtTxMethod, pm.cfg.BasicConfig.Name))
 	var statusArr []bool
+	var peers []string
 
 	if expResCnt == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// go routine to receive responses from rpc call to peers for status
@@ -120,17 +187,15 @@ func (pm *PeerManager) peerPrivateTxStatus(participantKeys []string) []bool {
 		nhCfg := pm.getConfigByPrivManKey(key)
 
 		if nhCfg != nil {
+			peers = append(peers, nhCfg.Name)
 			wg.Add(1)
 			go func(nhc *config.Peer) {
 				defer wg.Done()
 				result := PeerPrivateTxPrepResult{}
-				var client *http.Client
-				if nhc.TLSConfig != nil {
-					client = core.NewHttpClient(nhc.TLSConfig.TlsCfg)
-				}
-				if err := core.CallRPC(client, nhc.RpcUrl, preparePvtTxReq, &result); err != nil {
+				if err := pm.callPeer(nhc, preparePvtTxMethodName, preparePvtTxReq, &result); err != nil {
 					log.Error("peerPrivateTxStatus rpc failed", "err", err)
 					result.Error = err
+					pm.recordPeerUnreachable(nhc.Name, err)
 				} else if result.Error != nil {
 					log.Error("peerPrivateTxStatus rpc result failed", "err", result.Error)
 				}
@@ -144,16 +209,17 @@ func (pm *PeerManager) peerPrivateTxStatus(participantKeys []string) []bool {
 	wg.Wait()
 	<-resDoneCh
 	log.Debug("peerPrivateTxStatus - completed", "status", statusArr)
-	return statusArr
+	return peers, statusArr
 }
 
 // ValidatePeers checks the status of peer node managers.
-// if one of them returns error during rpc call or not reachable then it returns error.
-// if all of them responded and one of them in shutdown initiated or inprogress state it returns error
+// if some of them return error during rpc call or are not reachable it returns error unless the unreachable peers
+// policy allows them to be ignored.
+// if one of the peers which responded is in shutdown initiated or inprogress state it returns error
 func (pm *PeerManager) ValidatePeers() ([]NodeStatusInfo, error) {
-	nodeManagerCount, statusArr := pm.peerStatus()
-	if len(statusArr) != nodeManagerCount {
-		return statusArr, errors.New("some node managers did not respond")
+	nodeManagerCount, statusArr, unreachable := pm.peerStatus()
+	if err := pm.checkUnreachablePeers(nodeManagerCount, unreachable); err != nil {
+		return statusArr, err
 	}
 	shutdownInProgress := false
 	for _, n := range statusArr {
@@ -169,6 +235,44 @@ func (pm *PeerManager) ValidatePeers() ([]NodeStatusInfo, error) {
 	return statusArr, nil
 }
 
+// checkUnreachablePeers returns error if the peers which did not respond to the status check prevent the node
+// from being shutdown according to the configured unreachable peers policy
+func (pm *PeerManager) checkUnreachablePeers(nodeManagerCount int, unreachable []string) error {
+	if len(unreachable) == 0 {
+		return nil
+	}
+	policy := pm.cfg.BasicConfig.UnreachablePeers
+	switch {
+	case policy == nil || policy.IsAll():
+		return errors.New("some node managers did not respond")
+	case policy.IsDown():
+		log.Warn("checkUnreachablePeers - unreachable peers treated as down", "peers", unreachable)
+		return nil
+	case policy.IsQuorum():
+		// this node hibernator is counted as responded
+		responded := nodeManagerCount - len(unreachable) + 1
+		if responded*2 <= nodeManagerCount+1 {
+			return fmt.Errorf("%d of %d node managers responded, a majority is required", responded, nodeManagerCount+1)
+		}
+		log.Warn("checkUnreachablePeers - unreachable peers ignored as a majority responded", "peers", unreachable)
+		return nil
+	case policy.IsIgnoreAfter():
+		ignoreAfter := time.Duration(policy.IgnoreAfter) * time.Second
+		var recent []string
+		for _, p := range unreachable {
+			if pm.health.unreachableFor(p) < ignoreAfter {
+				recent = append(recent, p)
+			}
+		}
+		if len(recent) > 0 {
+			return fmt.Errorf("node managers %v did not respond", recent)
+		}
+		log.Warn("checkUnreachablePeers - peers unreachable for longer than ignoreAfter ignored", "peers", unreachable)
+		return nil
+	}
+	return nil
+}
+
 func (pm *PeerManager) getPeersCount(nhCfgs []*config.Peer) int {
 	nodeManagerCount := 0
 	for _, n := range nhCfgs {
@@ -182,14 +286,16 @@ func (pm *PeerManager) getPeersCount(nhCfgs []*config.Peer) int {
 }
 
 // peerStatus makes rpc call to peers and gets their status.
-// If returns expected result count and an array results(NodeStausInfo) received.
+// If returns expected result count, an array results(NodeStausInfo) received and the names of the peers which did not respond.
 // If all of them responded and one of them in shutdown initiated or inprogress state it returns error.
 // It creates as many go routines as the number of peer node managers. It should not be an issue
 // as we would not have more than a few thousand peers.
 // Golang easily supports creating thousands of goroutines
-func (pm *PeerManager) peerStatus() (int, []NodeStatusInfo) {
+func (pm *PeerManager) peerStatus() (int, []NodeStatusInfo, []string) {
 	var nodeStatusReq = []byte(fmt.Sprintf(NodeStatusMethod, pm.cfg.BasicConfig.Name))
 	var statusArr []NodeStatusInfo
+	var unreachable []string
+	var unreachableMux sync.Mutex
 	var wg = sync.WaitGroup{}
 	var resDoneCh = make(chan bool, 1)
 	var resCh = make(chan PeerNodeStatusResult, 1)
@@ -197,7 +303,7 @@ func (pm *PeerManager) peerStatus() (int, []NodeStatusInfo) {
 	expResCnt := pm.getPeersCount(peersConfig)
 
 	if expResCnt == 0 {
-		return 0, nil
+		return 0, nil, nil
 	}
 
 	// go routine to receive responses from rpc call to peers for status
@@ -228,24 +334,55 @@ func (pm *PeerManager) peerStatus() (int, []NodeStatusInfo) {
 		go func(nhc *config.Peer) {
 			defer wg.Done()
 			var res = PeerNodeStatusResult{}
-			var client *http.Client
-			if nhc.TLSConfig != nil {
-				client = core.NewHttpClient(nhc.TLSConfig.TlsCfg)
-			}
-			if err := core.CallRPC(client, nhc.RpcUrl, nodeStatusReq, &res); err != nil {
+			if err := pm.callPeer(nhc, nodeStatusMethodName, nodeStatusReq, &res); err != nil {
 				log.Error("peerStatus - ClientStatus - failed", "err", err)
+				pm.recordPeerUnreachable(nhc.Name, err)
+				res.Error = err
 			}
 			if res.Error != nil {
 				log.Error("peerStatus - ClientStatus - response failed", "err", res.Error)
+				unreachableMux.Lock()
+				unreachable = append(unreachable, nhc.Name)
+				unreachableMux.Unlock()
 			}
-			log.Debug("peerStatus", "res", res, "cfg", n)
+			log.Debug("peerStatus", "res", res, "cfg", nhc)
 			resCh <- res
 		}(n)
 	}
 	wg.Wait()
 	<-resDoneCh
-	log.Info("peerStatus - completed", "status", fmt.Sprintf("%+v", statusArr))
-	return expResCnt, statusArr
+	sort.Strings(unreachable)
+	log.Info("peerStatus - completed", "status", fmt.Sprintf("%+v", statusArr), "unreachable", unreachable)
+	return expResCnt, statusArr, unreachable
+}
+
+// callPeer makes the rpc call to the peer and records its latency and health.
+// The call is skipped and errCircuitOpen is returned if the peer has been unreachable for the last calls.
+func (pm *PeerManager) callPeer(peer *config.Peer, method string, req []byte, res interface{}) error {
+	if err := pm.health.allow(peer.Name); err != nil {
+		return err
+	}
+	var client *http.Client
+	if peer.TLSConfig != nil {
+		client = core.NewHttpClient(peer.TLSConfig.TlsCfg)
+	}
+	start := time.Now()
+	err := core.CallRPC(client, peer.RpcUrl, req, res)
+	metrics.ObservePeerRPC(peer.Name, method, start, err)
+	pm.health.record(peer.Name, err)
+	return err
+}
+
+func (pm *PeerManager) recordPeerUnreachable(peerName string, err error) {
+	if err == errCircuitOpen {
+		// already recorded by the failed calls which opened the circuit
+		return
+	}
+	pm.history.Record(history.Event{
+		Type:    history.PeerUnreachable,
+		Message: err.Error(),
+		Peers:   []string{peerName},
+	})
 }
 
 func (pm *PeerManager) isPeerSelf(peerName string) bool {
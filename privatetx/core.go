@@ -1,10 +1,22 @@
 package privatetx
 
+import "net/http"
+
 // TxHandler is an interface to process private transactions.
 // It should be implementd for quorum and besu as they have differences
 // in message formats in handling private transactions.
 type TxHandler interface {
 	// IsPrivateTx will take msg as input and return an array of public keys of participants if
 	// the msg is a private transaction.
+	// msg can be a single JSON-RPC request or a batch (array) of requests. For a batch the participants
+	// of all the private transactions in the batch are returned without duplicates.
 	IsPrivateTx(msg []byte) ([]string, error)
 }
+
+// RequestTxHandler is an interface to process private transactions sent to REST APIs, such as the privacy manager's,
+// where the participants can be in the request headers as well as the body depending on the request path.
+type RequestTxHandler interface {
+	// IsPrivateRequest returns an array of public keys of participants if the request to path with the given header
+	// and body is a private transaction.
+	IsPrivateRequest(path string, header http.Header, body []byte) ([]string, error)
+}
@@ -1,6 +1,7 @@
 package privatetx
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -61,22 +62,49 @@ func containsPrivateTxKeyWords(bodyStr string) bool {
 	return strings.Contains(bodyStr, privateFor)
 }
 
+// decodePvtTx returns the participants of the private transaction in body.
+// If body is a batch request it returns the participants of all private transactions in the batch.
 func decodePvtTx(body []byte) ([]string, error) {
+	if isBatch(body) {
+		var batch []interface{}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return nil, err
+		}
+		log.Debug("decodePvtTx - batch details", "size", len(batch))
+		var keys []string
+		for i, req := range batch {
+			txMap, ok := req.(map[string]interface{})
+			if !ok {
+				log.Warn("decodePvtTx - batch element is not a request object", "index", i)
+				continue
+			}
+			keys = appendUnique(keys, decodePvtReq(txMap)...)
+		}
+		return keys, nil
+	}
+
 	var txMap map[string]interface{}
 	err := json.Unmarshal(body, &txMap)
 	if err != nil {
 		return nil, err
-	} else {
-		log.Debug("decodePvtTx - txMap details", "Tx", txMap)
 	}
-	var method string
-	var ok bool
-	if _, ok = txMap["method"]; !ok {
-		return nil, nil
+	return decodePvtReq(txMap), nil
+}
+
+// isBatch returns true if body is a JSON-RPC batch request
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// decodePvtReq returns the participants of the private transaction in a single JSON-RPC request
+func decodePvtReq(txMap map[string]interface{}) []string {
+	log.Debug("decodePvtReq - txMap details", "Tx", txMap)
+	method, ok := txMap["method"].(string)
+	if !ok {
+		return nil
 	}
-	method = txMap["method"].(string)
-	keys := validatePrivateReq(txMap, method)
-	return keys, nil
+	return validatePrivateReq(txMap, method)
 }
 
 func validatePrivateReq(txMap map[string]interface{}, method string) []string {
@@ -110,15 +138,36 @@ func validatePrivateReq(txMap map[string]interface{}, method string) []string {
 		log.Warn("params missing in " + method)
 		return nil
 	}
-	return nil
 }
 
 func privKeys(keys interface{}) []string {
-	arrK := keys.([]interface{})
+	arrK, ok := keys.([]interface{})
+	if !ok {
+		log.Warn("privKeys - privateFor is not an array", "privateFor", keys)
+		return nil
+	}
 	var privKeys []string
 	for _, v := range arrK {
-		s := v.(string)
-		privKeys = append(privKeys, s)
+		if s, ok := v.(string); ok {
+			privKeys = append(privKeys, s)
+		}
 	}
 	return privKeys
 }
+
+// appendUnique appends the keys which are not already in dst
+func appendUnique(dst []string, keys ...string) []string {
+	for _, k := range keys {
+		found := false
+		for _, d := range dst {
+			if d == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, k)
+		}
+	}
+	return dst
+}
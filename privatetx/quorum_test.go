@@ -0,0 +1,81 @@
+package privatetx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorumTxHandler_IsPrivateTx(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{
+			name:     "publicTx",
+			msg:      `{"jsonrpc":"2.0","id":1,"method":"eth_sendTransaction","params":[{"from":"0x1"}]}`,
+			wantKeys: nil,
+		},
+		{
+			name:     "privateTx",
+			msg:      `{"jsonrpc":"2.0","id":1,"method":"eth_sendTransaction","params":[{"from":"0x1","privateFor":["key1","key2"]}]}`,
+			wantKeys: []string{"key1", "key2"},
+		},
+		{
+			name:     "privateRawTx",
+			msg:      `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawPrivateTransaction","params":["0xf8",{"privateFor":["key1"]}]}`,
+			wantKeys: []string{"key1"},
+		},
+		{
+			name:     "privateForInOtherMethod",
+			msg:      `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"privateFor":["key1"]}]}`,
+			wantKeys: nil,
+		},
+		{
+			name:     "privateForNotArray",
+			msg:      `{"jsonrpc":"2.0","id":1,"method":"eth_sendTransaction","params":[{"privateFor":"key1"}]}`,
+			wantKeys: nil,
+		},
+		{
+			name: "batch",
+			msg: ` [
+				{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]},
+				{"jsonrpc":"2.0","id":2,"method":"eth_sendTransaction","params":[{"privateFor":["key1","key2"]}]},
+				{"jsonrpc":"2.0","id":3,"method":"eth_sendRawPrivateTransaction","params":["0xf8",{"privateFor":["key2","key3"]}]}
+			]`,
+			wantKeys: []string{"key1", "key2", "key3"},
+		},
+		{
+			name:     "batchWithInvalidElement",
+			msg:      `[1, {"jsonrpc":"2.0","id":2,"method":"eth_sendTransaction","params":[{"privateFor":["key1"]}]}]`,
+			wantKeys: []string{"key1"},
+		},
+		{
+			name:    "invalidJson",
+			msg:     `{"method":"eth_sendTransaction","params":[{"privateFor":["key1"]}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalidBatch",
+			msg:     `[{"method":"eth_sendTransaction","params":[{"privateFor":["key1"]}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txh := NewQuorumTxHandler(nil)
+
+			keys, err := txh.IsPrivateTx([]byte(tt.msg))
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantKeys, keys)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package privatetx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+// TesseraTxHandler finds the recipients of private payloads sent to Tessera's Q2T REST API
+type TesseraTxHandler struct{}
+
+// tesseraToHeader is the header of raw requests with the recipients
+const tesseraToHeader = "c11n-to"
+
+// tessera endpoints which store and/or send a private payload
+const (
+	tesseraSend         = "/send"
+	tesseraSendRaw      = "/sendraw"
+	tesseraStoreRaw     = "/storeraw"
+	tesseraSendSignedTx = "/sendsignedtx"
+	tesseraTransaction  = "/transaction"
+)
+
+func NewTesseraTxHandler() RequestTxHandler {
+	return &TesseraTxHandler{}
+}
+
+// IsPrivateRequest implements RequestTxHandler.IsPrivateRequest.
+// /send and /transaction have the recipients in the to field of the JSON body. /sendraw has them in the c11n-to
+// header. /sendsignedtx has them in the c11n-to header or, for JSON requests, in the to field of the body.
+// /storeraw only stores the payload locally, so it has no recipients.
+func (t TesseraTxHandler) IsPrivateRequest(path string, header http.Header, body []byte) ([]string, error) {
+	switch strings.TrimSuffix(path, "/") {
+	case tesseraSend, tesseraTransaction:
+		return recipientsFromBody(body)
+	case tesseraSendRaw:
+		return recipientsFromHeader(header), nil
+	case tesseraSendSignedTx:
+		if keys := recipientsFromHeader(header); keys != nil {
+			return keys, nil
+		}
+		if strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+			return recipientsFromBody(body)
+		}
+		return nil, nil
+	case tesseraStoreRaw:
+		log.Debug("IsPrivateRequest - payload stored locally, no recipients", "path", path)
+		return nil, nil
+	}
+	return nil, nil
+}
+
+// recipientsFromBody returns the keys in the to field of the JSON request body
+func recipientsFromBody(body []byte) ([]string, error) {
+	var req struct {
+		To []string `json:"to"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return appendUnique(nil, req.To...), nil
+}
+
+// recipientsFromHeader returns the keys in the c11n-to header, which can be set multiple times or be a comma
+// separated list
+func recipientsFromHeader(header http.Header) []string {
+	var keys []string
+	for _, v := range header.Values(tesseraToHeader) {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = appendUnique(keys, k)
+			}
+		}
+	}
+	return keys
+}
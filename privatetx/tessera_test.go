@@ -0,0 +1,96 @@
+package privatetx
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTesseraTxHandler_IsPrivateRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		header   http.Header
+		body     string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{
+			name:     "send",
+			path:     "/send",
+			body:     `{"payload":"cGF5bG9hZA==","from":"self","to":["key1","key2","key1"]}`,
+			wantKeys: []string{"key1", "key2"},
+		},
+		{
+			name:     "transaction",
+			path:     "/transaction/",
+			body:     `{"payload":"cGF5bG9hZA==","to":["key1"]}`,
+			wantKeys: []string{"key1"},
+		},
+		{
+			name:    "send invalid json",
+			path:    "/send",
+			body:    `{"to":["key1"]`,
+			wantErr: true,
+		},
+		{
+			name:     "sendraw",
+			path:     "/sendraw",
+			header:   http.Header{"C11n-To": []string{"key1, key2", "key3"}, "C11n-From": []string{"self"}},
+			body:     `raw payload`,
+			wantKeys: []string{"key1", "key2", "key3"},
+		},
+		{
+			name:     "sendraw without recipients",
+			path:     "/sendraw",
+			body:     `raw payload`,
+			wantKeys: nil,
+		},
+		{
+			name:     "sendsignedtx header",
+			path:     "/sendsignedtx",
+			header:   http.Header{"C11n-To": []string{"key1"}},
+			body:     `raw hash`,
+			wantKeys: []string{"key1"},
+		},
+		{
+			name:     "sendsignedtx json",
+			path:     "/sendsignedtx",
+			header:   http.Header{"Content-Type": []string{"application/json"}},
+			body:     `{"hash":"aGFzaA==","to":["key1","key2"]}`,
+			wantKeys: []string{"key1", "key2"},
+		},
+		{
+			name:     "storeraw",
+			path:     "/storeraw",
+			body:     `{"payload":"cGF5bG9hZA==","from":"self"}`,
+			wantKeys: nil,
+		},
+		{
+			name:     "receive",
+			path:     "/receive",
+			body:     `{"key":"abc","to":"self"}`,
+			wantKeys: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			txh := NewTesseraTxHandler()
+
+			keys, err := txh.IsPrivateRequest(tt.path, header, []byte(tt.body))
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantKeys, keys)
+		})
+	}
+}
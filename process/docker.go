@@ -10,6 +10,7 @@ import (
 	"github.com/ConsenSys/quorum-hibernate/config"
 
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 	dtypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 )
@@ -55,13 +56,17 @@ func (dc *DockerControl) UpdateStatus() bool {
 }
 
 // Stop implements Process.Stop
-func (dc *DockerControl) Stop() error {
+func (dc *DockerControl) Stop() (err error) {
 	defer dc.muxLock.Unlock()
 	dc.muxLock.Lock()
 	if !dc.status {
 		log.Info("Stop - process is already down", "name", dc.cfg.Name)
 		return nil
 	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(dc.cfg.Name, "stop", start, err == nil)
+	}()
 
 	cli, err := client.NewEnvClient()
 	if err != nil {
@@ -89,13 +94,17 @@ func (dc *DockerControl) Stop() error {
 }
 
 // Stop implements Process.Stop
-func (dc *DockerControl) Start() error {
+func (dc *DockerControl) Start() (err error) {
 	defer dc.muxLock.Unlock()
 	dc.muxLock.Lock()
 	if dc.status {
 		log.Info("Start - process is already up", "name", dc.cfg.Name)
 		return nil
 	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(dc.cfg.Name, "start", start, err == nil)
+	}()
 
 	cli, err := client.NewEnvClient()
 	if err != nil {
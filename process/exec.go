@@ -0,0 +1,369 @@
+package process
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
+)
+
+// exitPollInterval is the interval at which an adopted process is checked for exit
+var exitPollInterval = time.Second
+
+// ExecProcessControl represents process control for a process spawned and supervised by node hibernator.
+// The process is started with the configured command, env and working directory. It is stopped with SIGTERM
+// followed by SIGKILL if it has not exited after the grace period. Its stdout and stderr are written to the
+// log file. If the process exits without being stopped by node hibernator its status is set to down.
+type ExecProcessControl struct {
+	cfg      *config.Process
+	status   bool
+	client   *http.Client
+	proc     *os.Process   // running process. nil if no process is running
+	exited   chan struct{} // closed when the running process exits
+	stopping bool          // indicates if the running process is being stopped by node hibernator
+	muxLock  sync.Mutex    // lock for starting and stopping the process
+	procMux  sync.Mutex    // lock for status, proc, exited and stopping
+}
+
+func NewExecProcess(c *http.Client, p *config.Process, s bool) Process {
+	ep := &ExecProcessControl{cfg: p, status: s, client: c}
+	ep.adopt()
+	ep.UpdateStatus()
+	log.Debug("exec process created", "name", ep.cfg.Name)
+	return ep
+}
+
+func (ep *ExecProcessControl) setStatus(s bool) {
+	ep.procMux.Lock()
+	defer ep.procMux.Unlock()
+	ep.status = s
+	log.Debug("setStatus - process "+ep.cfg.Name, "status", s)
+}
+
+// Status implements Process.Status
+func (ep *ExecProcessControl) Status() bool {
+	ep.procMux.Lock()
+	defer ep.procMux.Unlock()
+	return ep.status
+}
+
+// UpdateStatus implements Process.UpdateStatus. The process is up if it is running and passes the upcheck.
+func (ep *ExecProcessControl) UpdateStatus() bool {
+	if _, running := ep.running(); !running {
+		ep.setStatus(false)
+		log.Debug("UpdateStatus - exec process is not running", "name", ep.cfg.Name)
+		return false
+	}
+	s, err := IsProcessUp(ep.client, ep.cfg.UpcheckCfg)
+	if err != nil {
+		ep.setStatus(false)
+		log.Error("UpdateStatus - exec process is down", "err", err)
+	} else {
+		ep.setStatus(s)
+	}
+	s = ep.Status()
+	log.Debug("UpdateStatus", "name", ep.cfg.Name, "return", s)
+	return s
+}
+
+// Start implements Process.Start
+func (ep *ExecProcessControl) Start() (err error) {
+	defer ep.muxLock.Unlock()
+	ep.muxLock.Lock()
+	if ep.Status() {
+		log.Info("Start - process is already up", "name", ep.cfg.Name)
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(ep.cfg.Name, "start", start, err == nil)
+	}()
+	if _, running := ep.running(); running {
+		log.Info("Start - process is running, waiting for it to come up", "name", ep.cfg.Name)
+	} else if err := ep.spawn(); err != nil {
+		log.Error("Start - failed to start "+ep.cfg.Name, "err", err)
+		return err
+	}
+	if !ep.WaitToComeUp() {
+		log.Error("Start - failed to start " + ep.cfg.Name)
+		if err := ep.terminate(); err != nil {
+			log.Error("Start - failed to stop "+ep.cfg.Name+" after failed start", "err", err)
+		}
+		ep.setStatus(false)
+		return fmt.Errorf("%s failed to start", ep.cfg.Name)
+	}
+	ep.setStatus(true)
+	log.Debug("Start - started", "process", ep.cfg.Name)
+	return nil
+}
+
+// Stop implements Process.Stop
+func (ep *ExecProcessControl) Stop() (err error) {
+	defer ep.muxLock.Unlock()
+	ep.muxLock.Lock()
+	if _, running := ep.running(); !running {
+		log.Info("Stop - process is already down", "name", ep.cfg.Name)
+		ep.setStatus(false)
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(ep.cfg.Name, "stop", start, err == nil)
+	}()
+	if err := ep.terminate(); err != nil {
+		log.Error("Stop - "+ep.cfg.Name+" failed", "err", err)
+		return err
+	}
+	ep.setStatus(false)
+	log.Debug("Stop - stopped", "process", ep.cfg.Name)
+	return nil
+}
+
+// WaitToComeUp waits for the process status to be up by performing up check repeatedly
+// for a certain duration. It returns false immediately if the process exits.
+func (ep *ExecProcessControl) WaitToComeUp() bool {
+	retryCount := 30
+	c := 1
+	for c <= retryCount {
+		if ep.UpdateStatus() {
+			return true
+		}
+		if _, running := ep.running(); !running {
+			log.Error("WaitToComeUp - process exited "+ep.cfg.Name, "c", c)
+			return false
+		}
+		time.Sleep(time.Second)
+		log.Debug("WaitToComeUp - wait for up "+ep.cfg.Name, "c", c)
+		c++
+	}
+	return false
+}
+
+// running returns the running process and true if there is one
+func (ep *ExecProcessControl) running() (*os.Process, bool) {
+	ep.procMux.Lock()
+	defer ep.procMux.Unlock()
+	return ep.proc, ep.proc != nil
+}
+
+// spawn starts the process and a goroutine which waits for it to exit
+func (ep *ExecProcessControl) spawn() error {
+	c := ep.cfg.ExecCfg
+	cmd := exec.Command(c.Command[0], c.Command[1:]...)
+	cmd.Dir = c.WorkDir
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true, // SIGINT interrupts the entire process group - to prevent SIGINT of node-hibernator killing this child process, give the child its own process group
+	}
+
+	var out io.WriteCloser
+	if c.LogFile != "" {
+		rf, err := newRotatingFile(c.LogFile, int64(c.MaxLogSize)*1024*1024, c.MaxLogFiles)
+		if err != nil {
+			return err
+		}
+		out = rf
+		cmd.Stdout = out
+		cmd.Stderr = out
+	}
+
+	if err := cmd.Start(); err != nil {
+		if out != nil {
+			out.Close()
+		}
+		return err
+	}
+	log.Info("spawn - process started", "name", ep.cfg.Name, "pid", cmd.Process.Pid)
+	ep.writePidFile(cmd.Process.Pid)
+
+	exited := ep.track(cmd.Process)
+	go func() {
+		err := cmd.Wait()
+		if out != nil {
+			out.Close()
+		}
+		ep.exit(cmd.Process, exited, err)
+	}()
+	return nil
+}
+
+// adopt takes control of a process spawned by a previous run of node hibernator if its pid file exists
+// and the process is still running. The pid may have been reused, e.g. after a host reboot, so the process
+// is only adopted if its command line matches the configured command. Otherwise the pid file is removed.
+func (ep *ExecProcessControl) adopt() {
+	if ep.cfg.ExecCfg.PidFile == "" {
+		return
+	}
+	b, err := ioutil.ReadFile(ep.cfg.ExecCfg.PidFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Error("adopt - unable to read pid file", "name", ep.cfg.Name, "err", err)
+		}
+		return
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		log.Error("adopt - invalid pid file", "name", ep.cfg.Name, "err", err)
+		return
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil || p.Signal(syscall.Signal(0)) != nil {
+		log.Info("adopt - process in pid file is not running", "name", ep.cfg.Name, "pid", pid)
+		ep.removePidFile()
+		return
+	}
+	if !ep.isCommand(pid) {
+		log.Warn("adopt - process in pid file does not run the configured command, not adopting it", "name", ep.cfg.Name, "pid", pid)
+		ep.removePidFile()
+		return
+	}
+	log.Info("adopt - controlling running process", "name", ep.cfg.Name, "pid", pid)
+	exited := ep.track(p)
+	// the process is not a child of node hibernator so it cannot be waited for
+	go func() {
+		for p.Signal(syscall.Signal(0)) == nil {
+			time.Sleep(exitPollInterval)
+		}
+		ep.exit(p, exited, nil)
+	}()
+}
+
+// isCommand returns true if the command line of the process with the given pid is the configured command
+func (ep *ExecProcessControl) isCommand(pid int) bool {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		log.Error("isCommand - unable to read process command line", "name", ep.cfg.Name, "pid", pid, "err", err)
+		return false
+	}
+	args := strings.Split(strings.TrimSuffix(string(b), "\x00"), "\x00")
+	c := ep.cfg.ExecCfg.Command
+	if len(args) != len(c) {
+		return false
+	}
+	for i := range c {
+		if args[i] != c[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// track sets p as the running process and returns the channel which is closed when it exits
+func (ep *ExecProcessControl) track(p *os.Process) chan struct{} {
+	ep.procMux.Lock()
+	defer ep.procMux.Unlock()
+	ep.proc = p
+	ep.exited = make(chan struct{})
+	ep.stopping = false
+	return ep.exited
+}
+
+// exit clears the running process p when it exits. If the process was not being stopped by node hibernator
+// the exit is unexpected and the status is set to down before exited is closed.
+func (ep *ExecProcessControl) exit(p *os.Process, exited chan struct{}, err error) {
+	ep.procMux.Lock()
+	current := ep.proc == p
+	unexpected := current && !ep.stopping
+	if current {
+		ep.proc = nil
+	}
+	if unexpected {
+		ep.status = false
+	}
+	ep.procMux.Unlock()
+	if current {
+		ep.removePidFile()
+	}
+	close(exited)
+
+	if !unexpected {
+		log.Info("exit - process stopped", "name", ep.cfg.Name, "pid", p.Pid, "err", err)
+		return
+	}
+	log.Error("exit - process exited unexpectedly", "name", ep.cfg.Name, "pid", p.Pid, "err", err)
+	metrics.IncProcessUnexpectedExits(ep.cfg.Name)
+}
+
+// terminate sends SIGTERM to the running process and waits for it to exit. If it has not exited after the
+// grace period SIGKILL is sent to its process group, or only to the process if it does not lead its own group.
+func (ep *ExecProcessControl) terminate() error {
+	ep.procMux.Lock()
+	p, exited := ep.proc, ep.exited
+	if p == nil {
+		ep.procMux.Unlock()
+		return nil
+	}
+	ep.stopping = true
+	ep.procMux.Unlock()
+
+	grace := time.Duration(ep.cfg.ExecCfg.GracePeriod) * time.Second
+	log.Debug("terminate - sending SIGTERM", "name", ep.cfg.Name, "pid", p.Pid, "gracePeriod", grace)
+	if err := p.Signal(syscall.SIGTERM); err != nil && !hasExited(exited) {
+		return err
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(grace):
+	}
+
+	log.Warn("terminate - process did not exit within grace period, sending SIGKILL", "name", ep.cfg.Name, "pid", p.Pid)
+	if err := kill(p); err != nil && !hasExited(exited) {
+		return err
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(grace):
+		return fmt.Errorf("%s did not exit after SIGKILL", ep.cfg.Name)
+	}
+}
+
+// kill sends SIGKILL to the process group of p if p leads it. The process is started in its own process group
+// so the group is killed to also kill any children holding its output.
+func kill(p *os.Process) error {
+	if pgid, err := syscall.Getpgid(p.Pid); err == nil && pgid == p.Pid {
+		return syscall.Kill(-p.Pid, syscall.SIGKILL)
+	}
+	return p.Kill()
+}
+
+// hasExited returns true if exited is closed, allowing for the process exit to be processed after a failed signal
+func hasExited(exited chan struct{}) bool {
+	select {
+	case <-exited:
+		return true
+	case <-time.After(exitPollInterval):
+		return false
+	}
+}
+
+func (ep *ExecProcessControl) writePidFile(pid int) {
+	if ep.cfg.ExecCfg.PidFile == "" {
+		return
+	}
+	if err := ioutil.WriteFile(ep.cfg.ExecCfg.PidFile, []byte(strconv.Itoa(pid)), 0644); err != nil {
+		log.Error("writePidFile - failed", "name", ep.cfg.Name, "err", err)
+	}
+}
+
+func (ep *ExecProcessControl) removePidFile() {
+	if ep.cfg.ExecCfg.PidFile == "" {
+		return
+	}
+	if err := os.Remove(ep.cfg.ExecCfg.PidFile); err != nil && !os.IsNotExist(err) {
+		log.Error("removePidFile - failed", "name", ep.cfg.Name, "err", err)
+	}
+}
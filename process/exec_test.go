@@ -0,0 +1,158 @@
+package process
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExecProcess(t *testing.T, dir string, command ...string) *ExecProcessControl {
+	upcheck := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("UpCheck"))
+	}))
+	t.Cleanup(upcheck.Close)
+
+	cfg := &config.Process{
+		Name:        "bcclnt",
+		ControlType: "exec",
+		ExecCfg: &config.Exec{
+			Command:     command,
+			Env:         []string{"EXEC_TEST=hello"},
+			WorkDir:     dir,
+			GracePeriod: 1,
+			LogFile:     filepath.Join(dir, "process.log"),
+			PidFile:     filepath.Join(dir, "process.pid"),
+		},
+		UpcheckCfg: &config.Upcheck{
+			UpcheckUrl: upcheck.URL,
+			ReturnType: "string",
+			Method:     "GET",
+			Expected:   "UpCheck",
+		},
+	}
+	return NewExecProcess(&http.Client{}, cfg, false).(*ExecProcessControl)
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "execprocess")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestExecProcess_StartStop(t *testing.T) {
+	dir := tempDir(t)
+	ep := newTestExecProcess(t, dir, "sh", "-c", "echo $EXEC_TEST; pwd; exec sleep 30")
+	require.False(t, ep.Status())
+
+	require.NoError(t, ep.Start())
+	require.True(t, ep.Status())
+
+	p, running := ep.running()
+	require.True(t, running)
+	pid, err := ioutil.ReadFile(filepath.Join(dir, "process.pid"))
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(p.Pid), string(pid))
+
+	var lines []string
+	require.Eventually(t, func() bool {
+		out, err := ioutil.ReadFile(filepath.Join(dir, "process.log"))
+		require.NoError(t, err)
+		lines = strings.Split(strings.TrimSpace(string(out)), "\n")
+		return len(lines) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ep.Stop())
+	require.False(t, ep.Status())
+	require.False(t, ep.UpdateStatus())
+	require.NoFileExists(t, filepath.Join(dir, "process.pid"))
+
+	require.Equal(t, "hello", lines[0])
+	wantDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	require.Equal(t, wantDir, lines[1])
+}
+
+func TestExecProcess_StopKillsAfterGracePeriod(t *testing.T) {
+	dir := tempDir(t)
+	ep := newTestExecProcess(t, dir, "sh", "-c", "trap '' TERM; touch trapped; while true; do sleep 1; done")
+	require.NoError(t, ep.Start())
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "trapped"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, ep.Stop())
+
+	require.True(t, time.Since(start) >= time.Second)
+	_, running := ep.running()
+	require.False(t, running)
+}
+
+func TestExecProcess_UnexpectedExit(t *testing.T) {
+	dir := tempDir(t)
+	ep := newTestExecProcess(t, dir, "sh", "-c", "sleep 1")
+	require.NoError(t, ep.Start())
+	require.True(t, ep.Status())
+
+	_, running := ep.running()
+	require.True(t, running)
+	ep.procMux.Lock()
+	exitedCh := ep.exited
+	ep.procMux.Unlock()
+
+	select {
+	case <-exitedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process did not exit")
+	}
+	require.False(t, ep.Status())
+	require.False(t, ep.UpdateStatus())
+	require.NoError(t, ep.Stop())
+}
+
+func TestExecProcess_AdoptsRunningProcess(t *testing.T) {
+	dir := tempDir(t)
+	ep := newTestExecProcess(t, dir, "sleep", "30")
+	require.NoError(t, ep.Start())
+	p, _ := ep.running()
+
+	// a restarted node hibernator controls the process spawned before
+	restarted := newTestExecProcess(t, dir, "sleep", "30")
+	require.True(t, restarted.Status())
+	adopted, running := restarted.running()
+	require.True(t, running)
+	require.Equal(t, p.Pid, adopted.Pid)
+
+	require.NoError(t, restarted.Stop())
+	require.False(t, restarted.Status())
+}
+
+func TestExecProcess_DoesNotAdoptOtherProcess(t *testing.T) {
+	dir := tempDir(t)
+	other := exec.Command("sleep", "30")
+	require.NoError(t, other.Start())
+	t.Cleanup(func() {
+		other.Process.Kill()
+		other.Wait()
+	})
+	// the pid in the pid file has been reused by a process not spawned by node hibernator
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "process.pid"), []byte(strconv.Itoa(other.Process.Pid)), 0644))
+
+	ep := newTestExecProcess(t, dir, "sleep", "31")
+	require.False(t, ep.Status())
+	_, running := ep.running()
+	require.False(t, running)
+	require.NoFileExists(t, filepath.Join(dir, "process.pid"))
+}
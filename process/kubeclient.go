@@ -0,0 +1,301 @@
+package process
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	defaultNamespace  = "default"
+)
+
+// kubeClient is a minimal client of the Kubernetes API to scale a StatefulSet or Deployment
+type kubeClient struct {
+	server    string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// workloadStatus is the replica status of a StatefulSet or Deployment
+type workloadStatus struct {
+	Spec struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		Replicas      int `json:"replicas"`
+		ReadyReplicas int `json:"readyReplicas"`
+	} `json:"status"`
+}
+
+// kubeconfig is the subset of a kubeconfig file needed to connect to a cluster
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// newKubeClient returns a client authenticated using the kubeconfig file if set, or else the in-cluster service account
+func newKubeClient(cfg *config.Kubernetes) (*kubeClient, error) {
+	var (
+		kc  *kubeClient
+		err error
+	)
+	if cfg.Kubeconfig != "" {
+		kc, err = newKubeconfigClient(cfg.Kubeconfig, cfg.Context)
+	} else {
+		kc, err = newInClusterClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Namespace != "" {
+		kc.namespace = cfg.Namespace
+	}
+	if kc.namespace == "" {
+		kc.namespace = defaultNamespace
+	}
+	return kc, nil
+}
+
+func newInClusterClient() (*kubeClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	}
+	token, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+	caPem, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, err := kubeTLSConfig(caPem, false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	kc := &kubeClient{
+		server: "https://" + net.JoinHostPort(host, port),
+		token:  strings.TrimSpace(string(token)),
+		client: newKubeHttpClient(tlsCfg),
+	}
+	if ns, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "namespace")); err == nil {
+		kc.namespace = strings.TrimSpace(string(ns))
+	}
+	return kc, nil
+}
+
+func newKubeconfigClient(file, context string) (*kubeClient, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var kcfg kubeconfig
+	if err := yaml.Unmarshal(b, &kcfg); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig %s: %v", file, err)
+	}
+	if context == "" {
+		context = kcfg.CurrentContext
+	}
+
+	kc := &kubeClient{}
+	var clusterName, userName string
+	found := false
+	for _, c := range kcfg.Contexts {
+		if c.Name == context {
+			clusterName, userName, kc.namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig %s", context, file)
+	}
+
+	// relative paths in a kubeconfig are relative to the kubeconfig file
+	dir := filepath.Dir(file)
+	readFileOrData := func(path, data string) ([]byte, error) {
+		if data != "" {
+			return base64.StdEncoding.DecodeString(data)
+		}
+		if path == "" {
+			return nil, nil
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return ioutil.ReadFile(path)
+	}
+
+	var (
+		caPem, certPem, keyPem []byte
+		insecure               bool
+	)
+	found = false
+	for _, c := range kcfg.Clusters {
+		if c.Name == clusterName {
+			kc.server = strings.TrimSuffix(c.Cluster.Server, "/")
+			insecure = c.Cluster.InsecureSkipTLSVerify
+			if caPem, err = readFileOrData(c.Cluster.CertificateAuthority, c.Cluster.CertificateAuthorityData); err != nil {
+				return nil, err
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig %s", clusterName, file)
+	}
+	for _, u := range kcfg.Users {
+		if u.Name == userName {
+			kc.token = u.User.Token
+			if kc.token == "" && u.User.TokenFile != "" {
+				token, err := readFileOrData(u.User.TokenFile, "")
+				if err != nil {
+					return nil, err
+				}
+				kc.token = strings.TrimSpace(string(token))
+			}
+			if certPem, err = readFileOrData(u.User.ClientCertificate, u.User.ClientCertificateData); err != nil {
+				return nil, err
+			}
+			if keyPem, err = readFileOrData(u.User.ClientKey, u.User.ClientKeyData); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tlsCfg, err := kubeTLSConfig(caPem, insecure, certPem, keyPem)
+	if err != nil {
+		return nil, err
+	}
+	kc.client = newKubeHttpClient(tlsCfg)
+	return kc, nil
+}
+
+func kubeTLSConfig(caPem []byte, insecure bool, certPem, keyPem []byte) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecure,
+	}
+	if len(caPem) != 0 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPem) {
+			return nil, errors.New("invalid kubernetes certificate authority")
+		}
+		tlsCfg.RootCAs = certPool
+	}
+	if len(certPem) != 0 {
+		cert, err := tls.X509KeyPair(certPem, keyPem)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+func newKubeHttpClient(tlsCfg *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsCfg,
+		},
+	}
+}
+
+// workloadUrl returns the url of the StatefulSet or Deployment
+func (kc *kubeClient) workloadUrl(cfg *config.Kubernetes) string {
+	resource := "deployments"
+	if cfg.IsStatefulSet() {
+		resource = "statefulsets"
+	}
+	return fmt.Sprintf("%s/apis/apps/v1/namespaces/%s/%s/%s", kc.server, kc.namespace, resource, cfg.Name)
+}
+
+// scale sets the number of replicas of the workload using its scale subresource
+func (kc *kubeClient) scale(cfg *config.Kubernetes, replicas int) error {
+	body := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	return kc.do(http.MethodPatch, kc.workloadUrl(cfg)+"/scale", body, nil)
+}
+
+// status returns the replica status of the workload
+func (kc *kubeClient) status(cfg *config.Kubernetes) (*workloadStatus, error) {
+	var s workloadStatus
+	if err := kc.do(http.MethodGet, kc.workloadUrl(cfg), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (kc *kubeClient) do(method, url string, body []byte, res interface{}) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if method == http.MethodPatch {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	if kc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+kc.token)
+	}
+	resp, err := kc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kubernetes api %s %s failed: %s: %s", method, url, resp.Status, strings.TrimSpace(string(b)))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.Unmarshal(b, res)
+}
@@ -0,0 +1,160 @@
+package process
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
+)
+
+// kubePollInterval is the interval at which the workload status is checked while waiting for pods
+var kubePollInterval = time.Second
+
+// KubernetesControl represents process control for a StatefulSet or Deployment in a kubernetes cluster.
+// The workload is stopped by scaling it to 0 replicas and started by scaling it back to the configured replicas.
+// Pod readiness is checked with the kubernetes api. The upcheck is used if the kubernetes api is not available.
+type KubernetesControl struct {
+	cfg     *config.Process
+	status  bool
+	client  *http.Client
+	kube    *kubeClient
+	muxLock sync.Mutex
+}
+
+func NewKubernetesProcess(c *http.Client, p *config.Process, s bool) Process {
+	kc := &KubernetesControl{cfg: p, status: s, client: c}
+	kube, err := newKubeClient(p.KubernetesCfg)
+	if err != nil {
+		log.Error("kubernetes client creation failed", "name", p.Name, "err", err)
+	}
+	kc.kube = kube
+	kc.UpdateStatus()
+	log.Debug("kubernetes process created", "name", kc.cfg.Name)
+	return kc
+}
+
+func (kc *KubernetesControl) setStatus(s bool) {
+	kc.status = s
+	log.Debug("setStatus - process "+kc.cfg.Name, "status", kc.status)
+}
+
+// Status implements Process.Status
+func (kc *KubernetesControl) Status() bool {
+	return kc.status
+}
+
+// UpdateStatus implements Process.UpdateStatus
+func (kc *KubernetesControl) UpdateStatus() bool {
+	s, err := IsProcessUp(kc.client, kc.cfg.UpcheckCfg)
+	if err != nil {
+		kc.setStatus(false)
+		log.Error("UpdateStatus - kubernetes process is down", "err", err)
+	} else {
+		kc.setStatus(s)
+	}
+	log.Debug("UpdateStatus", "name", kc.cfg.Name, "return", kc.status)
+	return kc.status
+}
+
+// Stop implements Process.Stop
+func (kc *KubernetesControl) Stop() (err error) {
+	defer kc.muxLock.Unlock()
+	kc.muxLock.Lock()
+	if !kc.status {
+		log.Info("Stop - process is already down", "name", kc.cfg.Name)
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(kc.cfg.Name, "stop", start, err == nil)
+	}()
+
+	if err := kc.scale(0); err != nil {
+		log.Error("Stop - kubernetes scale down failed", "name", kc.cfg.Name, "workload", kc.cfg.KubernetesCfg.Name, "err", err)
+		return err
+	}
+	log.Info("Stop - kubernetes workload scaled down", "name", kc.cfg.Name, "workload", kc.cfg.KubernetesCfg.Name)
+	if !kc.waitForPods(false) {
+		kc.setStatus(true)
+		log.Error("failed to stop " + kc.cfg.Name)
+		return fmt.Errorf("%s failed to stop", kc.cfg.Name)
+	}
+	kc.setStatus(false)
+	log.Debug("Stop - is down", "process", kc.cfg.Name, "status", kc.status)
+	return nil
+}
+
+// Start implements Process.Start
+func (kc *KubernetesControl) Start() (err error) {
+	defer kc.muxLock.Unlock()
+	kc.muxLock.Lock()
+	if kc.status {
+		log.Info("Start - process is already up", "name", kc.cfg.Name)
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(kc.cfg.Name, "start", start, err == nil)
+	}()
+
+	if err := kc.scale(kc.cfg.KubernetesCfg.ReplicasOrDefault()); err != nil {
+		log.Error("Start - kubernetes scale up failed", "name", kc.cfg.Name, "workload", kc.cfg.KubernetesCfg.Name, "err", err)
+		return err
+	}
+	log.Info("Start - kubernetes workload scaled up", "name", kc.cfg.Name, "workload", kc.cfg.KubernetesCfg.Name)
+	if !kc.waitForPods(true) {
+		kc.setStatus(false)
+		log.Error("Start - failed to start " + kc.cfg.Name)
+		return fmt.Errorf("%s failed to start", kc.cfg.Name)
+	}
+	kc.setStatus(true)
+	log.Debug("Start - is up", "process", kc.cfg.Name, "status", kc.status)
+	return nil
+}
+
+func (kc *KubernetesControl) scale(replicas int) error {
+	if kc.kube == nil {
+		kube, err := newKubeClient(kc.cfg.KubernetesCfg)
+		if err != nil {
+			return err
+		}
+		kc.kube = kube
+	}
+	return kc.kube.scale(kc.cfg.KubernetesCfg, replicas)
+}
+
+// waitForPods waits for the pods of the workload to be ready if up is true, or to be terminated if up is false,
+// and then for the upcheck to match. If the workload status cannot be fetched from the kubernetes api only the
+// upcheck is used.
+func (kc *KubernetesControl) waitForPods(up bool) bool {
+	deadline := time.Now().Add(time.Duration(kc.cfg.KubernetesCfg.ReadyTimeoutOrDefault()) * time.Second)
+	useApi := true
+	for {
+		done := false
+		if useApi {
+			s, err := kc.kube.status(kc.cfg.KubernetesCfg)
+			if err != nil {
+				log.Warn("waitForPods - unable to get workload status, falling back to upcheck", "name", kc.cfg.Name, "err", err)
+				useApi = false
+			} else if up {
+				done = s.Status.ReadyReplicas >= kc.cfg.KubernetesCfg.ReplicasOrDefault()
+				log.Debug("waitForPods - wait for ready "+kc.cfg.Name, "ready", s.Status.ReadyReplicas)
+			} else {
+				done = s.Status.Replicas == 0
+				log.Debug("waitForPods - wait for terminated "+kc.cfg.Name, "replicas", s.Status.Replicas)
+			}
+		}
+		if (done || !useApi) && kc.UpdateStatus() == up {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(kubePollInterval)
+	}
+}
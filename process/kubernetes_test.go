@@ -0,0 +1,196 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKubeApiServer is a fake kubernetes api server serving a single StatefulSet/Deployment. Scaling is applied
+// immediately and the upcheck is up when there are ready replicas.
+type fakeKubeApiServer struct {
+	*httptest.Server
+	token      string
+	mux        sync.Mutex
+	replicas   int
+	patches    []string
+	failStatus bool
+}
+
+func newFakeKubeApiServer(t *testing.T, path, token string) *fakeKubeApiServer {
+	f := &fakeKubeApiServer{token: token, replicas: 1}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+f.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mux.Lock()
+		defer f.mux.Unlock()
+		if f.failStatus {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"spec":{"replicas":%d},"status":{"replicas":%d,"readyReplicas":%d}}`, f.replicas, f.replicas, f.replicas)
+	})
+	mux.HandleFunc(path+"/scale", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+f.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPatch || r.Header.Get("Content-Type") != "application/merge-patch+json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		var patch struct {
+			Spec struct {
+				Replicas int `json:"replicas"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(b, &patch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.mux.Lock()
+		f.replicas = patch.Spec.Replicas
+		f.patches = append(f.patches, string(b))
+		f.mux.Unlock()
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/upcheck", func(w http.ResponseWriter, r *http.Request) {
+		f.mux.Lock()
+		defer f.mux.Unlock()
+		if f.replicas == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("UpCheck"))
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func writeKubeconfig(t *testing.T, server, token string) string {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ioutil.WriteFile(filepath.Join(dir, "token"), []byte(token+"\n"), 0600)
+
+	kubeconfig := fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+current-context: other
+clusters:
+- name: test-cluster
+  cluster:
+    server: %s
+contexts:
+- name: other
+  context:
+    cluster: missing
+    user: missing
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+    namespace: quorum
+users:
+- name: test-user
+  user:
+    tokenFile: token
+`, server)
+	path := filepath.Join(dir, "config")
+	require.NoError(t, ioutil.WriteFile(path, []byte(kubeconfig), 0600))
+	return path
+}
+
+func newTestKubernetesProcess(t *testing.T, kind, path string) (*KubernetesControl, *fakeKubeApiServer) {
+	kubePollInterval = 10 * time.Millisecond
+	f := newFakeKubeApiServer(t, path, "secret")
+	cfg := &config.Process{
+		Name:        "bcclnt",
+		ControlType: "kubernetes",
+		KubernetesCfg: &config.Kubernetes{
+			Kind:         kind,
+			Name:         "node1",
+			Kubeconfig:   writeKubeconfig(t, f.URL, "secret"),
+			Context:      "test",
+			ReadyTimeout: 2,
+		},
+		UpcheckCfg: &config.Upcheck{
+			UpcheckUrl: f.URL + "/upcheck",
+			ReturnType: "string",
+			Method:     "GET",
+			Expected:   "UpCheck",
+		},
+	}
+	return NewKubernetesProcess(&http.Client{}, cfg, false).(*KubernetesControl), f
+}
+
+func TestKubernetesProcess_StatefulSet_StopStart(t *testing.T) {
+	kc, f := newTestKubernetesProcess(t, "statefulset", "/apis/apps/v1/namespaces/quorum/statefulsets/node1")
+	require.True(t, kc.Status())
+
+	require.NoError(t, kc.Stop())
+	require.False(t, kc.Status())
+
+	require.NoError(t, kc.Start())
+	require.True(t, kc.Status())
+
+	require.Equal(t, []string{`{"spec":{"replicas":0}}`, `{"spec":{"replicas":1}}`}, f.patches)
+}
+
+func TestKubernetesProcess_Deployment_NamespaceOverride(t *testing.T) {
+	kc, f := newTestKubernetesProcess(t, "deployment", "/apis/apps/v1/namespaces/nodes/deployments/node1")
+	kc.cfg.KubernetesCfg.Namespace = "nodes"
+	kc.cfg.KubernetesCfg.Replicas = 2
+	kc.kube = nil
+
+	require.NoError(t, kc.Stop())
+	require.NoError(t, kc.Start())
+
+	require.Equal(t, []string{`{"spec":{"replicas":0}}`, `{"spec":{"replicas":2}}`}, f.patches)
+}
+
+func TestKubernetesProcess_FallsBackToUpcheck(t *testing.T) {
+	kc, f := newTestKubernetesProcess(t, "statefulset", "/apis/apps/v1/namespaces/quorum/statefulsets/node1")
+	f.failStatus = true
+
+	require.NoError(t, kc.Stop())
+	require.False(t, kc.Status())
+}
+
+func TestKubernetesProcess_ScaleFails(t *testing.T) {
+	kc, f := newTestKubernetesProcess(t, "statefulset", "/apis/apps/v1/namespaces/quorum/statefulsets/node1")
+	f.token = "other"
+
+	err := kc.Stop()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401 Unauthorized")
+	require.True(t, kc.Status())
+}
+
+func TestNewKubeClient_UnknownContext(t *testing.T) {
+	_, err := newKubeClient(&config.Kubernetes{
+		Kind:       "statefulset",
+		Name:       "node1",
+		Kubeconfig: writeKubeconfig(t, "http://localhost", "secret"),
+		Context:    "unknown",
+	})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `context "unknown" not found`)
+}
@@ -0,0 +1,103 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingFile is a log file which is rotated when it reaches maxSize bytes.
+// Rotated files are named <path>.1 (most recent) to <path>.<maxFiles>. Older files are removed.
+type rotatingFile struct {
+	path     string
+	maxSize  int64 // 0 disables rotation
+	maxFiles int
+	f        *os.File
+	size     int64
+	mux      sync.Mutex
+}
+
+func newRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	rf := &rotatingFile{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.f = f
+	rf.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer. The file is rotated before writing p if p would take it over maxSize.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mux.Lock()
+	defer rf.mux.Unlock()
+	if rf.f == nil {
+		return 0, os.ErrClosed
+	}
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return err
+	}
+	rf.f = nil
+	if rf.maxFiles == 0 {
+		if err := os.Remove(rf.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		if err := os.Remove(rotatedName(rf.path, rf.maxFiles)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for i := rf.maxFiles - 1; i >= 1; i-- {
+			if err := os.Rename(rotatedName(rf.path, i), rotatedName(rf.path, i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(rf.path, rotatedName(rf.path, 1)); err != nil {
+			return err
+		}
+	}
+	return rf.open()
+}
+
+// Close implements io.Closer
+func (rf *rotatingFile) Close() error {
+	rf.mux.Lock()
+	defer rf.mux.Unlock()
+	if rf.f == nil {
+		return nil
+	}
+	err := rf.f.Close()
+	rf.f = nil
+	return err
+}
+
+func rotatedName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
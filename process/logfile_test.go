@@ -0,0 +1,54 @@
+package process
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_Rotates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotatingfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "process.log")
+
+	rf, err := newRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := rf.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, rf.Close())
+
+	readFile := func(name string) string {
+		b, err := ioutil.ReadFile(name)
+		require.NoError(t, err)
+		return string(b)
+	}
+	require.Equal(t, "fourth\n", readFile(path))
+	require.Equal(t, "third\n", readFile(path+".1"))
+	require.Equal(t, "second\n", readFile(path+".2"))
+	require.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFile_Appends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotatingfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "process.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("existing\n"), 0644))
+
+	rf, err := newRotatingFile(path, 0, 0)
+	require.NoError(t, err)
+	_, err = rf.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, rf.Close())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "existing\nnew\n", string(b))
+}
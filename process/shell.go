@@ -9,6 +9,7 @@ import (
 	"github.com/ConsenSys/quorum-hibernate/config"
 
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 )
 
 // ShellProcessControl represents process control for a shell process
@@ -53,13 +54,17 @@ func (sp *ShellProcessControl) UpdateStatus() bool {
 }
 
 // Status implements Process.Stop
-func (sp *ShellProcessControl) Stop() error {
+func (sp *ShellProcessControl) Stop() (err error) {
 	defer sp.muxLock.Unlock()
 	sp.muxLock.Lock()
 	if !sp.status {
 		log.Debug("Stop - process is already down", "name", sp.cfg.Name)
 		return nil
 	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(sp.cfg.Name, "stop", start, err == nil)
+	}()
 	if err := ExecuteShellCommand(sp.cfg.StopCommand); err == nil {
 		if sp.WaitToBeDown() {
 			sp.setStatus(false)
@@ -78,13 +83,17 @@ func (sp *ShellProcessControl) Stop() error {
 }
 
 // Status implements Process.Start
-func (sp *ShellProcessControl) Start() error {
+func (sp *ShellProcessControl) Start() (err error) {
 	defer sp.muxLock.Unlock()
 	sp.muxLock.Lock()
 	if sp.status {
 		log.Info("Start - process is already up", "name", sp.cfg.Name)
 		return nil
 	}
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProcessAction(sp.cfg.Name, "start", start, err == nil)
+	}()
 	if err := ExecuteShellCommand(sp.cfg.StartCommand); err == nil {
 		//wait for process to come up
 		if sp.WaitToComeUp() {
@@ -0,0 +1,272 @@
+package proxy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+var ErrUnauthorized = errors.New("unauthorized")
+
+// authenticator authenticates the clients of a proxy using api keys, bearer JWTs or tls client certificates
+type authenticator struct {
+	cfg     *config.ProxyAuth
+	apiKeys []*config.APIKey
+	jwks    map[string]crypto.PublicKey // JWT validation keys by key id
+}
+
+func newAuthenticator(cfg *config.ProxyAuth) (*authenticator, error) {
+	a := &authenticator{cfg: cfg, apiKeys: cfg.APIKeys}
+	if cfg.JWKSFile != "" {
+		jwks, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwks file %s: %v", cfg.JWKSFile, err)
+		}
+		a.jwks = jwks
+	}
+	return a, nil
+}
+
+// authenticate returns the identity of the client making req.
+// It returns ErrUnauthorized if the client is not authenticated by any of the configured methods.
+func (a *authenticator) authenticate(req *http.Request) (string, error) {
+	if key := req.Header.Get(apiKeyHeader); key != "" && len(a.apiKeys) != 0 {
+		for _, k := range a.apiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(k.Key)) == 1 {
+				return "apikey:" + k.Name, nil
+			}
+		}
+		log.Debug("authenticate - invalid api key", "remoteAddr", req.RemoteAddr)
+	}
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") && a.jwks != nil {
+		sub, err := a.verifyJWT(strings.TrimPrefix(auth, "Bearer "))
+		if err == nil {
+			return "jwt:" + sub, nil
+		}
+		log.Debug("authenticate - invalid bearer token", "remoteAddr", req.RemoteAddr, "err", err)
+	}
+	// client certificates are verified by the tls server using the client ca
+	if a.cfg.ClientCert && req.TLS != nil && len(req.TLS.PeerCertificates) != 0 {
+		if cn := req.TLS.PeerCertificates[0].Subject.CommonName; cn != "" {
+			return "cert:" + cn, nil
+		}
+	}
+	return "", ErrUnauthorized
+}
+
+// verifyJWT verifies the signature and claims of token and returns its sub claim
+func (a *authenticator) verifyJWT(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", err
+	}
+	key, ok := a.jwks[header.Kid]
+	if !ok && header.Kid == "" && len(a.jwks) == 1 {
+		for _, k := range a.jwks {
+			key, ok = k, true
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("unknown key id %q", header.Kid)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return "", err
+	}
+
+	var claims struct {
+		Sub string          `json:"sub"`
+		Iss string          `json:"iss"`
+		Aud json.RawMessage `json:"aud"`
+		Exp *int64          `json:"exp"`
+		Nbf *int64          `json:"nbf"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	if claims.Exp != nil && now >= *claims.Exp {
+		return "", errors.New("token has expired")
+	}
+	if claims.Nbf != nil && now < *claims.Nbf {
+		return "", errors.New("token is not valid yet")
+	}
+	if a.cfg.Issuer != "" && claims.Iss != a.cfg.Issuer {
+		return "", fmt.Errorf("invalid issuer %q", claims.Iss)
+	}
+	if a.cfg.Audience != "" && !hasAudience(claims.Aud, a.cfg.Audience) {
+		return "", errors.New("invalid audience")
+	}
+	if claims.Sub == "" {
+		return "", errors.New("sub claim is not set")
+	}
+	return claims.Sub, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// hasAudience returns true if the aud claim, either a string or an array of strings, contains audience
+func hasAudience(aud json.RawMessage, audience string) bool {
+	var one string
+	if err := json.Unmarshal(aud, &one); err == nil {
+		return one == audience
+	}
+	var many []string
+	if err := json.Unmarshal(aud, &many); err != nil {
+		return false
+	}
+	for _, a := range many {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not valid for alg %q", alg)
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+	case strings.HasPrefix(alg, "ES"):
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not valid for alg %q", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported alg %q", alg)
+}
+
+// loadJWKS returns the RSA and EC keys in the JWKS file by key id
+func loadJWKS(file string) (map[string]crypto.PublicKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return nil, err
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("key %q has unsupported curve %q", k.Kid, k.Crv)
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, err
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, err
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		default:
+			log.Warn("loadJWKS - ignoring key with unsupported type", "kid", k.Kid, "kty", k.Kty)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no supported keys")
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,196 @@
+package proxy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// writeTestJWKS writes a JWKS file containing the public keys of rsaKey and ecKey
+func writeTestJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa1","n":"%s","e":"%s"},
+		{"kty":"EC","kid":"ec1","crv":"P-256","x":"%s","y":"%s"},
+		{"kty":"oct","kid":"hmac1","k":"c2VjcmV0"}
+	]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))),
+	)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(jwks), 0644))
+	return file
+}
+
+func signTestJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(sig)
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	a, err := newAuthenticator(&config.ProxyAuth{
+		APIKeys:    []*config.APIKey{{Name: "client1", Key: "secret1"}},
+		JWKSFile:   writeTestJWKS(t, rsaKey, ecKey),
+		Issuer:     "https://issuer",
+		Audience:   "node1",
+		ClientCert: true,
+	})
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	validClaims := map[string]interface{}{"sub": "user1", "iss": "https://issuer", "aud": []string{"node1", "node2"}, "exp": exp}
+
+	tests := []struct {
+		name     string
+		setup    func(req *http.Request)
+		want     string
+		wantFail bool
+	}{
+		{
+			name:  "api key",
+			setup: func(req *http.Request) { req.Header.Set(apiKeyHeader, "secret1") },
+			want:  "apikey:client1",
+		},
+		{
+			name:     "invalid api key",
+			setup:    func(req *http.Request) { req.Header.Set(apiKeyHeader, "secret2") },
+			wantFail: true,
+		},
+		{
+			name: "rsa jwt",
+			setup: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "RS256", "rsa1", rsaKey, validClaims))
+			},
+			want: "jwt:user1",
+		},
+		{
+			name: "ec jwt",
+			setup: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "ES256", "ec1", ecKey, validClaims))
+			},
+			want: "jwt:user1",
+		},
+		{
+			name: "jwt signed with other key",
+			setup: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "RS256", "rsa1", otherKey, validClaims))
+			},
+			wantFail: true,
+		},
+		{
+			name: "expired jwt",
+			setup: func(req *http.Request) {
+				claims := map[string]interface{}{"sub": "user1", "iss": "https://issuer", "aud": "node1", "exp": time.Now().Add(-time.Minute).Unix()}
+				req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "RS256", "rsa1", rsaKey, claims))
+			},
+			wantFail: true,
+		},
+		{
+			name: "jwt with wrong issuer",
+			setup: func(req *http.Request) {
+				claims := map[string]interface{}{"sub": "user1", "iss": "https://other", "aud": "node1", "exp": exp}
+				req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "RS256", "rsa1", rsaKey, claims))
+			},
+			wantFail: true,
+		},
+		{
+			name: "jwt with wrong audience",
+			setup: func(req *http.Request) {
+				claims := map[string]interface{}{"sub": "user1", "iss": "https://issuer", "aud": "node3", "exp": exp}
+				req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "RS256", "rsa1", rsaKey, claims))
+			},
+			wantFail: true,
+		},
+		{
+			name: "jwt with unsupported alg",
+			setup: func(req *http.Request) {
+				header := b64([]byte(`{"alg":"none","kid":"rsa1"}`))
+				payload, _ := json.Marshal(validClaims)
+				req.Header.Set("Authorization", "Bearer "+header+"."+b64(payload)+".")
+			},
+			wantFail: true,
+		},
+		{
+			name: "client certificate",
+			setup: func(req *http.Request) {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client2"}}}}
+			},
+			want: "cert:client2",
+		},
+		{
+			name:     "no credentials",
+			setup:    func(req *http.Request) {},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			tt.setup(req)
+
+			got, err := a.authenticate(req)
+
+			if tt.wantFail {
+				require.Equal(t, ErrUnauthorized, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator_InvalidJWKSFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"keys":[]}`), 0644))
+
+	_, err := newAuthenticator(&config.ProxyAuth{JWKSFile: file})
+
+	require.EqualError(t, err, fmt.Sprintf("invalid jwks file %s: no supported keys", file))
+}
@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+// mutableBlockTags are block parameters whose responses change as new blocks are added and so are not cached
+var mutableBlockTags = map[string]bool{
+	"latest":    true,
+	"pending":   true,
+	"safe":      true,
+	"finalized": true,
+}
+
+// cacheKeyCtxKey is the request context key holding the cache key of a request forwarded upstream
+type cacheKeyCtxKey struct{}
+
+// responseCache is a LRU cache of JSON-RPC results. It is filled from upstream responses while the node is up
+// and is used to answer requests while the node is down.
+type responseCache struct {
+	cfg     *config.ResponseCache
+	ttl     time.Duration
+	entries map[string]*list.Element
+	lru     *list.List // most recently used entry at the front
+	mux     sync.Mutex
+}
+
+type cacheEntry struct {
+	key    string
+	result json.RawMessage
+	expiry time.Time
+}
+
+func newResponseCache(cfg *config.ResponseCache) *responseCache {
+	return &responseCache{
+		cfg:     cfg,
+		ttl:     time.Duration(cfg.TTL) * time.Second,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// key returns the cache key and id of the JSON-RPC request in body and true if its response can be cached.
+// Batch requests and requests with mutable block parameters, e.g. latest, are not cached.
+func (c *responseCache) key(body []byte) (string, json.RawMessage, bool) {
+	if b := bytes.TrimLeft(body, " \t\r\n"); len(b) == 0 || b[0] != '{' {
+		return "", nil, false
+	}
+	var req struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || !c.cfg.IsCached(req.Method) {
+		return "", nil, false
+	}
+	var params []interface{}
+	if len(req.Params) != 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return "", nil, false
+		}
+	}
+	for _, p := range params {
+		if s, ok := p.(string); ok && mutableBlockTags[s] {
+			return "", nil, false
+		}
+	}
+	// re-encode the params so that requests differing only in whitespace share an entry
+	b, err := json.Marshal(params)
+	if err != nil {
+		return "", nil, false
+	}
+	return req.Method + string(b), req.ID, true
+}
+
+// response returns the JSON-RPC response with the given id for the cached result of key if it has not expired
+func (c *responseCache) response(key string, id json.RawMessage) ([]byte, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if time.Now().After(entry.expiry) {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	b, err := json.Marshal(struct {
+		JsonRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  json.RawMessage `json:"result"`
+	}{"2.0", id, entry.result})
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// add caches result for key, evicting the least recently used entry if the cache is full
+func (c *responseCache) add(key string, result json.RawMessage) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	expiry := time.Now().Add(c.ttl)
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		entry.result, entry.expiry = result, expiry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, result: result, expiry: expiry})
+	if c.lru.Len() > c.cfg.Size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// store caches the result of an upstream response to a request with a cache key.
+// Error responses, null results and encoded responses are not cached.
+func (c *responseCache) store(res *http.Response) error {
+	key, ok := res.Request.Context().Value(cacheKeyCtxKey{}).(string)
+	if !ok || res.StatusCode != http.StatusOK {
+		return nil
+	}
+	if enc := res.Header.Get("Content-Encoding"); enc != "" && enc != "identity" {
+		return nil
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil || len(resp.Error) != 0 || len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return nil
+	}
+	c.add(key, resp.Result)
+	log.Debug("responseCache - response cached", "key", key)
+	return nil
+}
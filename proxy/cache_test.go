@@ -0,0 +1,152 @@
+package proxy
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResponseCache(size int) *responseCache {
+	return newResponseCache(&config.ResponseCache{
+		Methods: []string{"eth_chainId", "eth_getBlockByNumber"},
+		Size:    size,
+		TTL:     60,
+	})
+}
+
+func TestResponseCache_Key(t *testing.T) {
+	c := newTestResponseCache(10)
+
+	tests := []struct {
+		name, body string
+		wantOk     bool
+	}{
+		{
+			name:   "cached method",
+			body:   `{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":1}`,
+			wantOk: true,
+		},
+		{
+			name:   "block number",
+			body:   `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x1", false],"id":1}`,
+			wantOk: true,
+		},
+		{
+			name:   "mutable block tag",
+			body:   `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["latest", false],"id":1}`,
+			wantOk: false,
+		},
+		{
+			name:   "method not cached",
+			body:   `{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`,
+			wantOk: false,
+		},
+		{
+			name:   "batch",
+			body:   `[{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":1}]`,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, ok := c.key([]byte(tt.body))
+			require.Equal(t, tt.wantOk, ok)
+		})
+	}
+
+	k1, _, _ := c.key([]byte(`{"method":"eth_getBlockByNumber","params":["0x1",false],"id":1}`))
+	k2, _, _ := c.key([]byte(`{"method":"eth_getBlockByNumber", "params": [ "0x1", false ], "id":2}`))
+	require.Equal(t, k1, k2)
+}
+
+func TestResponseCache_ResponseUsesRequestId(t *testing.T) {
+	c := newTestResponseCache(10)
+	c.add("eth_chainId[]", []byte(`"0x539"`))
+
+	got, ok := c.response("eth_chainId[]", []byte(`"abc"`))
+
+	require.True(t, ok)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":"abc","result":"0x539"}`, string(got))
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTestResponseCache(2)
+	c.add("a", []byte(`1`))
+	c.add("b", []byte(`2`))
+	_, ok := c.response("a", nil)
+	require.True(t, ok)
+
+	c.add("c", []byte(`3`))
+
+	_, ok = c.response("a", nil)
+	require.True(t, ok)
+	_, ok = c.response("b", nil)
+	require.False(t, ok, "least recently used entry should have been evicted")
+	_, ok = c.response("c", nil)
+	require.True(t, ok)
+}
+
+func TestResponseCache_Expired(t *testing.T) {
+	c := newTestResponseCache(2)
+	c.ttl = time.Millisecond
+	c.add("a", []byte(`1`))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := c.response("a", nil)
+	require.False(t, ok)
+	require.Equal(t, 0, c.lru.Len())
+}
+
+func TestResponseCache_Store(t *testing.T) {
+	tests := []struct {
+		name, body string
+		wantCached bool
+	}{
+		{
+			name:       "result",
+			body:       `{"jsonrpc":"2.0","id":1,"result":"0x539"}`,
+			wantCached: true,
+		},
+		{
+			name:       "error",
+			body:       `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"failed"}}`,
+			wantCached: false,
+		},
+		{
+			name:       "null result",
+			body:       `{"jsonrpc":"2.0","id":1,"result":null}`,
+			wantCached: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestResponseCache(10)
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), cacheKeyCtxKey{}, "eth_chainId[]"))
+			res := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+				Request:    req,
+			}
+
+			require.NoError(t, c.store(res))
+
+			_, ok := c.response("eth_chainId[]", nil)
+			require.Equal(t, tt.wantCached, ok)
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.body, string(body), "response body should be unchanged")
+		})
+	}
+}
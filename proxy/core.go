@@ -1,6 +1,8 @@
 package proxy
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -27,6 +29,7 @@ type Proxy interface {
 var (
 	ErrParticipantsDown = errors.New(core.SomeParticipantsDown)
 	ErrNodeNotReady     = errors.New(core.NodeIsNotReadyToAcceptRequest)
+	ErrNodeHibernated   = errors.New(core.NodeIsHibernated)
 )
 
 func MakeProxyServices(qn *node.NodeControl, errc chan error) ([]Proxy, error) {
@@ -44,13 +47,37 @@ func MakeProxyServices(qn *node.NodeControl, errc chan error) ([]Proxy, error) {
 // HandlePrivateTx helps with processing private transactions.
 // if the body is a private transaction request it will get participants of the transaction and
 // wake them up via p2p rpc call.
+// if the body is a batch request the participants of all private transactions in the batch are woken up
+// with a single p2p rpc call to each of them.
 // if body is not a private transaction it will return nil.
 func HandlePrivateTx(body []byte, ps *ProxyServer) error {
-	// TODO If privacy manager proxy works as expected, can this be removed?
-	participants, err := ps.nodeCtrl.GetTxHandler().IsPrivateTx(body)
+	txh := ps.nodeCtrl.GetTxHandler()
+	if txh == nil {
+		return nil
+	}
+	participants, err := txh.IsPrivateTx(body)
 	if err != nil {
 		return err
 	}
+	return prepareParticipants(participants, ps)
+}
+
+// HandlePrivateRequest processes private transactions sent to the proxy in http requests.
+// If the proxy uses a RequestTxHandler, e.g. for the Tessera REST API, the participants are found from the request
+// path, headers and body and woken up via p2p rpc call, else the body is handled by HandlePrivateTx.
+func HandlePrivateRequest(req *http.Request, body []byte, ps *ProxyServer) error {
+	if ps.reqTxh == nil {
+		return HandlePrivateTx(body, ps)
+	}
+	participants, err := ps.reqTxh.IsPrivateRequest(req.URL.Path, req.Header, body)
+	if err != nil {
+		return err
+	}
+	return prepareParticipants(participants, ps)
+}
+
+// prepareParticipants wakes up the participants of a private transaction via p2p rpc call
+func prepareParticipants(participants []string, ps *ProxyServer) error {
 	if participants != nil {
 		log.Info("HandlePrivateTx - participants", "keys", participants)
 		if status, err := ps.nodeCtrl.PrepareNodeHibernatorForPrivateTx(participants); err != nil {
@@ -64,8 +91,42 @@ func HandlePrivateTx(body []byte, ps *ProxyServer) error {
 	return nil
 }
 
-func logRequestPayload(req *http.Request, name string, destUrl string, body string) {
-	log.Info("Request received", "name", name, "path", req.RequestURI, "remoteAddr", req.RemoteAddr, "destUrl", destUrl, "body", body)
+// jsonRpcMethods returns the methods of the JSON-RPC request or batch request in body.
+// It returns nil if body is not a JSON-RPC request.
+func jsonRpcMethods(body []byte) []string {
+	type rpcReq struct {
+		Method string `json:"method"`
+	}
+	var msgs []json.RawMessage
+	if b := bytes.TrimLeft(body, " \t\r\n"); len(b) > 0 && b[0] == '[' {
+		if err := json.Unmarshal(b, &msgs); err != nil {
+			return nil
+		}
+	} else {
+		msgs = []json.RawMessage{body}
+	}
+	var methods []string
+	for _, m := range msgs {
+		var r rpcReq
+		if err := json.Unmarshal(m, &r); err == nil && r.Method != "" {
+			methods = append(methods, r.Method)
+		}
+	}
+	return methods
+}
+
+// maxLoggedBodySize is the maximum number of bytes of a request body that are logged
+const maxLoggedBodySize = 256
+
+// logRequestPayload logs a request received by a proxy. The body is only logged at debug level and is truncated to
+// maxLoggedBodySize bytes, as it may be large and contain signed transactions.
+func logRequestPayload(req *http.Request, name string, destUrl string, body []byte, methods []string) {
+	log.Info("Request received", "name", name, "path", req.RequestURI, "remoteAddr", req.RemoteAddr, "destUrl", destUrl, "size", len(body), "methods", methods)
+	truncated := len(body) > maxLoggedBodySize
+	if truncated {
+		body = body[:maxLoggedBodySize]
+	}
+	log.Debug("Request body", "name", name, "body", string(body), "truncated", truncated)
 }
 
 func copyHeader(dst, src http.Header) {
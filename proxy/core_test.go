@@ -0,0 +1,51 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ConsenSys/quorum-hibernate/node"
+	"github.com/ConsenSys/quorum-hibernate/privatetx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonRpcMethods(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "single request",
+			body: `{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`,
+			want: []string{"eth_blockNumber"},
+		},
+		{
+			name: "batch request",
+			body: ` [{"jsonrpc":"2.0","method":"net_peerCount","id":1}, 1, {"jsonrpc":"2.0","method":"eth_sendTransaction","id":2}]`,
+			want: []string{"net_peerCount", "eth_sendTransaction"},
+		},
+		{
+			name: "not json-rpc",
+			body: `hello`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, jsonRpcMethods([]byte(tt.body)))
+		})
+	}
+}
+
+func TestHandlePrivateRequest_Tessera(t *testing.T) {
+	ps := &ProxyServer{nodeCtrl: &node.NodeControl{}, reqTxh: privatetx.NewTesseraTxHandler()}
+
+	req := httptest.NewRequest(http.MethodPost, "/storeraw", nil)
+	require.NoError(t, HandlePrivateRequest(req, []byte(`{"payload":"cGF5bG9hZA==","from":"self"}`), ps))
+
+	req = httptest.NewRequest(http.MethodPost, "/send", nil)
+	require.Error(t, HandlePrivateRequest(req, []byte(`{"to":["key1"]`), ps))
+}
@@ -2,22 +2,39 @@ package proxy
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 )
 
+// ErrRequestTooLarge is returned for requests with a body larger than the proxy's maxBodySize
+var ErrRequestTooLarge = errors.New("request body too large")
+
 // makeHttpHandler returns a function to serve HTTP requests from clients
 func makeHttpHandler(ps *ProxyServer) (http.HandlerFunc, error) {
 
 	return func(res http.ResponseWriter, req *http.Request) {
-		if err := ps.nodeCtrl.IsNodeBusy(); err != nil {
-			http.Error(res, err.Error(), http.StatusInternalServerError)
+		metrics.IncProxyRequests(ps.proxyCfg.Name)
+		client, err := ps.clientIdentity(req)
+		if err != nil {
+			log.Info("httpHandler - client not authenticated", "name", ps.proxyCfg.Name, "remoteAddr", req.RemoteAddr)
+			ps.writeRPCError(res, nil, err)
 			return
 		}
 
-		body, err := ioutil.ReadAll(req.Body)
+		body, err := readBody(req, ps.proxyCfg.MaxBodySizeOrDefault())
+		if err == ErrRequestTooLarge {
+			log.Warn("httpHandler - request body too large", "name", ps.proxyCfg.Name, "path", req.RequestURI, "contentLength", req.ContentLength)
+			ps.writeRPCError(res, nil, err)
+			return
+		}
 		if err != nil {
 			const errMsg = "Reading request failed"
 			log.Error(errMsg, "name", ps.proxyCfg.Name, "path", req.RequestURI, "err", err)
@@ -27,33 +44,97 @@ func makeHttpHandler(ps *ProxyServer) (http.HandlerFunc, error) {
 
 		// you can reassign the body if you need to parse it as multipart
 		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		// allows the body to be resent when failing over to another upstream
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+		id := requestId(body)
 
-		if !ps.CanIgnoreRequest(req.RequestURI) {
-			logRequestPayload(req, ps.proxyCfg.Name, ps.proxyCfg.UpstreamAddr, string(body))
+		if err := ps.allowRequest(client); err != nil {
+			ps.writeRPCError(res, id, err)
+			return
+		}
+
+		dispatched := func() {}
+		if err := ps.nodeCtrl.IsNodeBusy(); err != nil {
+			if dispatched, err = ps.waitIfStarting(req.Context(), err); err != nil {
+				ps.writeRPCError(res, id, err)
+				return
+			}
+			defer dispatched()
+		}
+
+		if ps.cache != nil {
+			if key, id, ok := ps.cache.key(body); ok {
+				if ps.nodeCtrl.ClientStatus() == core.Down {
+					if resp, ok := ps.cache.response(key, id); ok {
+						log.Debug("httpHandler - answered from cache", "name", ps.proxyCfg.Name, "key", key)
+						metrics.IncProxyCacheHits(ps.proxyCfg.Name)
+						res.Header().Set("Content-Type", "application/json")
+						res.Write(resp)
+						return
+					}
+				}
+				req = req.WithContext(context.WithValue(req.Context(), cacheKeyCtxKey{}, key))
+			}
+		}
+
+		methods := jsonRpcMethods(body)
+		if !ps.CanIgnoreRequest(req.RequestURI) && ps.countsAsActivity(methods) {
+			logRequestPayload(req, ps.proxyCfg.Name, ps.proxyCfg.UpstreamAddr, body, methods)
 			ps.nodeCtrl.ResetInactiveSyncTime()
+		}
 
+		if !ps.CanIgnoreRequest(req.RequestURI) && ps.canWakeNode(methods) {
 			log.Info("httpHandler - request", "path", req.RequestURI)
 
-			if ps.nodeCtrl.PrepareClient() {
+			if err := ps.allowWake(client); err != nil {
+				ps.writeRPCError(res, id, err)
+				return
+			}
+
+			if ps.nodeCtrl.PrepareClient(history.TriggerProxyRequest) {
 				log.Debug("httpHandler - prepared to accept request")
 			} else {
 				log.Error("httpHandler - prepare node failed")
-				http.Error(res, ErrNodeNotReady.Error(), http.StatusInternalServerError)
+				ps.writeRPCError(res, id, ErrNodeNotReady)
 				return
 			}
 
 			if ps.nodeCtrl.WithPrivMan() {
-				if err := HandlePrivateTx(body, ps); err != nil {
+				if err := HandlePrivateRequest(req, body, ps); err != nil {
 					log.Error("httpHandler - handling pvt tx failed", "err", err)
-					http.Error(res, ErrParticipantsDown.Error(), http.StatusInternalServerError)
+					ps.writeRPCError(res, id, ErrParticipantsDown)
 					return
 				}
 			}
 
+		} else if !ps.CanIgnoreRequest(req.RequestURI) && ps.nodeCtrl.ClientStatus() == core.Down {
+			// the request would fail at the hibernated upstream
+			log.Debug("httpHandler - node is hibernated, request not allowed to wake node", "name", ps.proxyCfg.Name, "methods", methods)
+			ps.writeRPCError(res, id, ErrNodeHibernated)
+			return
 		}
 
 		// Forward request to original request
 		log.Debug("httpHandler - forwarding request to proxy")
+		dispatched()
 		ps.rp.ServeHTTP(res, req)
 	}, nil
 }
+
+// readBody reads the body of req. It returns ErrRequestTooLarge if the body is larger than max bytes, without
+// reading more than max+1 bytes.
+func readBody(req *http.Request, max int64) ([]byte, error) {
+	if req.ContentLength > max {
+		return nil, ErrRequestTooLarge
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > max {
+		return nil, ErrRequestTooLarge
+	}
+	return body, nil
+}
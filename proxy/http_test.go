@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBody(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantErr       error
+	}{
+		{name: "within limit", body: "0123456789", contentLength: 10},
+		{name: "content length exceeds limit", body: "01234567890", contentLength: 11, wantErr: ErrRequestTooLarge},
+		{name: "chunked within limit", body: "0123456789", contentLength: -1},
+		{name: "chunked exceeds limit", body: "01234567890", contentLength: -1, wantErr: ErrRequestTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", ioutil.NopCloser(strings.NewReader(tt.body)))
+			req.ContentLength = tt.contentLength
+
+			body, err := readBody(req, 10)
+
+			if tt.wantErr != nil {
+				require.Equal(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.body, string(body))
+			}
+		})
+	}
+}
+
+func TestHttpHandler_RequestTooLarge(t *testing.T) {
+	var forwarded bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { forwarded = true }))
+	defer upstream.Close()
+
+	ps, err := NewProxyServer(newUpNodeControl(), &config.Proxy{
+		Name:         "test",
+		Type:         "http",
+		UpstreamAddr: upstream.URL,
+		ProxyPaths:   []string{"/"},
+		ReadTimeout:  5,
+		WriteTimeout: 5,
+		MaxBodySize:  64,
+	}, nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	body := `{"jsonrpc":"2.0","method":"eth_sendRawTransaction","params":["0x` + strings.Repeat("ab", 64) + `"],"id":1}`
+	ps.(*ProxyServer).mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)))
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32007,"message":"request body too large"}}`, rec.Body.String())
+	require.False(t, forwarded)
+}
+
+func TestHttpHandler_NodeHibernatedAndRequestMayNotWake(t *testing.T) {
+	var forwarded bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { forwarded = true }))
+	defer upstream.Close()
+
+	newProxy := func(status core.ClientStatus) *ProxyServer {
+		nc := &node.NodeControl{}
+		nc.SetClntStatus(status)
+		ps, err := NewProxyServer(nc, &config.Proxy{
+			Name:                   "test",
+			Type:                   "http",
+			UpstreamAddr:           upstream.URL,
+			ProxyPaths:             []string{"/"},
+			IgnorePathsForActivity: []string{"/upcheck"},
+			ReadTimeout:            5,
+			WriteTimeout:           5,
+			WakeMethods:            &config.MethodFilter{Allow: []string{"eth_sendRawTransaction"}},
+			ActivityMethods:        &config.MethodFilter{Allow: []string{"eth_sendRawTransaction"}},
+		}, nil)
+		require.NoError(t, err)
+		return ps.(*ProxyServer)
+	}
+	body := `{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`
+
+	rec := httptest.NewRecorder()
+	newProxy(core.Down).mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32008,"message":"node is hibernated and the request is not allowed to wake it"}}`, rec.Body.String())
+	require.False(t, forwarded)
+
+	newProxy(core.Down).mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/upcheck", nil))
+	require.True(t, forwarded, "ignored paths must be forwarded")
+
+	// the client status is unknown until the status monitor has polled the node
+	forwarded = false
+	var unknown core.ClientStatus
+	newProxy(unknown).mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)))
+	require.True(t, forwarded, "requests must be forwarded if the node is not known to be down")
+}
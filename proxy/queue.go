@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+)
+
+var (
+	ErrQueueFull    = errors.New("node is being started and the request queue is full, try after sometime")
+	ErrQueueTimeout = errors.New("node did not start within the request queue max wait, try after sometime")
+)
+
+// queuePollInterval is the interval at which the queue checks if the node is ready to accept requests
+var queuePollInterval = 200 * time.Millisecond
+
+// requestQueue holds requests received while the node is being started.
+// Requests are released in the order they were received once the node is ready to accept requests.
+// A request is released only after the previous request has been dispatched upstream.
+type requestQueue struct {
+	name      string
+	maxLength int
+	maxWait   time.Duration
+	isReady   func() bool // returns true if the node is ready to accept requests
+	waiters   []*queuedRequest
+	running   bool // indicates if the dispatcher is running
+	mux       sync.Mutex
+}
+
+// queuedRequest is a request waiting in the queue
+type queuedRequest struct {
+	releaseCh    chan struct{} // closed when the request is released
+	dispatchedCh chan struct{} // closed when the released request has been dispatched upstream
+	dispatched   sync.Once
+}
+
+func newRequestQueue(name string, maxLength int, maxWait time.Duration, isReady func() bool) *requestQueue {
+	return &requestQueue{
+		name:      name,
+		maxLength: maxLength,
+		maxWait:   maxWait,
+		isReady:   isReady,
+	}
+}
+
+// wait holds the caller until the node is ready to accept requests and all requests queued before it have been
+// dispatched. It returns an error if the queue is full, the max wait is reached or ctx is done.
+// On success the returned function must be called once the request has been dispatched upstream.
+func (q *requestQueue) wait(ctx context.Context) (func(), error) {
+	q.mux.Lock()
+	if len(q.waiters) >= q.maxLength {
+		q.mux.Unlock()
+		log.Warn("requestQueue - queue is full", "name", q.name, "maxLength", q.maxLength)
+		return nil, ErrQueueFull
+	}
+	r := &queuedRequest{
+		releaseCh:    make(chan struct{}),
+		dispatchedCh: make(chan struct{}),
+	}
+	q.waiters = append(q.waiters, r)
+	log.Info("requestQueue - request queued", "name", q.name, "length", len(q.waiters))
+	if !q.running {
+		q.running = true
+		go q.dispatch()
+	}
+	q.mux.Unlock()
+
+	done := func() {
+		r.dispatched.Do(func() { close(r.dispatchedCh) })
+	}
+	timer := time.NewTimer(q.maxWait)
+	defer timer.Stop()
+	select {
+	case <-r.releaseCh:
+		return done, nil
+	case <-timer.C:
+		if q.remove(r) {
+			log.Warn("requestQueue - request timed out", "name", q.name, "maxWait", q.maxWait)
+			return nil, ErrQueueTimeout
+		}
+	case <-ctx.Done():
+		if q.remove(r) {
+			return nil, ctx.Err()
+		}
+	}
+	// the request was released at the same time
+	<-r.releaseCh
+	return done, nil
+}
+
+// remove removes r from the queue. It returns false if r is no longer queued.
+func (q *requestQueue) remove(r *queuedRequest) bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	for i, w := range q.waiters {
+		if w == r {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch waits for the node to be ready and then releases the queued requests in order, each after the previous
+// one has been dispatched. It returns when the queue is empty.
+func (q *requestQueue) dispatch() {
+	for {
+		q.mux.Lock()
+		if len(q.waiters) == 0 {
+			q.running = false
+			q.mux.Unlock()
+			return
+		}
+		q.mux.Unlock()
+
+		if !q.isReady() {
+			time.Sleep(queuePollInterval)
+			continue
+		}
+
+		q.mux.Lock()
+		if len(q.waiters) == 0 {
+			q.mux.Unlock()
+			continue
+		}
+		r := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		q.mux.Unlock()
+
+		close(r.releaseCh)
+		<-r.dispatchedCh
+	}
+}
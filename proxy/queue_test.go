@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	queuePollInterval = 10 * time.Millisecond
+}
+
+func TestRequestQueue_ReleasesInOrderWhenReady(t *testing.T) {
+	var ready int32
+	q := newRequestQueue("test", 3, 5*time.Second, func() bool { return atomic.LoadInt32(&ready) == 1 })
+
+	var (
+		mux   sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			done, err := q.wait(context.Background())
+			require.NoError(t, err)
+			mux.Lock()
+			order = append(order, i)
+			mux.Unlock()
+			done()
+		}(i)
+		// ensure requests are queued in order
+		require.Eventually(t, func() bool { return q.length() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	mux.Lock()
+	require.Empty(t, order, "requests must not be released before the node is ready")
+	mux.Unlock()
+
+	atomic.StoreInt32(&ready, 1)
+	wg.Wait()
+	require.Equal(t, []int{0, 1, 2}, order)
+}
+
+func TestRequestQueue_Full(t *testing.T) {
+	q := newRequestQueue("test", 1, time.Second, func() bool { return false })
+
+	go q.wait(context.Background())
+	require.Eventually(t, func() bool { return q.length() == 1 }, time.Second, time.Millisecond)
+
+	_, err := q.wait(context.Background())
+	require.Equal(t, ErrQueueFull, err)
+}
+
+func TestRequestQueue_Timeout(t *testing.T) {
+	q := newRequestQueue("test", 1, 50*time.Millisecond, func() bool { return false })
+
+	_, err := q.wait(context.Background())
+	require.Equal(t, ErrQueueTimeout, err)
+	require.Equal(t, 0, q.length())
+}
+
+func TestRequestQueue_ContextCancelled(t *testing.T) {
+	q := newRequestQueue("test", 1, 5*time.Second, func() bool { return false })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := q.wait(ctx)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 0, q.length())
+}
+
+func (q *requestQueue) length() int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	return len(q.waiters)
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+)
+
+var (
+	ErrRateLimited     = errors.New("rate limit exceeded, try after sometime")
+	ErrWakeRateLimited = errors.New("node wake rate limit exceeded, try after sometime")
+)
+
+// rateLimitPruneInterval is the interval at which idle clients are removed from a rate limiter
+const rateLimitPruneInterval = time.Minute
+
+// rateLimiter is a token bucket rate limiter per client identity
+type rateLimiter struct {
+	rate      float64 // tokens added per second
+	burst     float64 // maximum tokens
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	mux       sync.Mutex
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time // time at which tokens was last updated
+}
+
+func newRateLimiter(cfg *config.TokenBucket) *rateLimiter {
+	return &rateLimiter{
+		rate:      cfg.Rate,
+		burst:     float64(cfg.Burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// allow takes a token from the bucket of the client and returns true, or returns false if the bucket is empty
+func (l *rateLimiter) allow(client string) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	now := time.Now()
+	l.prune(now)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (l *rateLimiter) refill(b *tokenBucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Seconds()*l.rate
+	if tokens > l.burst {
+		return l.burst
+	}
+	return tokens
+}
+
+// prune removes the buckets that have been refilled as they are the same as new buckets
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for c, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, c)
+		}
+	}
+}
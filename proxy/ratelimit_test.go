@@ -0,0 +1,36 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	l := newRateLimiter(&config.TokenBucket{Rate: 1, Burst: 2})
+
+	require.True(t, l.allow("client1"))
+	require.True(t, l.allow("client1"))
+	require.False(t, l.allow("client1"), "burst should be exhausted")
+	require.True(t, l.allow("client2"), "clients should be limited separately")
+
+	// refill a token
+	l.buckets["client1"].last = l.buckets["client1"].last.Add(-time.Second)
+	require.True(t, l.allow("client1"))
+	require.False(t, l.allow("client1"))
+}
+
+func TestRateLimiter_PrunesIdleClients(t *testing.T) {
+	l := newRateLimiter(&config.TokenBucket{Rate: 1, Burst: 2})
+	require.True(t, l.allow("client1"))
+	require.True(t, l.allow("client2"))
+	l.buckets["client1"].last = l.buckets["client1"].last.Add(-time.Hour)
+	l.lastPrune = l.lastPrune.Add(-rateLimitPruneInterval)
+
+	require.True(t, l.allow("client2"))
+
+	require.NotContains(t, l.buckets, "client1")
+	require.Contains(t, l.buckets, "client2")
+}
@@ -0,0 +1,206 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
+	"github.com/ConsenSys/quorum-hibernate/log"
+
+	"github.com/gorilla/websocket"
+)
+
+// JSON-RPC error codes returned by the proxies when a request is not forwarded upstream
+const (
+	rpcErrCodeBusy             = -32001 // node is being shutdown
+	rpcErrCodeWaking           = -32002 // node is being started
+	rpcErrCodeParticipantsDown = -32003 // some participants of a private transaction are down
+	rpcErrCodeNotReady         = -32004 // hibernating or waking the node failed
+	rpcErrCodeRateLimited      = -32005 // client exceeded a rate limit
+	rpcErrCodeUnauthorized     = -32006 // client is not authenticated
+	rpcErrCodeTooLarge         = -32007 // request body exceeds the max body size
+	rpcErrCodeHibernated       = -32008 // node is hibernated and the request may not wake it
+	rpcErrCodeInternal         = -32603
+)
+
+// retryAfterSamples is the number of most recent lifecycle events used to estimate how long until a request can be retried
+const retryAfterSamples = 5
+
+// defaultRetryAfter is the estimate used for an event type which has not been recorded yet
+var defaultRetryAfter = 10 * time.Second
+
+// maxCloseReasonLen is the maximum length of a websocket close reason as the close frame payload is limited to 125 bytes
+const maxCloseReasonLen = 123
+
+// rpcError is an error returned to a client in a JSON-RPC error response
+type rpcError struct {
+	code       int
+	status     int // http status code
+	message    string
+	retryAfter int // seconds after which the request can be retried. 0 if the request should not be retried as is
+}
+
+// newRPCError returns the JSON-RPC error for err. For errors raised while the node is being shutdown or started,
+// or participants are being woken, the retry after time is estimated from the durations of recent hibernations
+// and wake ups.
+func (ps *ProxyServer) newRPCError(err error) *rpcError {
+	e := &rpcError{code: rpcErrCodeInternal, status: http.StatusInternalServerError, message: err.Error()}
+	var retryEvents []history.EventType
+	switch err {
+	case core.ErrNodeBeingShutdown:
+		// the node has to be shutdown and then woken before the request can be served
+		e.code, e.status = rpcErrCodeBusy, http.StatusServiceUnavailable
+		retryEvents = []history.EventType{history.Hibernated, history.Woken}
+	case core.ErrNodeBeingStarted, ErrQueueFull, ErrQueueTimeout:
+		e.code, e.status = rpcErrCodeWaking, http.StatusServiceUnavailable
+		retryEvents = []history.EventType{history.Woken}
+	case ErrParticipantsDown:
+		e.code, e.status = rpcErrCodeParticipantsDown, http.StatusServiceUnavailable
+		retryEvents = []history.EventType{history.Woken}
+	case ErrNodeNotReady:
+		e.code = rpcErrCodeNotReady
+	case ErrNodeHibernated:
+		e.code, e.status = rpcErrCodeHibernated, http.StatusServiceUnavailable
+	case ErrRateLimited, ErrWakeRateLimited:
+		e.code, e.status = rpcErrCodeRateLimited, http.StatusTooManyRequests
+	case ErrUnauthorized:
+		e.code, e.status = rpcErrCodeUnauthorized, http.StatusUnauthorized
+	case ErrRequestTooLarge:
+		e.code, e.status = rpcErrCodeTooLarge, http.StatusRequestEntityTooLarge
+	}
+	if len(retryEvents) != 0 {
+		var d time.Duration
+		for _, t := range retryEvents {
+			d += averageDuration(ps.nodeCtrl.History(time.Time{}, time.Time{}, []history.EventType{t}))
+		}
+		e.retryAfter = int((d + time.Second - 1) / time.Second)
+		if e.retryAfter < 1 {
+			e.retryAfter = 1
+		}
+	}
+	return e
+}
+
+// averageDuration returns the average duration of the most recent events, or defaultRetryAfter if there are none
+func averageDuration(events []history.Event) time.Duration {
+	if len(events) == 0 {
+		return defaultRetryAfter
+	}
+	if len(events) > retryAfterSamples {
+		events = events[len(events)-retryAfterSamples:]
+	}
+	var total int64
+	for _, e := range events {
+		total += e.Duration
+	}
+	return time.Duration(total/int64(len(events))) * time.Millisecond
+}
+
+// response returns the JSON-RPC error response for the request with the given id.
+// If id is an array of ids, as returned by requestId for a batch request, it returns an array of error responses,
+// one for each request of the batch.
+func (e *rpcError) response(id json.RawMessage) []byte {
+	if len(id) != 0 && id[0] == '[' {
+		var ids []json.RawMessage
+		if err := json.Unmarshal(id, &ids); err == nil {
+			resps := make([]json.RawMessage, len(ids))
+			for i, id := range ids {
+				resps[i] = e.response(id)
+			}
+			b, _ := json.Marshal(resps)
+			return b
+		}
+		id = nil
+	}
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	type errObj struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Data    interface{} `json:"data,omitempty"`
+	}
+	obj := errObj{Code: e.code, Message: e.message}
+	if e.retryAfter > 0 {
+		obj.Data = map[string]int{"retryAfter": e.retryAfter}
+	}
+	b, _ := json.Marshal(struct {
+		JsonRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Error   errObj          `json:"error"`
+	}{"2.0", id, obj})
+	return b
+}
+
+// closeMessage returns the websocket close message for the error.
+// Errors that can be retried close the connection with 1013 (try again later).
+func (e *rpcError) closeMessage() []byte {
+	code, reason := websocket.CloseInternalServerErr, e.message
+	switch {
+	case e.retryAfter > 0:
+		code = websocket.CloseTryAgainLater
+		reason = fmt.Sprintf("%s, retry after %ds", e.message, e.retryAfter)
+	case e.code == rpcErrCodeInternal:
+		code = websocket.CloseNormalClosure
+	case e.status == http.StatusTooManyRequests || e.status == http.StatusUnauthorized:
+		code = websocket.ClosePolicyViolation
+	}
+	if len(reason) > maxCloseReasonLen {
+		reason = reason[:maxCloseReasonLen]
+	}
+	return websocket.FormatCloseMessage(code, reason)
+}
+
+// writeRPCError writes the JSON-RPC error response for err to the request with the given id.
+// Retry-After is set if the request can be retried later.
+func (ps *ProxyServer) writeRPCError(res http.ResponseWriter, id json.RawMessage, err error) {
+	e := ps.newRPCError(err)
+	log.Debug("writeRPCError - request failed", "name", ps.proxyCfg.Name, "code", e.code, "err", err, "retryAfter", e.retryAfter)
+	res.Header().Set("Content-Type", "application/json")
+	if e.retryAfter > 0 {
+		res.Header().Set("Retry-After", strconv.Itoa(e.retryAfter))
+	}
+	res.WriteHeader(e.status)
+	res.Write(e.response(id))
+}
+
+// requestId returns the id of the JSON-RPC request in body. For batch requests it returns a JSON array of the ids of
+// the requests in the batch, with null for requests without id. It returns nil for empty batches and bodies which
+// are not JSON-RPC requests.
+func requestId(body []byte) json.RawMessage {
+	b := bytes.TrimLeft(body, " \t\r\n")
+	if len(b) == 0 {
+		return nil
+	}
+	type request struct {
+		ID json.RawMessage `json:"id"`
+	}
+	switch b[0] {
+	case '{':
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			return nil
+		}
+		return req.ID
+	case '[':
+		var reqs []request
+		if err := json.Unmarshal(b, &reqs); err != nil || len(reqs) == 0 {
+			return nil
+		}
+		ids := make([]json.RawMessage, len(reqs))
+		for i, req := range reqs {
+			ids[i] = req.ID
+			if len(ids[i]) == 0 {
+				ids[i] = json.RawMessage("null")
+			}
+		}
+		id, _ := json.Marshal(ids)
+		return id
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
+	"github.com/ConsenSys/quorum-hibernate/node"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRPCError(t *testing.T) {
+	ps := &ProxyServer{nodeCtrl: &node.NodeControl{}, proxyCfg: &config.Proxy{Name: "test"}}
+
+	tests := []struct {
+		err            error
+		wantCode       int
+		wantStatus     int
+		wantRetryAfter int
+	}{
+		{core.ErrNodeBeingShutdown, rpcErrCodeBusy, http.StatusServiceUnavailable, 20},
+		{core.ErrNodeBeingStarted, rpcErrCodeWaking, http.StatusServiceUnavailable, 10},
+		{ErrQueueTimeout, rpcErrCodeWaking, http.StatusServiceUnavailable, 10},
+		{ErrParticipantsDown, rpcErrCodeParticipantsDown, http.StatusServiceUnavailable, 10},
+		{ErrNodeNotReady, rpcErrCodeNotReady, http.StatusInternalServerError, 0},
+		{ErrRateLimited, rpcErrCodeRateLimited, http.StatusTooManyRequests, 0},
+		{ErrUnauthorized, rpcErrCodeUnauthorized, http.StatusUnauthorized, 0},
+		{ErrRequestTooLarge, rpcErrCodeTooLarge, http.StatusRequestEntityTooLarge, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			e := ps.newRPCError(tt.err)
+			require.Equal(t, tt.wantCode, e.code)
+			require.Equal(t, tt.wantStatus, e.status)
+			require.Equal(t, tt.wantRetryAfter, e.retryAfter)
+			require.Equal(t, tt.err.Error(), e.message)
+		})
+	}
+}
+
+func TestAverageDuration(t *testing.T) {
+	require.Equal(t, defaultRetryAfter, averageDuration(nil))
+
+	var events []history.Event
+	// only the most recent events are used
+	for _, d := range []int64{100000, 100000, 1000, 2000, 3000, 4000, 5000} {
+		events = append(events, history.Event{Type: history.Woken, Duration: d})
+	}
+	require.Equal(t, 3*time.Second, averageDuration(events))
+}
+
+func TestRPCError_Response(t *testing.T) {
+	e := &rpcError{code: rpcErrCodeWaking, message: core.NodeIsBeingStarted, retryAfter: 12}
+	require.JSONEq(t,
+		`{"jsonrpc":"2.0","id":"abc","error":{"code":-32002,"message":"node is being started, try after sometime","data":{"retryAfter":12}}}`,
+		string(e.response(json.RawMessage(`"abc"`))))
+
+	e = &rpcError{code: rpcErrCodeNotReady, message: core.NodeIsNotReadyToAcceptRequest}
+	require.JSONEq(t,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32004,"message":"node is not ready to accept request"}}`,
+		string(e.response(nil)))
+
+	require.JSONEq(t,
+		`[{"jsonrpc":"2.0","id":1,"error":{"code":-32004,"message":"node is not ready to accept request"}},{"jsonrpc":"2.0","id":"b","error":{"code":-32004,"message":"node is not ready to accept request"}}]`,
+		string(e.response(json.RawMessage(`[1,"b"]`))))
+}
+
+func TestRPCError_CloseMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		e          *rpcError
+		wantCode   int
+		wantReason string
+	}{
+		{
+			name:       "retry later",
+			e:          &rpcError{code: rpcErrCodeBusy, message: core.NodeIsBeingShutdown, retryAfter: 30},
+			wantCode:   websocket.CloseTryAgainLater,
+			wantReason: "node is being shutdown, try after sometime, retry after 30s",
+		},
+		{
+			name:       "not ready",
+			e:          &rpcError{code: rpcErrCodeNotReady, status: http.StatusInternalServerError, message: core.NodeIsNotReadyToAcceptRequest},
+			wantCode:   websocket.CloseInternalServerErr,
+			wantReason: core.NodeIsNotReadyToAcceptRequest,
+		},
+		{
+			name:       "long reason is truncated",
+			e:          &rpcError{code: rpcErrCodeInternal, status: http.StatusInternalServerError, message: string(bytes.Repeat([]byte("a"), 200))},
+			wantCode:   websocket.CloseNormalClosure,
+			wantReason: string(bytes.Repeat([]byte("a"), maxCloseReasonLen)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.e.closeMessage()
+			require.Equal(t, tt.wantCode, int(m[0])<<8|int(m[1]))
+			require.Equal(t, tt.wantReason, string(m[2:]))
+		})
+	}
+}
+
+func TestRequestId(t *testing.T) {
+	require.Equal(t, json.RawMessage(`7`), requestId([]byte(` {"jsonrpc":"2.0","method":"eth_blockNumber","id":7}`)))
+	require.Equal(t, json.RawMessage(`[7,"a",null]`), requestId([]byte(`[{"jsonrpc":"2.0","method":"eth_blockNumber","id":7},{"id":"a"},{}]`)))
+	require.Nil(t, requestId([]byte(`[]`)))
+	require.Nil(t, requestId([]byte(`hello`)))
+}
+
+func TestHttpHandler_NodeBeingStarted(t *testing.T) {
+	nc := &node.NodeControl{}
+	nc.SetNodeStatus(core.StartupInprogress)
+	ps := &ProxyServer{nodeCtrl: nc, proxyCfg: &config.Proxy{Name: "test"}}
+	h, err := makeHttpHandler(ps)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"jsonrpc":"2.0","method":"eth_blockNumber","id":42}`)))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Equal(t, "10", rec.Header().Get("Retry-After"))
+	require.JSONEq(t,
+		`{"jsonrpc":"2.0","id":42,"error":{"code":-32002,"message":"node is being started, try after sometime","data":{"retryAfter":10}}}`,
+		rec.Body.String())
+}
+
+func TestHttpHandler_NodeBeingStarted_Batch(t *testing.T) {
+	nc := &node.NodeControl{}
+	nc.SetNodeStatus(core.StartupInprogress)
+	ps := &ProxyServer{nodeCtrl: nc, proxyCfg: &config.Proxy{Name: "test"}}
+	h, err := makeHttpHandler(ps)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`[{"jsonrpc":"2.0","method":"eth_blockNumber","id":1},{"jsonrpc":"2.0","method":"eth_chainId","id":"two"}]`)))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.JSONEq(t,
+		`[{"jsonrpc":"2.0","id":1,"error":{"code":-32002,"message":"node is being started, try after sometime","data":{"retryAfter":10}}},{"jsonrpc":"2.0","id":"two","error":{"code":-32002,"message":"node is being started, try after sometime","data":{"retryAfter":10}}}]`,
+		rec.Body.String())
+}
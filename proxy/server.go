@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	golog "log"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -11,8 +12,10 @@ import (
 	"time"
 
 	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
 	"github.com/ConsenSys/quorum-hibernate/log"
 	"github.com/ConsenSys/quorum-hibernate/node"
+	"github.com/ConsenSys/quorum-hibernate/privatetx"
 )
 
 // ProxyServer represents a proxy server
@@ -21,26 +24,93 @@ type ProxyServer struct {
 	proxyCfg      *config.Proxy     // proxy config
 	ignorePathMap map[string]bool
 	mux           *http.ServeMux
-	srv           *http.Server           // http server for the proxy
-	rp            *httputil.ReverseProxy // handler for http reverse proxy
-	wp            *WebsocketProxy        // handler for websocket
-	errCh         chan error             // error channel
+	srv           *http.Server               // http server for the proxy
+	rp            *httputil.ReverseProxy     // handler for http reverse proxy
+	wp            *WebsocketProxy            // handler for websocket
+	tcp           *tcpProxy                  // tcp proxy. nil if the proxy is not a tcp proxy
+	errCh         chan error                 // error channel
+	queue         *requestQueue              // queue for requests received while the node is being started. nil if not configured
+	cache         *responseCache             // cache of responses used while the node is down. nil if not configured
+	auth          *authenticator             // authenticator of clients. nil if not configured
+	reqLimiter    *rateLimiter               // rate limiter of requests per client. nil if not configured
+	wakeLimiter   *rateLimiter               // rate limiter of node wakes per client. nil if not configured
+	reqTxh        privatetx.RequestTxHandler // handler of private transactions in REST API requests. nil if the node's TxHandler is used
+	upstreams     *upstreamPool              // pool of upstreams. nil if the proxy has a single upstream
 	shutdownWg    sync.WaitGroup
 }
 
 // CanIgnoreRequest implements Proxy.CanIgnoreRequest
-func (ps ProxyServer) CanIgnoreRequest(req string) bool {
+func (ps *ProxyServer) CanIgnoreRequest(req string) bool {
 	_, ok := ps.ignorePathMap[req]
 	return ok
 }
 
+// countsAsActivity returns true if a request with the given JSON-RPC methods should be tracked for activity.
+// A batch request is tracked if any of its methods is allowed by the activity methods filter.
+func (ps *ProxyServer) countsAsActivity(methods []string) bool {
+	return anyMethodAllowed(ps.proxyCfg.ActivityMethods, methods)
+}
+
+// canWakeNode returns true if a request with the given JSON-RPC methods may start up the node if it is down.
+// A batch request may start up the node if any of its methods is allowed by the wake methods filter.
+func (ps *ProxyServer) canWakeNode(methods []string) bool {
+	return anyMethodAllowed(ps.proxyCfg.WakeMethods, methods)
+}
+
+// anyMethodAllowed returns true if the filter is not set, there are no methods or any of the methods is allowed
+func anyMethodAllowed(f *config.MethodFilter, methods []string) bool {
+	if f == nil || len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if f.IsAllowed(m) {
+			return true
+		}
+	}
+	return false
+}
+
 func NewProxyServer(qn *node.NodeControl, pc *config.Proxy, errc chan error) (Proxy, error) {
-	ps := &ProxyServer{qn, pc, make(map[string]bool), nil, nil, nil, nil, errc, sync.WaitGroup{}}
+	ps := &ProxyServer{
+		nodeCtrl:      qn,
+		proxyCfg:      pc,
+		ignorePathMap: make(map[string]bool),
+		errCh:         errc,
+	}
 	url, err := url.Parse(ps.proxyCfg.UpstreamAddr)
 	if err != nil {
 		return nil, err
 	}
 
+	if q := ps.proxyCfg.RequestQueue; q != nil {
+		ps.queue = newRequestQueue(ps.proxyCfg.Name, q.MaxLength, time.Duration(q.MaxWait)*time.Second, func() bool {
+			return qn.IsNodeBusy() == nil && qn.IsClientUp()
+		})
+	}
+
+	if ps.proxyCfg.ResponseCache != nil {
+		ps.cache = newResponseCache(ps.proxyCfg.ResponseCache)
+	}
+
+	if ps.proxyCfg.Auth != nil {
+		if ps.auth, err = newAuthenticator(ps.proxyCfg.Auth); err != nil {
+			return nil, err
+		}
+	}
+
+	if rl := ps.proxyCfg.RateLimit; rl != nil {
+		if rl.Requests != nil {
+			ps.reqLimiter = newRateLimiter(rl.Requests)
+		}
+		if rl.Wakes != nil {
+			ps.wakeLimiter = newRateLimiter(rl.Wakes)
+		}
+	}
+
+	if ps.proxyCfg.IsTesseraTxHandler() {
+		ps.reqTxh = privatetx.NewTesseraTxHandler()
+	}
+
 	ps.mux = http.NewServeMux()
 
 	for _, p := range ps.proxyCfg.IgnorePathsForActivity {
@@ -57,6 +127,10 @@ func NewProxyServer(qn *node.NodeControl, pc *config.Proxy, errc chan error) (Pr
 		if err != nil {
 			return nil, err
 		}
+	} else if ps.proxyCfg.IsTCP() {
+		ps.tcp = newTCPProxy(ps)
+		log.Info("ProxyServer - created tcp proxy server for config", "cfg", *pc)
+		return ps, nil
 	}
 	ps.srv = &http.Server{
 		Handler:      ps.mux,
@@ -81,9 +155,24 @@ func initHttpHandler(ps *ProxyServer, url *url.URL) error {
 		transport.TLSClientConfig = ps.proxyCfg.ClientTLSConfig.TlsCfg //TODO(cjh) may not work, may have to set rpTransport.DialTLSContext
 		ps.rp.Transport = transport
 	}
+	if ps.proxyCfg.Upstreams != nil {
+		transport := ps.rp.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		pool, err := newUpstreamPool(ps, url, transport)
+		if err != nil {
+			return err
+		}
+		ps.upstreams = pool
+		ps.rp.Transport = pool
+	}
 	ps.rp.ModifyResponse = func(res *http.Response) error {
 		respStatus := res.Status
 		log.Debug("initHttpHandler - response status", "status", respStatus, "code", res.StatusCode)
+		if ps.cache != nil {
+			return ps.cache.store(res)
+		}
 		return nil
 	}
 	h, err := makeHttpHandler(ps)
@@ -98,11 +187,11 @@ func initHttpHandler(ps *ProxyServer, url *url.URL) error {
 }
 
 func initWSHandler(ps *ProxyServer) error {
-	//TODO(cjh) support TLS
 	var err error
 	if ps.wp, err = WSProxyHandler(ps, ps.proxyCfg.UpstreamAddr); err != nil {
 		return err
 	}
+	ps.wp.Dialer = newWSDialer(ps.proxyCfg)
 	for _, p := range ps.proxyCfg.ProxyPaths {
 		ps.mux.Handle(p, ps.wp)
 		log.Info("initWSHandler - registering WS handler", "proxyAddr", ps.proxyCfg.ProxyAddr, "upstrAddr", ps.proxyCfg.UpstreamAddr, "name", ps.proxyCfg.Name, "type", ps.proxyCfg.Type, "path", p)
@@ -110,16 +199,63 @@ func initWSHandler(ps *ProxyServer) error {
 	return nil
 }
 
+// clientIdentity authenticates the client making req and returns its identity, which is used for rate limiting.
+// If authentication is not configured the identity is the client IP address.
+func (ps *ProxyServer) clientIdentity(req *http.Request) (string, error) {
+	if ps.auth != nil {
+		return ps.auth.authenticate(req)
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr, nil
+	}
+	return host, nil
+}
+
+// allowRequest returns nil if the client is within its request rate limit
+func (ps *ProxyServer) allowRequest(client string) error {
+	if ps.reqLimiter != nil && !ps.reqLimiter.allow(client) {
+		log.Warn("allowRequest - rate limit exceeded", "name", ps.proxyCfg.Name, "client", client)
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// allowWake returns nil if the node is up or the client is within its wake rate limit
+func (ps *ProxyServer) allowWake(client string) error {
+	if ps.wakeLimiter != nil && !ps.nodeCtrl.IsClientUp() && !ps.wakeLimiter.allow(client) {
+		log.Warn("allowWake - wake rate limit exceeded", "name", ps.proxyCfg.Name, "client", client)
+		return ErrWakeRateLimited
+	}
+	return nil
+}
+
+// waitIfStarting holds the request in the request queue if the node is being started and the request queue is
+// configured, else it returns busyErr. On success the returned function must be called once the request has been
+// dispatched upstream.
+func (ps *ProxyServer) waitIfStarting(ctx context.Context, busyErr error) (func(), error) {
+	if ps.queue == nil || ps.nodeCtrl.GetNodeStatus() != core.StartupInprogress {
+		return nil, busyErr
+	}
+	return ps.queue.wait(ctx)
+}
+
 // Start starts the proxy server
-func (ps ProxyServer) Start() {
+func (ps *ProxyServer) Start() {
 	ps.shutdownWg.Add(1)
+	if ps.upstreams != nil {
+		ps.upstreams.start()
+	}
 	go func() {
 		defer ps.shutdownWg.Done()
 		log.Info("Start - ListenAndServe started", "proxyAddr", ps.proxyCfg.ProxyAddr, "upstream", ps.proxyCfg.UpstreamAddr)
 
 		var err error
 
-		if ps.proxyCfg.ProxyServerTLSConfig != nil {
+		if ps.tcp != nil {
+			log.Debug("Starting tcp proxy server", "proxyAddr", ps.proxyCfg.ProxyAddr, "upstream", ps.proxyCfg.UpstreamAddr)
+			err = ps.tcp.listenAndServe()
+		} else if ps.proxyCfg.ProxyServerTLSConfig != nil {
 			log.Debug("Starting TLS-enabled proxy server", "proxyAddr", ps.proxyCfg.ProxyAddr, "upstream", ps.proxyCfg.UpstreamAddr)
 			err = ps.srv.ListenAndServeTLS("", "")
 		} else {
@@ -135,7 +271,16 @@ func (ps ProxyServer) Start() {
 }
 
 // Stop stops the proxy server
-func (ps ProxyServer) Stop() {
+func (ps *ProxyServer) Stop() {
+	if ps.tcp != nil {
+		ps.tcp.stop()
+		ps.shutdownWg.Wait()
+		log.Info("Stop - tcp server shutdown completed", "name", ps.proxyCfg.Name)
+		return
+	}
+	if ps.upstreams != nil {
+		ps.upstreams.stop()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if ps.srv != nil {
@@ -0,0 +1,321 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/history"
+	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
+)
+
+var (
+	// tcpIdleInterval is the interval at which the connections of a tcp proxy are counted. A connection is idle if
+	// no data has been transferred in either direction during the interval.
+	tcpIdleInterval = 5 * time.Second
+
+	// tcpDialTimeout is the timeout for connecting to the upstream of a tcp proxy
+	tcpDialTimeout = 10 * time.Second
+
+	// tcpStopTimeout is the maximum time to wait for open connections to be closed when a tcp proxy is stopped
+	tcpStopTimeout = 5 * time.Second
+)
+
+// tcpProxy splices raw tcp connections to the upstream address. Each new connection counts as activity and waits
+// for the node to be started before it is connected to the upstream.
+type tcpProxy struct {
+	ps              *ProxyServer
+	resetInactivity func()          // called for each new connection
+	ctx             context.Context // cancelled when the proxy is stopped
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup // open connections
+
+	mux      sync.Mutex // lock for the fields below
+	listener net.Listener
+	conns    map[*tcpConn]struct{}
+	closed   bool
+}
+
+// tcpConn is a client connection and, once the node is ready, its upstream connection
+type tcpConn struct {
+	client     net.Conn
+	lastActive int64 // unix nano time data was last transferred. accessed atomically
+
+	mux      sync.Mutex // lock for the fields below
+	upstream net.Conn   // nil until connected
+	closed   bool
+}
+
+func newTCPProxy(ps *ProxyServer) *tcpProxy {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &tcpProxy{
+		ps:              ps,
+		resetInactivity: ps.nodeCtrl.ResetInactiveSyncTime,
+		ctx:             ctx,
+		cancel:          cancel,
+		conns:           make(map[*tcpConn]struct{}),
+	}
+}
+
+// listenAndServe listens on the proxy address and serves connections until the proxy is stopped
+func (t *tcpProxy) listenAndServe() error {
+	l, err := net.Listen("tcp", t.ps.proxyCfg.ProxyAddr)
+	if err != nil {
+		return err
+	}
+	if t.ps.proxyCfg.ProxyServerTLSConfig != nil {
+		l = tls.NewListener(l, t.ps.proxyCfg.ProxyServerTLSConfig.TlsCfg)
+	}
+	return t.serve(l)
+}
+
+// serve accepts connections on l until the proxy is stopped. It returns nil if the proxy was stopped.
+func (t *tcpProxy) serve(l net.Listener) error {
+	t.mux.Lock()
+	if t.closed {
+		t.mux.Unlock()
+		return l.Close()
+	}
+	t.listener = l
+	t.mux.Unlock()
+
+	go t.countConnections()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if t.ctx.Err() != nil {
+				return nil
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				log.Warn("tcpProxy - accepting connection failed", "name", t.ps.proxyCfg.Name, "err", err)
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		c := &tcpConn{client: conn, lastActive: time.Now().UnixNano()}
+		if !t.add(c) {
+			conn.Close()
+			return nil
+		}
+		go func() {
+			defer t.wg.Done()
+			defer t.remove(c)
+			t.handle(c)
+		}()
+	}
+}
+
+// handle prepares the node for the client connection and then splices it to the upstream
+func (t *tcpProxy) handle(c *tcpConn) {
+	ps := t.ps
+	defer c.client.Close()
+	metrics.IncProxyRequests(ps.proxyCfg.Name)
+
+	client := c.client.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(client); err == nil {
+		client = host
+	}
+	log.Debug("tcpProxy - connection received", "name", ps.proxyCfg.Name, "client", client)
+	if err := ps.allowRequest(client); err != nil {
+		return
+	}
+
+	t.resetInactivity()
+
+	dispatched := func() {}
+	if err := ps.nodeCtrl.IsNodeBusy(); err != nil {
+		if dispatched, err = ps.waitIfStarting(t.ctx, err); err != nil {
+			log.Info("tcpProxy - closing connection as node is busy", "name", ps.proxyCfg.Name, "client", client, "err", err)
+			return
+		}
+	}
+	upstream, err := t.prepareUpstream(client)
+	dispatched()
+	if err != nil {
+		log.Error("tcpProxy - connecting to upstream failed", "name", ps.proxyCfg.Name, "upstream", ps.proxyCfg.UpstreamAddr, "err", err)
+		return
+	}
+	defer upstream.Close()
+	if !c.setUpstream(upstream) {
+		return
+	}
+	log.Debug("tcpProxy - connected to upstream", "name", ps.proxyCfg.Name, "client", client, "upstream", ps.proxyCfg.UpstreamAddr)
+
+	err = c.splice(time.Duration(ps.proxyCfg.ReadTimeout)*time.Second, time.Duration(ps.proxyCfg.WriteTimeout)*time.Second)
+	log.Debug("tcpProxy - connection closed", "name", ps.proxyCfg.Name, "client", client, "err", err)
+}
+
+// prepareUpstream starts the node if it is down and connects to the upstream
+func (t *tcpProxy) prepareUpstream(client string) (net.Conn, error) {
+	ps := t.ps
+	if err := ps.allowWake(client); err != nil {
+		return nil, err
+	}
+	if !ps.nodeCtrl.PrepareClient(history.TriggerProxyRequest) {
+		return nil, ErrNodeNotReady
+	}
+	d := &net.Dialer{Timeout: tcpDialTimeout}
+	if ps.proxyCfg.ClientTLSConfig != nil {
+		return tls.DialWithDialer(d, "tcp", ps.proxyCfg.UpstreamAddr, ps.proxyCfg.ClientTLSConfig.TlsCfg)
+	}
+	return d.DialContext(t.ctx, "tcp", ps.proxyCfg.UpstreamAddr)
+}
+
+// add tracks c as an open connection. It returns false if the proxy has been stopped.
+func (t *tcpProxy) add(c *tcpConn) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.closed {
+		return false
+	}
+	t.conns[c] = struct{}{}
+	t.wg.Add(1)
+	return true
+}
+
+func (t *tcpProxy) remove(c *tcpConn) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	delete(t.conns, c)
+}
+
+// connectionCounts returns the number of open connections which have transferred data within tcpIdleInterval
+// and the number of idle connections
+func (t *tcpProxy) connectionCounts() (active, idle int) {
+	since := time.Now().Add(-tcpIdleInterval).UnixNano()
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for c := range t.conns {
+		if atomic.LoadInt64(&c.lastActive) >= since {
+			active++
+		} else {
+			idle++
+		}
+	}
+	return active, idle
+}
+
+// countConnections periodically updates the connection metrics until the proxy is stopped
+func (t *tcpProxy) countConnections() {
+	ticker := time.NewTicker(tcpIdleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			active, idle := t.connectionCounts()
+			log.Debug("tcpProxy - connections", "name", t.ps.proxyCfg.Name, "active", active, "idle", idle)
+			metrics.SetProxyTCPConnections(t.ps.proxyCfg.Name, active, idle)
+		case <-t.ctx.Done():
+			metrics.SetProxyTCPConnections(t.ps.proxyCfg.Name, 0, 0)
+			return
+		}
+	}
+}
+
+// stop stops accepting connections and closes the open connections
+func (t *tcpProxy) stop() {
+	t.mux.Lock()
+	t.closed = true
+	t.cancel()
+	if t.listener != nil {
+		t.listener.Close()
+	}
+	for c := range t.conns {
+		c.close()
+	}
+	t.mux.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(tcpStopTimeout):
+		log.Warn("tcpProxy - timed out waiting for connections to close", "name", t.ps.proxyCfg.Name)
+	}
+}
+
+// setUpstream sets the upstream connection. It returns false if the connection has been closed.
+func (c *tcpConn) setUpstream(conn net.Conn) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.closed {
+		return false
+	}
+	c.upstream = conn
+	return true
+}
+
+func (c *tcpConn) close() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.closed = true
+	c.client.Close()
+	if c.upstream != nil {
+		c.upstream.Close()
+	}
+}
+
+// splice copies data in both directions until both directions are closed, or either fails or is idle for readTimeout
+func (c *tcpConn) splice(readTimeout, writeTimeout time.Duration) error {
+	c.mux.Lock()
+	client, upstream := c.client, c.upstream
+	c.mux.Unlock()
+
+	errc := make(chan error, 2)
+	go func() { errc <- c.pipe(upstream, client, readTimeout, writeTimeout) }()
+	go func() { errc <- c.pipe(client, upstream, readTimeout, writeTimeout) }()
+	var firstErr error
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil && firstErr == nil {
+			firstErr = err
+			// unblock the other direction
+			client.Close()
+			upstream.Close()
+		}
+	}
+	return firstErr
+}
+
+// pipe copies data from src to dst until src is closed by its peer, in which case dst is closed for writing and nil
+// is returned. Reads time out after readTimeout unless data has been transferred in the other direction in the
+// meantime, and writes time out after writeTimeout.
+func (c *tcpConn) pipe(dst, src net.Conn, readTimeout, writeTimeout time.Duration) error {
+	buf := make([]byte, 32*1024)
+	for {
+		src.SetReadDeadline(time.Now().Add(readTimeout))
+		n, err := src.Read(buf)
+		if n > 0 {
+			atomic.StoreInt64(&c.lastActive, time.Now().UnixNano())
+			dst.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+				return cw.CloseWrite()
+			}
+			return err
+		}
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() && c.idleFor() < readTimeout {
+				continue
+			}
+			return err
+		}
+	}
+}
+
+// idleFor returns the time since data was last transferred in either direction
+func (c *tcpConn) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.lastActive)))
+}
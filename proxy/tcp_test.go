@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/node"
+	"github.com/stretchr/testify/require"
+)
+
+// newTCPServer returns the address of a tcp server which serves each connection with handle
+func newTCPServer(t *testing.T, handle func(conn net.Conn)) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func echo(conn net.Conn) {
+	io.Copy(conn, conn)
+}
+
+// startTestTCPProxy starts a tcp proxy to upstream and returns it with its address and the number of times
+// activity has been reported
+func startTestTCPProxy(t *testing.T, nc *node.NodeControl, upstream string, readTimeout int) (*tcpProxy, string, *int32) {
+	ps := &ProxyServer{
+		nodeCtrl: nc,
+		proxyCfg: &config.Proxy{
+			Name:         "tcpproxy",
+			Type:         "tcp",
+			UpstreamAddr: upstream,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: 5,
+		},
+	}
+	tp := newTCPProxy(ps)
+	var activity int32
+	tp.resetInactivity = func() { atomic.AddInt32(&activity, 1) }
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go tp.serve(l)
+	t.Cleanup(tp.stop)
+	return tp, l.Addr().String(), &activity
+}
+
+func newUpNodeControl() *node.NodeControl {
+	nc := &node.NodeControl{}
+	nc.SetClntStatus(core.Up)
+	return nc
+}
+
+func TestTCPProxy_SplicesToUpstream(t *testing.T) {
+	_, addr, activity := startTestTCPProxy(t, newUpNodeControl(), newTCPServer(t, echo), 5)
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte("hello"))
+		require.NoError(t, err)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		got := make([]byte, 5)
+		_, err = io.ReadFull(conn, got)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(got))
+		conn.Close()
+	}
+
+	require.Equal(t, int32(2), atomic.LoadInt32(activity), "each new connection must count as activity")
+}
+
+func TestTCPProxy_HalfClose(t *testing.T) {
+	upstream := newTCPServer(t, func(conn net.Conn) {
+		req, _ := ioutil.ReadAll(conn)
+		conn.Write([]byte("received " + string(req)))
+	})
+	_, addr, _ := startTestTCPProxy(t, newUpNodeControl(), upstream, 5)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	got, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "received request", string(got))
+}
+
+func TestTCPProxy_ClosesIdleConnection(t *testing.T) {
+	_, addr, _ := startTestTCPProxy(t, newUpNodeControl(), newTCPServer(t, echo), 1)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+	require.True(t, time.Since(start) >= time.Second, "connection must be kept open for the read timeout")
+}
+
+func TestTCPProxy_NodeBeingShutdown(t *testing.T) {
+	var connected int32
+	upstream := newTCPServer(t, func(conn net.Conn) { atomic.AddInt32(&connected, 1) })
+	nc := newUpNodeControl()
+	nc.SetNodeStatus(core.ShutdownInprogress)
+	_, addr, activity := startTestTCPProxy(t, nc, upstream, 5)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(activity))
+	require.Equal(t, int32(0), atomic.LoadInt32(&connected))
+}
+
+func TestTCPProxy_ConnectionCounts(t *testing.T) {
+	tp, addr, _ := startTestTCPProxy(t, newUpNodeControl(), newTCPServer(t, echo), 5)
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+	}
+	require.Eventually(t, func() bool {
+		active, idle := tp.connectionCounts()
+		return active == 2 && idle == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	tp.mux.Lock()
+	for c := range tp.conns {
+		atomic.StoreInt64(&c.lastActive, time.Now().Add(-2*tcpIdleInterval).UnixNano())
+		break
+	}
+	tp.mux.Unlock()
+
+	active, idle := tp.connectionCounts()
+	require.Equal(t, 1, active)
+	require.Equal(t, 1, idle)
+}
+
+func TestTCPProxy_StopClosesConnections(t *testing.T) {
+	tp, addr, _ := startTestTCPProxy(t, newUpNodeControl(), newTCPServer(t, echo), 5)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = io.ReadFull(conn, make([]byte, 5))
+	require.NoError(t, err)
+
+	tp.stop()
+
+	_, err = conn.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+	_, err = net.Dial("tcp", addr)
+	require.Error(t, err)
+}
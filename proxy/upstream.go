@@ -0,0 +1,212 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
+	"github.com/ConsenSys/quorum-hibernate/process"
+)
+
+// upstream is one of the upstreams of an upstreamPool
+type upstream struct {
+	url     *url.URL
+	healthy int32 // 1 if the upstream is healthy. accessed atomically
+}
+
+func (u *upstream) isHealthy() bool {
+	return atomic.LoadInt32(&u.healthy) == 1
+}
+
+// upstreamPool is a http.RoundTripper which sends requests to one of several upstreams, selected by priority or
+// round robin, and fails over to the next upstream if connecting to the selected upstream fails.
+// Upstreams are marked unhealthy when connecting to them fails or when their health check fails, and are marked
+// healthy again when a request or health check succeeds.
+type upstreamPool struct {
+	ps         *ProxyServer
+	upstreams  []*upstream // in priority order. the first is the proxy's upstreamAddress
+	roundRobin bool
+	next       uint32 // index of the next upstream to use with round robin. accessed atomically
+	transport  http.RoundTripper
+	client     *http.Client // client for health checks
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func newUpstreamPool(ps *ProxyServer, primary *url.URL, transport http.RoundTripper) (*upstreamPool, error) {
+	cfg := ps.proxyCfg.Upstreams
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &upstreamPool{
+		ps:         ps,
+		upstreams:  []*upstream{{url: primary}},
+		roundRobin: cfg.IsRoundRobin(),
+		transport:  transport,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	for _, a := range cfg.Addresses {
+		u, err := url.Parse(a)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		p.upstreams = append(p.upstreams, &upstream{url: u})
+	}
+	for _, u := range p.upstreams {
+		p.setHealthy(u, true)
+	}
+	if ps.proxyCfg.ClientTLSConfig != nil {
+		p.client = core.NewHttpClient(ps.proxyCfg.ClientTLSConfig.TlsCfg)
+	} else {
+		p.client = core.NewHttpClient(nil)
+	}
+	return p, nil
+}
+
+// RoundTrip implements http.RoundTripper. The request URL must be for the primary upstream, as set by the reverse
+// proxy director. The request is only sent to the next upstream if connecting to an upstream fails, as otherwise the
+// request may already have been processed.
+func (p *upstreamPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var lastErr error
+	for _, u := range p.order() {
+		r := req.Clone(req.Context())
+		p.rewrite(r, u)
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		res, err := p.transport.RoundTrip(r)
+		if err == nil {
+			p.setHealthy(u, true)
+			return res, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		log.Warn("upstreamPool - request to upstream failed", "name", p.ps.proxyCfg.Name, "upstream", u.url.String(), "err", err)
+		p.setHealthy(u, false)
+		if !isDialErr(err) || req.Body != nil && req.GetBody == nil {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+// order returns the upstreams in the order they should be tried. Healthy upstreams are tried first, starting with
+// the highest priority upstream or, with round robin, the next upstream in turn.
+func (p *upstreamPool) order() []*upstream {
+	start := 0
+	if p.roundRobin {
+		start = int((atomic.AddUint32(&p.next, 1) - 1) % uint32(len(p.upstreams)))
+	}
+	var healthy, unhealthy []*upstream
+	for i := range p.upstreams {
+		u := p.upstreams[(start+i)%len(p.upstreams)]
+		if u.isHealthy() {
+			healthy = append(healthy, u)
+		} else {
+			unhealthy = append(unhealthy, u)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// rewrite sets the URL of r, which is for the primary upstream, to the URL for u
+func (p *upstreamPool) rewrite(r *http.Request, u *upstream) {
+	primary := p.upstreams[0].url
+	if u.url == primary {
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(primary.Path, "/"))
+	r.URL.Scheme = u.url.Scheme
+	r.URL.Host = u.url.Host
+	r.URL.Path = strings.TrimSuffix(u.url.Path, "/") + path
+	r.URL.RawPath = ""
+}
+
+func (p *upstreamPool) setHealthy(u *upstream, healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	if old := atomic.SwapInt32(&u.healthy, v); old != v {
+		log.Info("upstreamPool - upstream health changed", "name", p.ps.proxyCfg.Name, "upstream", u.url.String(), "healthy", healthy)
+	}
+	metrics.SetProxyUpstreamHealthy(p.ps.proxyCfg.Name, u.url.String(), healthy)
+}
+
+// start starts the periodic health checks of the upstreams if configured
+func (p *upstreamPool) start() {
+	cfg := p.ps.proxyCfg.Upstreams
+	if cfg.HealthCheck == nil {
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(time.Duration(cfg.HealthCheckInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.checkHealth()
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// checkHealth checks the health of each upstream. Upstreams are not checked while the node is hibernated or
+// is being shutdown or started, as they are expected to be down.
+func (p *upstreamPool) checkHealth() {
+	nc := p.ps.nodeCtrl
+	if nc.IsNodeBusy() != nil || !nc.IsClientUp() {
+		return
+	}
+	for _, u := range p.upstreams {
+		cfg := *p.ps.proxyCfg.Upstreams.HealthCheck
+		ref, err := url.Parse(cfg.UpcheckUrl)
+		if err != nil {
+			log.Error("upstreamPool - invalid health check url", "url", cfg.UpcheckUrl, "err", err)
+			return
+		}
+		cfg.UpcheckUrl = u.url.ResolveReference(ref).String()
+		up, _ := process.IsProcessUp(p.client, &cfg)
+		p.setHealthy(u, up)
+	}
+}
+
+// stop stops the health checks
+func (p *upstreamPool) stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// isDialErr returns true if err is a failure to connect, in which case the request has not been sent
+func isDialErr(err error) bool {
+	for {
+		if op, ok := err.(*net.OpError); ok {
+			return op.Op == "dial"
+		}
+		switch e := err.(type) {
+		case *url.Error:
+			err = e.Err
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/stretchr/testify/require"
+)
+
+// newNamedServer returns a server which replies with its name and the path and body of each request
+func newNamedServer(t *testing.T, name string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write([]byte(name + " " + r.URL.Path + " " + string(body)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// closedServerURL returns the url of a server which is no longer listening
+func closedServerURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func newTestUpstreamPool(t *testing.T, primary string, upstreams *config.Upstreams) *upstreamPool {
+	ps := &ProxyServer{
+		nodeCtrl: newUpNodeControl(),
+		proxyCfg: &config.Proxy{Name: "test", UpstreamAddr: primary, Upstreams: upstreams},
+	}
+	u, err := url.Parse(primary)
+	require.NoError(t, err)
+	p, err := newUpstreamPool(ps, u, http.DefaultTransport)
+	require.NoError(t, err)
+	t.Cleanup(p.stop)
+	return p
+}
+
+// send sends a request with body through the pool as the reverse proxy would and returns the response body
+func send(t *testing.T, p *upstreamPool, path, body string) string {
+	primary := p.upstreams[0].url
+	req := httptest.NewRequest(http.MethodPost, primary.String()+path, bytes.NewBufferString(body))
+	req.RequestURI = ""
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewBufferString(body)), nil
+	}
+	res, err := p.RoundTrip(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestUpstreamPool_Priority(t *testing.T) {
+	a, b := newNamedServer(t, "a"), newNamedServer(t, "b")
+	p := newTestUpstreamPool(t, a.URL, &config.Upstreams{Addresses: []string{b.URL}})
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, "a /rpc req", send(t, p, "/rpc", "req"))
+	}
+}
+
+func TestUpstreamPool_RoundRobin(t *testing.T) {
+	a, b := newNamedServer(t, "a"), newNamedServer(t, "b")
+	p := newTestUpstreamPool(t, a.URL, &config.Upstreams{Addresses: []string{b.URL}, Policy: "roundRobin"})
+
+	require.Equal(t, "a / req", send(t, p, "/", "req"))
+	require.Equal(t, "b / req", send(t, p, "/", "req"))
+	require.Equal(t, "a / req", send(t, p, "/", "req"))
+}
+
+func TestUpstreamPool_FailsOverOnConnectionError(t *testing.T) {
+	b := newNamedServer(t, "b")
+	p := newTestUpstreamPool(t, closedServerURL(), &config.Upstreams{Addresses: []string{b.URL}})
+
+	require.Equal(t, "b /rpc req", send(t, p, "/rpc", "req"))
+	require.False(t, p.upstreams[0].isHealthy())
+	require.True(t, p.upstreams[1].isHealthy())
+
+	// the unhealthy upstream is tried last
+	require.Equal(t, []*upstream{p.upstreams[1], p.upstreams[0]}, p.order())
+}
+
+func TestUpstreamPool_RewritesPath(t *testing.T) {
+	b := newNamedServer(t, "b")
+	p := newTestUpstreamPool(t, closedServerURL()+"/primary", &config.Upstreams{Addresses: []string{b.URL + "/standby/"}})
+
+	require.Equal(t, "b /standby/rpc req", send(t, p, "/rpc", "req"))
+}
+
+func TestUpstreamPool_AllDown(t *testing.T) {
+	p := newTestUpstreamPool(t, closedServerURL(), &config.Upstreams{Addresses: []string{closedServerURL()}})
+
+	req := httptest.NewRequest(http.MethodGet, p.upstreams[0].url.String(), nil)
+	req.RequestURI = ""
+	_, err := p.RoundTrip(req)
+	require.Error(t, err)
+	require.True(t, isDialErr(err))
+}
+
+func TestUpstreamPool_CheckHealth(t *testing.T) {
+	var upB bool
+	a := newNamedServer(t, "a")
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/upcheck" && upB {
+			w.Write([]byte("I'm up!"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer b.Close()
+	p := newTestUpstreamPool(t, a.URL, &config.Upstreams{
+		Addresses: []string{b.URL},
+		HealthCheck: &config.Upcheck{
+			UpcheckUrl: "/upcheck",
+			Method:     "GET",
+			ReturnType: "string",
+			Expected:   "I'm up!",
+		},
+		HealthCheckInterval: 1,
+	})
+
+	p.checkHealth()
+	require.False(t, p.upstreams[0].isHealthy(), "a does not serve the health check")
+	require.False(t, p.upstreams[1].isHealthy())
+
+	upB = true
+	p.checkHealth()
+	require.True(t, p.upstreams[1].isHealthy())
+
+	// upstreams are expected to be down while the node is hibernated
+	upB = false
+	p.ps.nodeCtrl.SetClntStatus(core.Down)
+	p.checkHealth()
+	require.True(t, p.upstreams[1].isHealthy())
+}
@@ -1,13 +1,17 @@
 package proxy
 
 import (
-	"fmt"
+	"context"
 	"net"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/history"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 
 	"github.com/gorilla/websocket"
 )
@@ -47,6 +51,16 @@ type WebsocketProxy struct {
 	Dialer *websocket.Dialer
 }
 
+// newWSDialer returns a dialer for the upstream of the proxy, using the client tls config if set.
+// Each proxy has its own dialer so that the tls config of one proxy is not used by another.
+func newWSDialer(pc *config.Proxy) *websocket.Dialer {
+	d := *DefaultDialer
+	if pc.ClientTLSConfig != nil {
+		d.TLSClientConfig = pc.ClientTLSConfig.TlsCfg
+	}
+	return &d
+}
+
 // WSProxyHandler returns a new http.Handler interface that reverse proxies the
 // request to the given target.
 func WSProxyHandler(ps *ProxyServer, destUrl string) (*WebsocketProxy, error) {
@@ -87,11 +101,26 @@ func (w *WebsocketProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if w.ps.nodeCtrl.PrepareClient() {
+	client, err := w.ps.clientIdentity(req)
+	if err != nil {
+		log.Info("ServeHTTP-WS - client not authenticated", "name", w.ps.proxyCfg.Name, "remoteAddr", req.RemoteAddr)
+		w.ps.writeRPCError(rw, nil, err)
+		return
+	}
+	if err := w.ps.allowRequest(client); err != nil {
+		w.ps.writeRPCError(rw, nil, err)
+		return
+	}
+	if err := w.ps.allowWake(client); err != nil {
+		w.ps.writeRPCError(rw, nil, err)
+		return
+	}
+
+	if w.ps.nodeCtrl.PrepareClient(history.TriggerProxyRequest) {
 		log.Info("ServeHTTP-WS - node prepared to accept request")
 	} else {
 		log.Error("ServeHTTP-WS - failed to start node")
-		http.Error(rw, "Failed to start the node", http.StatusInternalServerError)
+		w.ps.writeRPCError(rw, nil, ErrNodeNotReady)
 		return
 	}
 
@@ -100,11 +129,6 @@ func (w *WebsocketProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		dialer = DefaultDialer
 	}
 
-	// enable tls config if tls is enabled for client
-	if w.ps.proxyCfg.ClientTLSConfig != nil {
-		dialer.TLSClientConfig = w.ps.proxyCfg.ClientTLSConfig.TlsCfg
-	}
-
 	// Pass headers from the incoming request to the dialer to forward them to
 	// the final destinations.
 	requestHeader := http.Header{}
@@ -182,10 +206,6 @@ func (w *WebsocketProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	log.Info("ServeHTTP-WS - connected to backend", "name", w.ps.proxyCfg.Name, "dest", w.ps.proxyCfg.UpstreamAddr)
-	defer func() {
-		connBackend.Close()
-		log.Info("ServeHTTP-WS - disconnected from backend", "name", w.ps.proxyCfg.Name, "dest", w.ps.proxyCfg.UpstreamAddr)
-	}()
 
 	upgrader := w.Upgrader
 	if w.Upgrader == nil {
@@ -208,80 +228,75 @@ func (w *WebsocketProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	connSrc, err := upgrader.Upgrade(rw, req, upgradeHeader)
 	if err != nil {
 		log.Error("ServeHTTP-WS - couldn't upgrade", "err", err)
+		connBackend.Close()
 		return
 	}
 	defer connSrc.Close()
 
-	errClient := make(chan error, 1)
-	errBackend := make(chan error, 1)
-
-	go w.replicateWebsocketConn(connSrc, connBackend, errClient, false)
-	go w.replicateWebsocketConn(connBackend, connSrc, errBackend, true)
-
-	var message string
-	select {
-	case err = <-errClient:
-		message = fmt.Sprintf("websocketproxy: Error when copying from backend to client: %v", err)
-	case err = <-errBackend:
-		message = fmt.Sprintf("websocketproxy: Error when copying from client to backend: %v", err)
-	}
+	// The session keeps the client connection open if the backend connection is closed, e.g. when the node
+	// hibernates, and reconnects to the backend when required.
+	s := newWSSession(w, connSrc, client, backendURL.String(), dialer, requestHeader)
+	s.setBackend(connBackend)
+	err = s.readClient()
+	s.close()
 	if e, ok := err.(*websocket.CloseError); !ok || e.Code == websocket.CloseAbnormalClosure {
-		log.Error(message, "err", err)
+		log.Error("websocketproxy: Error when copying from client to backend", "err", err)
 	}
 }
 
-func (w *WebsocketProxy) replicateWebsocketConn(dst, src *websocket.Conn, errc chan error, isReqFromSource bool) {
-	defer log.Info("replicateWebsocketConn - exit", "src", isReqFromSource)
-	for {
-		msgType, msg, err := src.ReadMessage()
-		if err != nil {
-			w.closeConnWithError(dst, err)
-			errc <- err
-			break
-		}
-
-		if isReqFromSource {
-			log.Info("replicateWebsocketConn - received request from source", "msgType", msgType, "msg", string(msg))
-		} else {
-			log.Info("replicateWebsocketConn - sending response to destination", "msgType", msgType, "msg", string(msg))
-		}
-
-		if isReqFromSource {
-			if err := w.ps.nodeCtrl.IsNodeBusy(); err != nil {
-				log.Error("replicateWebsocketConn - node is busy", "err", err)
-				w.closeConnWithError(dst, err)
-				return
-			}
-			w.ps.nodeCtrl.ResetInactiveSyncTime()
-			if w.ps.nodeCtrl.PrepareClient() {
-				log.Info("replicateWebsocketConn - prepared to accept request")
-			} else {
-				log.Error("replicateWebsocketConn - prepare node failed")
-				w.closeConnWithError(dst, ErrNodeNotReady)
-				errc <- err
-				break
-			}
+// prepareRequest prepares the node for a request from the client. It returns ErrRateLimited or ErrWakeRateLimited
+// if the client has exceeded a rate limit, ErrNodeHibernated if the node is down and the request may not wake it,
+// or another error if the client connection should be closed.
+// On success the returned function must be called once the request has been sent to the backend.
+func (w *WebsocketProxy) prepareRequest(client string, msg []byte) (func(), error) {
+	metrics.IncProxyRequests(w.ps.proxyCfg.Name)
+	if err := w.ps.allowRequest(client); err != nil {
+		return nil, err
+	}
+	dispatched := func() {}
+	if err := w.ps.nodeCtrl.IsNodeBusy(); err != nil {
+		if dispatched, err = w.ps.waitIfStarting(context.Background(), err); err != nil {
+			log.Error("prepareRequest - node is busy", "err", err)
+			return nil, err
 		}
-
-		if isReqFromSource {
-			if err := HandlePrivateTx(msg, w.ps); err != nil {
-				log.Error("replicateWebsocketConn - handling private transaction failed", "err", err)
-				w.closeConnWithError(dst, ErrParticipantsDown)
-				errc <- err
-				break
-			}
+	}
+	methods := jsonRpcMethods(msg)
+	if w.ps.countsAsActivity(methods) {
+		w.ps.nodeCtrl.ResetInactiveSyncTime()
+	}
+	if !w.ps.canWakeNode(methods) {
+		log.Debug("prepareRequest - request not allowed to wake node", "methods", methods)
+		if w.ps.nodeCtrl.ClientStatus() == core.Down {
+			dispatched()
+			return nil, ErrNodeHibernated
 		}
-
-		err = dst.WriteMessage(msgType, msg)
-		if err != nil {
-			errc <- err
-			break
+		return dispatched, nil
+	}
+	if err := w.ps.allowWake(client); err != nil {
+		dispatched()
+		return nil, err
+	}
+	if w.ps.nodeCtrl.PrepareClient(history.TriggerProxyRequest) {
+		log.Info("prepareRequest - prepared to accept request")
+	} else {
+		log.Error("prepareRequest - prepare node failed")
+		dispatched()
+		return nil, ErrNodeNotReady
+	}
+	if w.ps.nodeCtrl.WithPrivMan() {
+		if err := HandlePrivateTx(msg, w.ps); err != nil {
+			log.Error("prepareRequest - handling private transaction failed", "err", err)
+			dispatched()
+			return nil, ErrParticipantsDown
 		}
 	}
+	return dispatched, nil
 }
 
+// closeConnWithError closes dst with a close message for err. Errors raised while the node is being shutdown or
+// started close the connection with 1013 (try again later) and a reason with the estimated retry after time.
 func (w *WebsocketProxy) closeConnWithError(dst *websocket.Conn, err error) {
-	m := websocket.FormatCloseMessage(websocket.CloseNormalClosure, fmt.Sprintf("%v", err))
+	m := w.ps.newRPCError(err).closeMessage()
 	if e, ok := err.(*websocket.CloseError); ok {
 		if e.Code != websocket.CloseNoStatusReceived {
 			m = websocket.FormatCloseMessage(e.Code, e.Text)
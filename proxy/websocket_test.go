@@ -0,0 +1,130 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/core"
+	"github.com/ConsenSys/quorum-hibernate/node"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCertFile = "../config/resources/cert.pem"
+	testKeyFile  = "../config/resources/key.pem"
+
+	testWSRequest = `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`
+)
+
+// newEchoWSServer returns a websocket server which echoes messages, prefixed with the X-Forwarded-Proto header of the connection
+func newEchoWSServer(t *testing.T, withTLS bool) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := DefaultUpgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			reply := req.Header.Get("X-Forwarded-Proto") + " " + string(msg)
+			if err := conn.WriteMessage(msgType, []byte(reply)); err != nil {
+				return
+			}
+		}
+	}))
+	if withTLS {
+		srv.TLS = newTestServerTLS(t).TlsCfg
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestServerTLS(t *testing.T) *config.ServerTLS {
+	c := &config.ServerTLS{CertFile: testCertFile, KeyFile: testKeyFile}
+	require.NoError(t, c.SetTLSConfig())
+	return c
+}
+
+func newTestClientTLS(t *testing.T) *config.ClientTLS {
+	c := &config.ClientTLS{CACertFile: testCertFile}
+	require.NoError(t, c.SetTLSConfig())
+	return c
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// startTestWSProxy starts a tls enabled ws proxy to upstream
+func startTestWSProxy(t *testing.T, upstream string, clientTLS *config.ClientTLS) *httptest.Server {
+	nc := &node.NodeControl{}
+	nc.SetClntStatus(core.Up)
+	pc := &config.Proxy{
+		Name:                 "wsproxy",
+		Type:                 "ws",
+		UpstreamAddr:         upstream,
+		ProxyPaths:           []string{"/"},
+		ProxyServerTLSConfig: newTestServerTLS(t),
+		ClientTLSConfig:      clientTLS,
+		// the node control has no inactivity monitor so requests must not be tracked for activity
+		ActivityMethods: &config.MethodFilter{Deny: []string{"*"}},
+	}
+	p, err := NewProxyServer(nc, pc, make(chan error, 1))
+	require.NoError(t, err)
+	ps := p.(*ProxyServer)
+
+	srv := httptest.NewUnstartedServer(ps.mux)
+	srv.TLS = ps.srv.TLSConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func requireEcho(t *testing.T, proxyURL string, want string) {
+	dialer := &websocket.Dialer{TLSClientConfig: newTestClientTLS(t).TlsCfg, HandshakeTimeout: 5 * time.Second}
+	conn, _, err := dialer.Dial(proxyURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(testWSRequest)))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, want, string(msg))
+}
+
+func TestWebsocketProxy_WssToWss(t *testing.T) {
+	upstream := newEchoWSServer(t, true)
+	proxy := startTestWSProxy(t, wsURL(upstream), newTestClientTLS(t))
+
+	requireEcho(t, wsURL(proxy), "https "+testWSRequest)
+}
+
+func TestWebsocketProxy_WssToWs(t *testing.T) {
+	upstream := newEchoWSServer(t, false)
+	proxy := startTestWSProxy(t, wsURL(upstream), nil)
+
+	requireEcho(t, wsURL(proxy), "https "+testWSRequest)
+}
+
+func TestNewWSDialer_DoesNotShareTLSConfig(t *testing.T) {
+	clientTLS := newTestClientTLS(t)
+
+	withTLS := newWSDialer(&config.Proxy{ClientTLSConfig: clientTLS})
+	withoutTLS := newWSDialer(&config.Proxy{})
+
+	require.Same(t, clientTLS.TlsCfg, withTLS.TLSClientConfig)
+	require.Nil(t, withoutTLS.TLSClientConfig)
+	require.Nil(t, DefaultDialer.TLSClientConfig)
+}
@@ -0,0 +1,392 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/log"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsReconnectInterval is the interval at which a session with subscriptions and a disconnected backend checks
+// if the node is up to reconnect
+var wsReconnectInterval = time.Second
+
+// errWSSessionClosed is returned when sending to the backend after the client connection has been closed
+var errWSSessionClosed = errors.New("websocket session closed")
+
+// wsSession is a client websocket connection which survives its backend connection being closed, e.g. when the
+// node hibernates. Subscriptions made by the client are remembered and when the backend is reconnected they are
+// made again, with the new subscription ids rewritten to the ids the client already holds.
+type wsSession struct {
+	w          *WebsocketProxy
+	client     *websocket.Conn
+	identity   string // identity of the client used for rate limiting
+	backendURL string
+	dialer     *websocket.Dialer
+	header     http.Header
+	clientMux  sync.Mutex // lock for writing to client
+
+	mux         sync.Mutex        // lock for the fields below
+	backend     *websocket.Conn   // backend connection. nil while disconnected
+	closed      bool              // indicates if the client connection has been closed
+	subs        map[string][]byte // subscribe request of each active subscription by client subscription id
+	pendingSubs map[string][]byte // client subscribe requests waiting for a response by request id
+	resubs      map[string]resub  // replayed subscribe requests by request id
+	subIds      map[string]string // client subscription id by backend subscription id
+	resubCount  int
+}
+
+// resub is a subscribe request replayed when the backend is reconnected
+type resub struct {
+	clientId string // client subscription id
+	method   string // subscribe method, e.g. eth_subscribe
+}
+
+// rpcMsg is a JSON-RPC request, response or subscription notification
+type rpcMsg struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+func newWSSession(w *WebsocketProxy, client *websocket.Conn, identity string, backendURL string, dialer *websocket.Dialer, header http.Header) *wsSession {
+	return &wsSession{
+		w:           w,
+		client:      client,
+		identity:    identity,
+		backendURL:  backendURL,
+		dialer:      dialer,
+		header:      header,
+		subs:        make(map[string][]byte),
+		pendingSubs: make(map[string][]byte),
+		resubs:      make(map[string]resub),
+		subIds:      make(map[string]string),
+	}
+}
+
+// setBackend sets the backend connection and starts forwarding its messages to the client
+func (s *wsSession) setBackend(conn *websocket.Conn) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.backend = conn
+	go s.readBackend(conn)
+}
+
+// readClient forwards client messages to the backend, reconnecting to the backend if required.
+// It returns when the client connection fails or is closed.
+func (s *wsSession) readClient() error {
+	for {
+		msgType, msg, err := s.client.ReadMessage()
+		if err != nil {
+			return err
+		}
+		log.Info("wsSession - received request from source", "msgType", msgType, "msg", string(msg))
+
+		dispatched, err := s.w.prepareRequest(s.identity, msg)
+		if err == ErrRateLimited || err == ErrWakeRateLimited || err == ErrNodeHibernated {
+			if err := s.replyError(msg, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			s.closeClient(err)
+			return err
+		}
+		err = s.send(msgType, msg)
+		dispatched()
+		if err != nil {
+			s.closeClient(err)
+			return err
+		}
+	}
+}
+
+// send writes msg to the backend, connecting to it if it is disconnected
+func (s *wsSession) send(msgType int, msg []byte) error {
+	if !s.isConnected() {
+		if err := s.connect(); err != nil {
+			log.Error("wsSession - reconnecting to backend failed", "backendURL", s.backendURL, "err", err)
+			return err
+		}
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.backend == nil {
+		// the session was closed or the backend disconnected again
+		return errWSSessionClosed
+	}
+	return s.backend.WriteMessage(msgType, s.trackRequest(msg))
+}
+
+// isConnected returns true if the backend is connected
+func (s *wsSession) isConnected() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.backend != nil
+}
+
+// connect connects to the backend and makes the client's subscriptions again. The backend is dialled without
+// holding s.mux so that a slow backend does not block the session. If the backend has been connected by another
+// goroutine or the session has been closed in the meantime, the new connection is closed.
+func (s *wsSession) connect() error {
+	conn, _, err := s.dialer.Dial(s.backendURL, s.header)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.closed || s.backend != nil {
+		conn.Close()
+		return nil
+	}
+	log.Info("wsSession - reconnected to backend", "name", s.w.ps.proxyCfg.Name, "backendURL", s.backendURL, "subscriptions", len(s.subs))
+	s.backend = conn
+	s.subIds = make(map[string]string)
+	go s.readBackend(conn)
+
+	for clientId, req := range s.subs {
+		s.resubCount++
+		id := fmt.Sprintf(`"nh-resubscribe-%d"`, s.resubCount)
+		b, err := setJSONField(req, "id", json.RawMessage(id))
+		if err != nil {
+			log.Error("wsSession - invalid subscribe request", "subscription", clientId, "err", err)
+			delete(s.subs, clientId)
+			continue
+		}
+		var r rpcMsg
+		json.Unmarshal(req, &r)
+		s.resubs[id] = resub{clientId: clientId, method: r.Method}
+		if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readBackend forwards messages from the backend connection conn to the client until conn fails or is closed
+func (s *wsSession) readBackend(conn *websocket.Conn) {
+	for {
+		msgType, msg, err := conn.ReadMessage()
+		if err != nil {
+			s.disconnected(conn, err)
+			return
+		}
+		msg, forward := s.trackResponse(msg)
+		if !forward {
+			continue
+		}
+		log.Info("wsSession - sending response to destination", "msgType", msgType, "msg", string(msg))
+		s.clientMux.Lock()
+		err = s.client.WriteMessage(msgType, msg)
+		s.clientMux.Unlock()
+		if err != nil {
+			log.Error("wsSession - writing to client failed", "err", err)
+			// unblock readClient so that the session is closed
+			s.client.Close()
+			return
+		}
+	}
+}
+
+// disconnected handles the backend connection conn failing or being closed. The client connection is kept open
+// and if there are subscriptions the backend is reconnected once the node is up.
+func (s *wsSession) disconnected(conn *websocket.Conn, err error) {
+	conn.Close()
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.backend == conn {
+		s.backend = nil
+	}
+	if s.closed {
+		return
+	}
+	log.Info("wsSession - disconnected from backend, keeping client connection open", "name", s.w.ps.proxyCfg.Name, "err", err)
+	// requests on the old connection will not be answered
+	s.pendingSubs = make(map[string][]byte)
+	s.resubs = make(map[string]resub)
+	if len(s.subs) != 0 {
+		go s.reconnectWhenUp()
+	}
+}
+
+// reconnectWhenUp waits for the node to be up and then reconnects to the backend so that subscriptions are resumed
+func (s *wsSession) reconnectWhenUp() {
+	nc := s.w.ps.nodeCtrl
+	for {
+		time.Sleep(wsReconnectInterval)
+		s.mux.Lock()
+		done := s.closed || s.backend != nil || len(s.subs) == 0
+		s.mux.Unlock()
+		if done {
+			return
+		}
+		if nc.IsNodeBusy() == nil && nc.IsClientUp() {
+			if err := s.connect(); err != nil {
+				log.Error("wsSession - reconnecting to backend failed", "backendURL", s.backendURL, "err", err)
+			}
+		}
+	}
+}
+
+// trackRequest records subscribe requests and rewrites the subscription id of unsubscribe requests to the
+// backend subscription id. Batch requests are not tracked. The caller must hold s.mux.
+func (s *wsSession) trackRequest(msg []byte) []byte {
+	var req rpcMsg
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return msg
+	}
+	if strings.HasSuffix(req.Method, "_subscribe") {
+		s.pendingSubs[string(req.ID)] = msg
+	} else if strings.HasSuffix(req.Method, "_unsubscribe") {
+		var params []string
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+			return msg
+		}
+		clientId := params[0]
+		delete(s.subs, clientId)
+		for backendId, id := range s.subIds {
+			if id != clientId {
+				continue
+			}
+			delete(s.subIds, backendId)
+			if backendId != clientId {
+				b, _ := json.Marshal([]string{backendId})
+				if m, err := setJSONField(msg, "params", b); err == nil {
+					return m
+				}
+			}
+		}
+	}
+	return msg
+}
+
+// trackResponse records the subscription ids of subscribe responses and rewrites the subscription id of
+// notifications to the id the client holds. It returns false if the message is a response to a replayed
+// subscribe request and must not be forwarded to the client.
+func (s *wsSession) trackResponse(msg []byte) ([]byte, bool) {
+	var resp rpcMsg
+	if err := json.Unmarshal(msg, &resp); err != nil {
+		return msg, true
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if len(resp.ID) != 0 {
+		id := string(resp.ID)
+		if strings.HasPrefix(id, `"nh-unsubscribe-`) {
+			return msg, false
+		}
+		if r, ok := s.resubs[id]; ok {
+			clientId := r.clientId
+			delete(s.resubs, id)
+			var backendId string
+			if len(resp.Error) != 0 || json.Unmarshal(resp.Result, &backendId) != nil {
+				log.Error("wsSession - resubscribe failed", "subscription", clientId, "response", string(msg))
+				delete(s.subs, clientId)
+				return msg, false
+			}
+			if _, ok := s.subs[clientId]; !ok {
+				log.Info("wsSession - client unsubscribed while resubscribing, unsubscribing from backend", "subscription", clientId, "backendSubscription", backendId)
+				s.unsubscribeBackend(r.method, backendId)
+				return msg, false
+			}
+			log.Info("wsSession - resubscribed", "subscription", clientId, "backendSubscription", backendId)
+			s.subIds[backendId] = clientId
+			return msg, false
+		}
+		if req, ok := s.pendingSubs[id]; ok {
+			delete(s.pendingSubs, id)
+			var subId string
+			if len(resp.Error) == 0 && json.Unmarshal(resp.Result, &subId) == nil {
+				s.subs[subId] = req
+				s.subIds[subId] = subId
+			}
+		}
+		return msg, true
+	}
+
+	if strings.HasSuffix(resp.Method, "_subscription") {
+		var params map[string]json.RawMessage
+		if err := json.Unmarshal(resp.Params, &params); err != nil {
+			return msg, true
+		}
+		var backendId string
+		if err := json.Unmarshal(params["subscription"], &backendId); err != nil {
+			return msg, true
+		}
+		if clientId, ok := s.subIds[backendId]; ok && clientId != backendId {
+			params["subscription"], _ = json.Marshal(clientId)
+			b, _ := json.Marshal(params)
+			if m, err := setJSONField(msg, "params", b); err == nil {
+				return m, true
+			}
+		}
+	}
+	return msg, true
+}
+
+// unsubscribeBackend cancels the backend subscription backendId made with subscribeMethod. The response is not
+// forwarded to the client. The caller must hold s.mux.
+func (s *wsSession) unsubscribeBackend(subscribeMethod, backendId string) {
+	if s.backend == nil {
+		return
+	}
+	s.resubCount++
+	params, _ := json.Marshal([]string{backendId})
+	b, _ := json.Marshal(struct {
+		JsonRPC string          `json:"jsonrpc"`
+		ID      string          `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}{"2.0", fmt.Sprintf("nh-unsubscribe-%d", s.resubCount), strings.TrimSuffix(subscribeMethod, "_subscribe") + "_unsubscribe", params})
+	if err := s.backend.WriteMessage(websocket.TextMessage, b); err != nil {
+		log.Error("wsSession - unsubscribing from backend failed", "backendSubscription", backendId, "err", err)
+	}
+}
+
+// replyError responds to the client request msg with the JSON-RPC error for err
+func (s *wsSession) replyError(msg []byte, err error) error {
+	b := s.w.ps.newRPCError(err).response(requestId(msg))
+	s.clientMux.Lock()
+	defer s.clientMux.Unlock()
+	return s.client.WriteMessage(websocket.TextMessage, b)
+}
+
+// closeClient closes the client connection with err
+func (s *wsSession) closeClient(err error) {
+	s.clientMux.Lock()
+	defer s.clientMux.Unlock()
+	s.w.closeConnWithError(s.client, err)
+}
+
+// close closes the backend connection once the client connection has been closed
+func (s *wsSession) close() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closed = true
+	if s.backend != nil {
+		s.backend.Close()
+		s.backend = nil
+		log.Info("wsSession - disconnected from backend", "name", s.w.ps.proxyCfg.Name, "dest", s.w.ps.proxyCfg.UpstreamAddr)
+	}
+}
+
+// setJSONField returns the JSON object msg with field set to value
+func setJSONField(msg []byte, field string, value json.RawMessage) ([]byte, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	m[field] = value
+	return json.Marshal(m)
+}
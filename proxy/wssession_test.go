@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWSSession() *wsSession {
+	w := &WebsocketProxy{ps: &ProxyServer{proxyCfg: &config.Proxy{Name: "test"}}}
+	return newWSSession(w, nil, "client", "ws://localhost", nil, nil)
+}
+
+func TestWSSession_TracksSubscriptions(t *testing.T) {
+	s := newTestWSSession()
+	sub := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)
+
+	require.Equal(t, sub, s.trackRequest(sub))
+	msg, forward := s.trackResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xaaa"}`))
+
+	require.True(t, forward)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"0xaaa"}`, string(msg))
+	require.Equal(t, map[string][]byte{"0xaaa": sub}, s.subs)
+	require.Empty(t, s.pendingSubs)
+
+	// failed subscriptions are not tracked
+	s.trackRequest([]byte(`{"jsonrpc":"2.0","id":2,"method":"eth_subscribe","params":["unknown"]}`))
+	s.trackResponse([]byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"no such subscription"}}`))
+	require.Len(t, s.subs, 1)
+}
+
+func TestWSSession_RewritesResubscribedIds(t *testing.T) {
+	s := newTestWSSession()
+	s.trackRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`))
+	s.trackResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xaaa"}`))
+
+	// backend reconnected and subscription replayed
+	s.subIds = make(map[string]string)
+	s.resubs[`"nh-resubscribe-1"`] = resub{clientId: "0xaaa", method: "eth_subscribe"}
+	_, forward := s.trackResponse([]byte(`{"jsonrpc":"2.0","id":"nh-resubscribe-1","result":"0xbbb"}`))
+	require.False(t, forward, "response to replayed subscribe should not be forwarded to the client")
+
+	msg, forward := s.trackResponse([]byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xbbb","result":{"number":"0x1"}}}`))
+	require.True(t, forward)
+	require.JSONEq(t, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xaaa","result":{"number":"0x1"}}}`, string(msg))
+
+	msg = s.trackRequest([]byte(`{"jsonrpc":"2.0","id":3,"method":"eth_unsubscribe","params":["0xaaa"]}`))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":3,"method":"eth_unsubscribe","params":["0xbbb"]}`, string(msg))
+	require.Empty(t, s.subs)
+	require.Empty(t, s.subIds)
+}
+
+func TestWSSession_FailedResubscribeIsDropped(t *testing.T) {
+	s := newTestWSSession()
+	s.subs["0xaaa"] = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)
+	s.resubs[`"nh-resubscribe-1"`] = resub{clientId: "0xaaa", method: "eth_subscribe"}
+
+	_, forward := s.trackResponse([]byte(`{"jsonrpc":"2.0","id":"nh-resubscribe-1","error":{"code":-32000,"message":"failed"}}`))
+
+	require.False(t, forward)
+	require.Empty(t, s.subs)
+}
+
+func TestWSSession_ConnectReplaysSubscriptions(t *testing.T) {
+	// backend answers subscribe requests with a new subscription id and then sends a notification for it
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := DefaultUpgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var r rpcMsg
+			require.NoError(t, json.Unmarshal(msg, &r))
+			require.Equal(t, "eth_subscribe", r.Method)
+			conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":"0xbbb"}`, r.ID)))
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xbbb","result":"0x1"}}`))
+		}
+	}))
+	defer backend.Close()
+
+	sessions := make(chan *wsSession, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := DefaultUpgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		s := newTestWSSession()
+		s.client = conn
+		s.backendURL = "ws" + strings.TrimPrefix(backend.URL, "http")
+		s.dialer = websocket.DefaultDialer
+		sessions <- s
+	}))
+	defer proxy.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(proxy.URL, "http"), nil)
+	require.NoError(t, err)
+	defer client.Close()
+	s := <-sessions
+	defer s.close()
+
+	s.mux.Lock()
+	s.subs["0xaaa"] = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)
+	s.mux.Unlock()
+	require.NoError(t, s.connect())
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, msg, err := client.ReadMessage()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xaaa","result":"0x1"}}`, string(msg))
+}
+
+func TestWSSession_ConnectDoesNotBlockSession(t *testing.T) {
+	// backend accepts connections but never completes the websocket handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	s := newTestWSSession()
+	s.backendURL = "ws://" + ln.Addr().String()
+	s.dialer = &websocket.Dialer{HandshakeTimeout: 2 * time.Second}
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- s.send(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`))
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	s.close()
+	require.True(t, time.Since(start) < time.Second, "close must not wait for the backend handshake")
+	require.Error(t, <-sent)
+}
+
+func TestWSSession_UnsubscribeWhileResubscribing(t *testing.T) {
+	// backend records the messages it receives
+	received := make(chan []byte, 2)
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := DefaultUpgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- msg
+		}
+	}))
+	defer backend.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(backend.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s := newTestWSSession()
+	s.backend = conn
+	s.subs["0xaaa"] = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)
+	s.resubs[`"nh-resubscribe-1"`] = resub{clientId: "0xaaa", method: "eth_subscribe"}
+
+	// client unsubscribes before the replayed subscribe request is answered
+	s.trackRequest([]byte(`{"jsonrpc":"2.0","id":3,"method":"eth_unsubscribe","params":["0xaaa"]}`))
+	_, forward := s.trackResponse([]byte(`{"jsonrpc":"2.0","id":"nh-resubscribe-1","result":"0xbbb"}`))
+
+	require.False(t, forward)
+	require.Empty(t, s.subIds, "cancelled subscription must not be tracked")
+	select {
+	case msg := <-received:
+		require.JSONEq(t, `{"jsonrpc":"2.0","id":"nh-unsubscribe-1","method":"eth_unsubscribe","params":["0xbbb"]}`, string(msg))
+	case <-time.After(5 * time.Second):
+		t.Fatal("backend subscription was not cancelled")
+	}
+	_, forward = s.trackResponse([]byte(`{"jsonrpc":"2.0","id":"nh-unsubscribe-1","result":true}`))
+	require.False(t, forward, "response to the unsubscribe request must not be forwarded to the client")
+}
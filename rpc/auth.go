@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/gorilla/rpc/v2"
+)
+
+const allowAll = "*"
+
+// metricsMethod is the acl method name of the metrics endpoint when it is served by the rpc server
+const metricsMethod = "metrics"
+
+var (
+	errUnauthorized = errors.New("unauthorized")
+	errForbidden    = errors.New("method not allowed for caller")
+)
+
+// caller is the authenticated identity of a caller of the RPC server
+type caller struct {
+	name string // name of the peer, or the client certificate subject common name if the caller is not a peer
+	peer bool   // indicates if the caller is a peer
+}
+
+// callerKey is the request context key of the authenticated caller
+type callerKey struct{}
+
+// callerFromContext returns the authenticated caller stored in ctx by authorizer.handler
+func callerFromContext(ctx context.Context) (caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	return c, ok
+}
+
+// authorizer authenticates the callers of the RPC server by their tls client certificate and authorises the
+// methods they call with the acl
+type authorizer struct {
+	acl               []*config.RPCACLRule
+	peerNameBySubject func(subject string) (string, bool)
+}
+
+func newAuthorizer(acl []*config.RPCACLRule, peerNameBySubject func(subject string) (string, bool)) *authorizer {
+	return &authorizer{
+		acl:               acl,
+		peerNameBySubject: peerNameBySubject,
+	}
+}
+
+// authenticate returns the identity of the caller making req.
+// Client certificates are verified by the tls server using the client ca.
+func (a *authorizer) authenticate(req *http.Request) (caller, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return caller{}, errUnauthorized
+	}
+	subject := req.TLS.PeerCertificates[0].Subject.CommonName
+	if subject == "" {
+		return caller{}, errUnauthorized
+	}
+	if name, ok := a.peerNameBySubject(subject); ok {
+		return caller{name: name, peer: true}, nil
+	}
+	return caller{name: subject}, nil
+}
+
+// isAllowed returns true if a rule of the acl allows c to call method
+func (a *authorizer) isAllowed(c caller, method string) bool {
+	for _, r := range a.acl {
+		callers := r.Subjects
+		if c.peer {
+			callers = r.Peers
+		}
+		if contains(callers, c.name) && contains(r.Methods, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// handler rejects requests from callers which are not authenticated or not allowed to call the requested method
+func (a *authorizer) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, err := a.authenticate(req)
+		if err != nil {
+			log.Warn("authorizer - unauthenticated rpc call rejected", "remoteAddr", req.RemoteAddr)
+			writeError(w, http.StatusUnauthorized, nil, err)
+			return
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, nil, err)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		var rpcReq struct {
+			Method string           `json:"method"`
+			Id     *json.RawMessage `json:"id"`
+		}
+		// malformed requests are rejected by the rpc server
+		_ = json.Unmarshal(body, &rpcReq)
+
+		if !a.isAllowed(c, rpcReq.Method) {
+			log.Warn("authorizer - rpc call not allowed by acl", "caller", c.name, "peer", c.peer, "method", rpcReq.Method)
+			writeError(w, http.StatusForbidden, rpcReq.Id, errForbidden)
+			return
+		}
+		log.Debug("authorizer - rpc call allowed", "caller", c.name, "peer", c.peer, "method", rpcReq.Method)
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), callerKey{}, c)))
+	})
+}
+
+// endpointHandler rejects requests from callers which are not authenticated or not allowed by the acl to call
+// method. It protects the endpoints served by the rpc server which are not json rpc methods, e.g. metrics.
+func (a *authorizer) endpointHandler(method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, err := a.authenticate(req)
+		if err != nil {
+			log.Warn("authorizer - unauthenticated request rejected", "remoteAddr", req.RemoteAddr, "path", req.URL.Path)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !a.isAllowed(c, method) {
+			log.Warn("authorizer - request not allowed by acl", "caller", c.name, "peer", c.peer, "path", req.URL.Path)
+			http.Error(w, errForbidden.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// validateFrom rejects calls whose from param is not the name of the authenticated caller. It is registered with
+// the rpc server so that it is called with the decoded params of the method.
+func (a *authorizer) validateFrom(r *rpc.RequestInfo, args interface{}) error {
+	c, ok := callerFromContext(r.Request.Context())
+	if !ok {
+		return errUnauthorized
+	}
+	if from := fromParam(args); from != c.name {
+		log.Warn("authorizer - rpc call with from not matching caller rejected", "caller", c.name, "peer", c.peer, "from", from, "method", r.Method)
+		return fmt.Errorf("from %q does not match caller %q", from, c.name)
+	}
+	return nil
+}
+
+// fromParam returns the from param of the decoded params of a node rpc method. The params are either the from
+// string or a struct with a From field.
+func fromParam(args interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(args))
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Struct:
+		if f := v.FieldByName("From"); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// writeError writes err in the format of the json rpc server
+func writeError(w http.ResponseWriter, status int, id *json.RawMessage, err error) {
+	b, _ := json.Marshal(struct {
+		Result interface{}      `json:"result"`
+		Error  string           `json:"error"`
+		Id     *json.RawMessage `json:"id"`
+	}{Error: err.Error(), Id: id})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == allowAll || v == s {
+			return true
+		}
+	}
+	return false
+}
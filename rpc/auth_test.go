@@ -0,0 +1,233 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ConsenSys/quorum-hibernate/config"
+	"github.com/gorilla/rpc/v2"
+	"github.com/gorilla/rpc/v2/json"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthorizer() *authorizer {
+	peers := map[string]string{"peer1-cn": "peer1", "peer2": "peer2"}
+	return newAuthorizer(
+		[]*config.RPCACLRule{
+			{Peers: []string{"*"}, Methods: []string{"node.NodeStatus"}},
+			{Peers: []string{"peer1"}, Methods: []string{"node.PrepareForPrivateTx"}},
+			{Subjects: []string{"admin"}, Methods: []string{"*"}},
+		},
+		func(subject string) (string, bool) {
+			name, ok := peers[subject]
+			return name, ok
+		},
+	)
+}
+
+func newRequest(subject string, method string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"method":"`+method+`","params":["from"],"id":1}`))
+	if subject != "" {
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: subject}}}}
+	}
+	return req
+}
+
+func TestAuthorizer_Handler(t *testing.T) {
+	tests := []struct {
+		name, subject, method string
+		wantStatus            int
+		wantBody              string
+	}{
+		{
+			name:       "no client certificate",
+			method:     "node.NodeStatus",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"result":null,"error":"unauthorized","id":null}`,
+		},
+		{
+			name:       "peer mapped by subject",
+			subject:    "peer1-cn",
+			method:     "node.PrepareForPrivateTx",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "peer not allowed",
+			subject:    "peer2",
+			method:     "node.PrepareForPrivateTx",
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"result":null,"error":"method not allowed for caller","id":1}`,
+		},
+		{
+			name:       "all peers allowed",
+			subject:    "peer2",
+			method:     "node.NodeStatus",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "peer name used as subject",
+			subject:    "peer1",
+			method:     "node.NodeStatus",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "admin allowed all methods",
+			subject:    "admin",
+			method:     "node.Hibernate",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown subject",
+			subject:    "other",
+			method:     "node.NodeStatus",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				b, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				gotBody = string(b)
+			})
+			res := httptest.NewRecorder()
+
+			newTestAuthorizer().handler(next).ServeHTTP(res, newRequest(tt.subject, tt.method))
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			if tt.wantStatus == http.StatusOK {
+				require.Contains(t, gotBody, tt.method, "request body must be passed on")
+			} else {
+				require.Empty(t, gotBody)
+			}
+			if tt.wantBody != "" {
+				require.Equal(t, tt.wantBody, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthorizer_EndpointHandler(t *testing.T) {
+	tests := []struct {
+		name, subject string
+		wantStatus    int
+	}{
+		{
+			name:       "no client certificate",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "not allowed",
+			subject:    "peer1-cn",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "allowed",
+			subject:    "admin",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				called = true
+			})
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.subject != "" {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: tt.subject}}}}
+			}
+			res := httptest.NewRecorder()
+
+			newTestAuthorizer().endpointHandler(metricsMethod, next).ServeHTTP(res, req)
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, tt.wantStatus == http.StatusOK, called)
+		})
+	}
+}
+
+type FromArgs struct {
+	From string `json:"from"`
+}
+
+type testService struct{}
+
+func (testService) NodeStatus(_ *http.Request, from *string, reply *string) error {
+	*reply = "ok"
+	return nil
+}
+
+func (testService) RequestHibernationLease(_ *http.Request, args *FromArgs, reply *string) error {
+	*reply = "ok"
+	return nil
+}
+
+func TestAuthorizer_ValidateFrom(t *testing.T) {
+	a := newTestAuthorizer()
+	srv := rpc.NewServer()
+	srv.RegisterCodec(json.NewCodec(), "application/json")
+	srv.RegisterValidateRequestFunc(a.validateFrom)
+	require.NoError(t, srv.RegisterService(testService{}, "node"))
+	a.acl = append(a.acl, &config.RPCACLRule{Peers: []string{"*"}, Methods: []string{"node.RequestHibernationLease"}})
+
+	tests := []struct {
+		name, subject, body string
+		wantResult          bool
+	}{
+		{
+			name:       "peer calls as itself",
+			subject:    "peer1-cn",
+			body:       `{"method":"node.NodeStatus","params":["peer1"],"id":1}`,
+			wantResult: true,
+		},
+		{
+			name:    "peer calls as another peer",
+			subject: "peer1-cn",
+			body:    `{"method":"node.NodeStatus","params":["peer2"],"id":1}`,
+		},
+		{
+			name:       "peer requests lease as itself",
+			subject:    "peer2",
+			body:       `{"method":"node.RequestHibernationLease","params":[{"from":"peer2"}],"id":1}`,
+			wantResult: true,
+		},
+		{
+			name:    "peer requests lease as another node hibernator",
+			subject: "peer2",
+			body:    `{"method":"node.RequestHibernationLease","params":[{"from":"self"}],"id":1}`,
+		},
+		{
+			name:       "admin calls as itself",
+			subject:    "admin",
+			body:       `{"method":"node.NodeStatus","params":["admin"],"id":1}`,
+			wantResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: tt.subject}}}}
+			res := httptest.NewRecorder()
+
+			a.handler(srv).ServeHTTP(res, req)
+
+			if tt.wantResult {
+				require.Equal(t, `{"result":"ok","error":null,"id":1}`, strings.TrimSpace(res.Body.String()))
+			} else {
+				require.Contains(t, res.Body.String(), "does not match caller")
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/ConsenSys/quorum-hibernate/config"
 	"github.com/ConsenSys/quorum-hibernate/log"
+	"github.com/ConsenSys/quorum-hibernate/metrics"
 	"github.com/ConsenSys/quorum-hibernate/node"
 	"github.com/gorilla/rpc/v2"
 	"github.com/gorilla/rpc/v2/json"
@@ -50,7 +51,27 @@ func (r *RPCService) Start() error {
 		return err
 	}
 
-	serverWithCors := cors.New(cors.Options{AllowedOrigins: r.cors}).Handler(jsonrpcServer)
+	var handler http.Handler = jsonrpcServer
+	var auth *authorizer
+	if serverCfg := r.qn.GetNodeConfig().BasicConfig.Server; serverCfg.IsACLEnabled() {
+		auth = newAuthorizer(serverCfg.ACL, r.qn.PeerNameBySubject)
+		jsonrpcServer.RegisterValidateRequestFunc(auth.validateFrom)
+		handler = auth.handler(jsonrpcServer)
+		log.Info("Authenticating JSON-RPC callers by client certificate", "rules", len(serverCfg.ACL))
+	}
+	if metricsCfg := r.qn.GetNodeConfig().BasicConfig.Metrics; metricsCfg != nil && !metricsCfg.IsSeparateServer() {
+		metricsHandler := metrics.Handler()
+		if auth != nil {
+			metricsHandler = auth.endpointHandler(metricsMethod, metricsHandler)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(metrics.Path, metricsHandler)
+		mux.Handle("/", handler)
+		handler = mux
+		log.Info("Serving metrics on the JSON-RPC server", "path", metrics.Path)
+	}
+
+	serverWithCors := cors.New(cors.Options{AllowedOrigins: r.cors}).Handler(handler)
 	r.httpServer = &http.Server{
 		Addr:    r.httpAddress,
 		Handler: serverWithCors,